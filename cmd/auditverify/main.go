@@ -0,0 +1,40 @@
+// Command auditverify recomputes the audit log hash chain and exits non-zero
+// when an entry has been altered, removed or inserted out of band.
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+
+	"hospital-management/internal/audit"
+	"hospital-management/internal/config"
+	"hospital-management/internal/database"
+)
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
+	}
+
+	cfg := config.Load()
+
+	db, err := database.Initialize(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+
+	service := audit.NewService(audit.NewRepository(db))
+	result, err := service.Verify()
+	if err != nil {
+		log.Fatal("Failed to verify audit log:", err)
+	}
+
+	if !result.Valid {
+		log.Printf("Audit log INVALID after %d entries: entry %d: %s", result.Checked, result.BrokenAt, result.Reason)
+		os.Exit(1)
+	}
+
+	log.Printf("Audit log valid: %d entries checked", result.Checked)
+}
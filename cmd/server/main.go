@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -11,11 +14,29 @@ import (
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 
+	"hospital-management/internal/admin"
+	"hospital-management/internal/admission"
+	"hospital-management/internal/allergy"
+	"hospital-management/internal/appointment"
+	"hospital-management/internal/audit"
 	"hospital-management/internal/auth"
+	"hospital-management/internal/cds"
 	"hospital-management/internal/config"
 	"hospital-management/internal/database"
+	"hospital-management/internal/discharge"
+	"hospital-management/internal/encounter"
+	"hospital-management/internal/icd10"
+	"hospital-management/internal/lab"
+	"hospital-management/internal/models"
 	"hospital-management/internal/patient"
+	"hospital-management/internal/prescription"
+	"hospital-management/internal/problem"
+	"hospital-management/internal/queue"
+	"hospital-management/internal/retention"
+	"hospital-management/internal/schedule"
 	"hospital-management/internal/user"
+	"hospital-management/internal/vitals"
+	"hospital-management/pkg/events"
 )
 
 // @title Hospital Management System API
@@ -46,25 +67,94 @@ func main() {
 		log.Fatal("Failed to run migrations:", err)
 	}
 
+	// Create the bootstrap administrator
+	if err := database.SeedAdmin(db, cfg.AdminUsername, cfg.AdminEmail, cfg.AdminPassword); err != nil {
+		log.Fatal("Failed to seed administrator:", err)
+	}
+
+	// Load the clinical decision support dataset
+	cdsEngine, err := cds.Load(cfg.CDSDatasetPath)
+	if err != nil {
+		log.Fatal("Failed to load clinical decision support dataset:", err)
+	}
+
+	// Load the ICD-10 code table
+	icd10Catalog, err := icd10.Load(cfg.ICD10CodesPath)
+	if err != nil {
+		log.Fatal("Failed to load ICD-10 code table:", err)
+	}
+
 	// Initialize repositories
 	userRepo := user.NewRepository(db)
 	patientRepo := patient.NewRepository(db)
+	authRepo := auth.NewRepository(db)
+	auditRepo := audit.NewRepository(db)
+	appointmentRepo := appointment.NewRepository(db)
+	scheduleRepo := schedule.NewRepository(db)
+	queueRepo := queue.NewRepository(db)
+	encounterRepo := encounter.NewRepository(db)
+	allergyRepo := allergy.NewRepository(db)
+	prescriptionRepo := prescription.NewRepository(db)
+	labRepo := lab.NewRepository(db)
+	vitalsRepo := vitals.NewRepository(db)
+	admissionRepo := admission.NewRepository(db)
+	dischargeRepo := discharge.NewRepository(db)
+	problemRepo := problem.NewRepository(db)
+
+	// In-process event broker feeding live streams
+	broker := events.NewBroker()
 
 	// Initialize services
 	userService := user.NewService(userRepo)
-	patientService := patient.NewService(patientRepo)
-	authService := auth.NewService(userService, cfg.JWTSecret)
+	patientService := patient.NewService(patientRepo, cfg)
+	auditService := audit.NewService(auditRepo)
+	authService := auth.NewService(authRepo, userService, cfg)
+	scheduleService := schedule.NewService(scheduleRepo, userService, cfg)
+	appointmentService := appointment.NewService(appointmentRepo, patientService, userService, scheduleService, cfg)
+	queueService := queue.NewService(queueRepo, patientService, userService, appointmentService, broker, cfg)
+	encounterService := encounter.NewService(encounterRepo, patientService, userService, queueService)
+	allergyService := allergy.NewService(allergyRepo, patientService)
+	prescriptionService := prescription.NewService(prescriptionRepo, patientService, userService, encounterService, allergyService, cdsEngine, cfg)
+	labService := lab.NewService(labRepo, patientService, userService, encounterService)
+	admissionService := admission.NewService(admissionRepo, patientService, userService, broker)
+	problemService := problem.NewService(problemRepo, icd10Catalog, patientService, encounterService, cfg)
+	dischargeService := discharge.NewService(dischargeRepo, admissionService, encounterService, problemService, prescriptionService, allergyService, labService, userService, cfg)
+	vitalsService := vitals.NewService(vitalsRepo, patientService, userService, queueService, admissionService, broker)
 
 	// Initialize handlers
 	authHandler := auth.NewHandler(authService)
 	userHandler := user.NewHandler(userService)
-	patientHandler := patient.NewHandler(patientService)
+	patientHandler := patient.NewHandler(patientService, auditService)
+	auditHandler := audit.NewHandler(auditService)
+	adminHandler := admin.NewHandler(userService, authService, auditService)
+	appointmentHandler := appointment.NewHandler(appointmentService, auditService)
+	scheduleHandler := schedule.NewHandler(scheduleService, auditService)
+	queueHandler := queue.NewHandler(queueService, auditService)
+	encounterHandler := encounter.NewHandler(encounterService, auditService)
+	allergyHandler := allergy.NewHandler(allergyService, auditService)
+	prescriptionHandler := prescription.NewHandler(prescriptionService, auditService)
+	labHandler := lab.NewHandler(labService, auditService)
+	vitalsHandler := vitals.NewHandler(vitalsService, auditService)
+	admissionHandler := admission.NewHandler(admissionService, auditService)
+	dischargeHandler := discharge.NewHandler(dischargeService, auditService)
+	problemHandler := problem.NewHandler(problemService, auditService)
+
+	// Purge patient records past their retention period
+	retentionJob := retention.NewJob(patientService, auditService, cfg.RetentionPeriod, cfg.RetentionInterval)
+	go retentionJob.Start(context.Background())
 
-	// Setup router
-	router := gin.Default()
+	// Setup router. The access log leaves out query strings, which carry
+	// stream tokens and search terms.
+	router := gin.New()
+	router.Use(gin.LoggerWithFormatter(accessLogLine), gin.Recovery())
 
-	// CORS middleware
-	router.Use(cors.Default())
+	// CORS middleware; conditional request headers and ETags must be
+	// allowed through for optimistic concurrency control
+	corsConfig := cors.DefaultConfig()
+	corsConfig.AllowAllOrigins = true
+	corsConfig.AddAllowHeaders("Authorization", "If-Match", "If-None-Match")
+	corsConfig.AddExposeHeaders("ETag", "X-Patient-Merged-From")
+	router.Use(cors.New(corsConfig))
 
 	// // Static files
 	// router.Static("/static", "./web/static")
@@ -89,33 +179,246 @@ func main() {
 		// Auth routes
 		v1.POST("/login", authHandler.Login)
 		v1.POST("/register", authHandler.Register)
+		v1.POST("/token/refresh", authHandler.Refresh)
+
+		// Account routes, reachable while a password change is pending
+		account := v1.Group("/")
+		account.Use(auth.RequireAuth(authService))
+		{
+			account.POST("/logout", authHandler.Logout)
+			account.PUT("/profile/password", authHandler.ChangePassword)
+		}
+
+		// Live event streams. Browsers' EventSource cannot send an
+		// Authorization header, so these also accept a ?stream_token= from
+		// POST /token/stream.
+		streams := v1.Group("/")
+		streams.Use(auth.RequireStreamAuth(authService), auth.RequirePasswordChanged())
+		{
+			streams.GET("/queue/stream", auth.RequirePermission(models.PermissionQueueRead), queueHandler.StreamQueue)
+			streams.GET("/beds/board/stream", auth.RequirePermission(models.PermissionAdmissionRead), admissionHandler.StreamBoard)
+			streams.GET("/doctor/queue/stream", auth.RequireRole(models.RoleDoctor), auth.RequirePermission(models.PermissionQueueRead), queueHandler.StreamDoctorQueue)
+			streams.GET("/doctor/vitals-alerts/stream", auth.RequireRole(models.RoleDoctor), auth.RequirePermission(models.PermissionVitalsRead), vitalsHandler.StreamDoctorAlerts)
+		}
 
 		// Protected routes
 		protected := v1.Group("/")
-		protected.Use(auth.RequireAuth(cfg.JWTSecret))
+		protected.Use(auth.RequireAuth(authService), auth.RequirePasswordChanged())
 		{
 			// User routes
 			protected.GET("/profile", userHandler.GetProfile)
 			protected.PUT("/profile", userHandler.UpdateProfile)
+			protected.POST("/token/stream", authHandler.StreamToken)
+
+			// Patient routes (front desk)
+			patients := protected.Group("/patients")
+			{
+				patients.POST("/", auth.RequirePermission(models.PermissionPatientWrite), patientHandler.CreatePatient)
+				patients.GET("/", auth.RequirePermission(models.PermissionPatientRead), patientHandler.GetPatients)
+				patients.GET("/search", auth.RequirePermission(models.PermissionPatientRead), patientHandler.SearchPatients)
+				patients.POST("/check-duplicates", auth.RequirePermission(models.PermissionPatientWrite), patientHandler.CheckDuplicates)
+				patients.GET("/:id", auth.RequirePermission(models.PermissionPatientRead), patientHandler.GetPatient)
+				patients.PUT("/:id", auth.RequirePermission(models.PermissionPatientWrite), patientHandler.UpdatePatient)
+				patients.PATCH("/:id", auth.RequireAnyPermission(models.PermissionPatientWrite, models.PermissionMedicalWrite), patientHandler.PatchPatient)
+				patients.DELETE("/:id", auth.RequirePermission(models.PermissionPatientDelete), patientHandler.DeletePatient)
+				patients.POST("/:id/merge", auth.RequirePermission(models.PermissionPatientMerge), patientHandler.MergePatient)
+				patients.GET("/:id/merges", auth.RequirePermission(models.PermissionPatientRead), patientHandler.GetMergeHistory)
+				patients.GET("/:id/revisions", auth.RequirePermission(models.PermissionPatientRead), patientHandler.GetRevisions)
+				patients.GET("/:id/revisions/diff", auth.RequirePermission(models.PermissionPatientRead), patientHandler.DiffRevisions)
+				patients.GET("/:id/revisions/:version", auth.RequirePermission(models.PermissionPatientRead), patientHandler.GetRevision)
+				patients.GET("/:id/as-of", auth.RequirePermission(models.PermissionPatientRead), patientHandler.GetPatientAsOf)
+				patients.GET("/:id/encounters", auth.RequirePermission(models.PermissionEncounterRead), encounterHandler.GetPatientTimeline)
+				patients.GET("/:id/allergies", auth.RequirePermission(models.PermissionPatientRead), allergyHandler.GetAllergies)
+				patients.POST("/:id/allergies", auth.RequireAnyPermission(models.PermissionPatientWrite, models.PermissionMedicalWrite), allergyHandler.CreateAllergy)
+				patients.PUT("/:id/allergies/:allergyId", auth.RequireAnyPermission(models.PermissionPatientWrite, models.PermissionMedicalWrite), allergyHandler.UpdateAllergy)
+				patients.DELETE("/:id/allergies/:allergyId", auth.RequireAnyPermission(models.PermissionPatientWrite, models.PermissionMedicalWrite), allergyHandler.DeleteAllergy)
+				patients.GET("/:id/problems", auth.RequirePermission(models.PermissionProblemRead), problemHandler.GetProblems)
+				patients.POST("/:id/problems", auth.RequirePermission(models.PermissionProblemWrite), problemHandler.CreateProblem)
+				patients.GET("/:id/problems/:problemId", auth.RequirePermission(models.PermissionProblemRead), problemHandler.GetProblem)
+				patients.PUT("/:id/problems/:problemId", auth.RequirePermission(models.PermissionProblemWrite), problemHandler.UpdateProblem)
+				patients.GET("/:id/prescriptions", auth.RequirePermission(models.PermissionPrescriptionRead), prescriptionHandler.GetPatientPrescriptions)
+				patients.GET("/:id/medications", auth.RequirePermission(models.PermissionPrescriptionRead), prescriptionHandler.GetActiveMedications)
+				patients.GET("/:id/lab-results", auth.RequirePermission(models.PermissionLabRead), labHandler.GetPatientResults)
+				patients.GET("/:id/lab-results/trend", auth.RequirePermission(models.PermissionLabRead), labHandler.GetPatientTrend)
+				patients.GET("/:id/admissions", auth.RequirePermission(models.PermissionAdmissionRead), admissionHandler.GetPatientAdmissions)
+				patients.GET("/:id/vitals", auth.RequirePermission(models.PermissionVitalsRead), vitalsHandler.GetPatientVitals)
+				patients.GET("/:id/vitals/series", auth.RequirePermission(models.PermissionVitalsRead), vitalsHandler.GetPatientSeries)
+				patients.POST("/merges/:mergeId/unmerge", auth.RequirePermission(models.PermissionPatientMerge), patientHandler.UnmergePatient)
+			}
+
+			// Appointment routes (front desk)
+			protected.GET("/appointment-types", auth.RequirePermission(models.PermissionAppointmentRead), appointmentHandler.ListTypes)
+			protected.POST("/appointment-types", auth.RequirePermission(models.PermissionScheduleManage), appointmentHandler.CreateType)
+			protected.PUT("/appointment-types/:id", auth.RequirePermission(models.PermissionScheduleManage), appointmentHandler.UpdateType)
+
+			appointments := protected.Group("/appointments")
+			{
+				appointments.POST("/", auth.RequirePermission(models.PermissionAppointmentWrite), appointmentHandler.BookAppointment)
+				appointments.GET("/", auth.RequirePermission(models.PermissionAppointmentRead), appointmentHandler.GetAppointments)
+				appointments.GET("/:id", auth.RequirePermission(models.PermissionAppointmentRead), appointmentHandler.GetAppointment)
+				appointments.PUT("/:id/reschedule", auth.RequirePermission(models.PermissionAppointmentWrite), appointmentHandler.RescheduleAppointment)
+				appointments.POST("/:id/cancel", auth.RequirePermission(models.PermissionAppointmentWrite), appointmentHandler.CancelAppointment)
+			}
+
+			// Doctor availability
+			doctors := protected.Group("/doctors")
+			{
+				doctors.GET("/:id/schedule", auth.RequirePermission(models.PermissionAppointmentRead), scheduleHandler.GetSchedule)
+				doctors.PUT("/:id/schedule/weekly", auth.RequirePermission(models.PermissionScheduleManage), scheduleHandler.SetWeeklyHours)
+				doctors.POST("/:id/leave", auth.RequirePermission(models.PermissionScheduleManage), scheduleHandler.AddLeave)
+				doctors.DELETE("/:id/leave/:leaveId", auth.RequirePermission(models.PermissionScheduleManage), scheduleHandler.DeleteLeave)
+				doctors.POST("/:id/exceptions", auth.RequirePermission(models.PermissionScheduleManage), scheduleHandler.AddException)
+				doctors.DELETE("/:id/exceptions/:exceptionId", auth.RequirePermission(models.PermissionScheduleManage), scheduleHandler.DeleteException)
+			}
+			protected.GET("/availability/slots", auth.RequirePermission(models.PermissionAppointmentRead), scheduleHandler.FindSlots)
+
+			// Waiting queue (front desk)
+			visits := protected.Group("/queue")
+			{
+				visits.POST("/check-in", auth.RequirePermission(models.PermissionQueueWrite), queueHandler.CheckIn)
+				visits.GET("/", auth.RequirePermission(models.PermissionQueueRead), queueHandler.GetQueue)
+				visits.PUT("/:id/status", auth.RequirePermission(models.PermissionQueueWrite), queueHandler.UpdateStatus)
+				visits.PUT("/:id/priority", auth.RequirePermission(models.PermissionQueueWrite), queueHandler.UpdatePriority)
+			}
 
-			// Patient routes (Receptionist only)
-			receptionist := protected.Group("/patients")
-			receptionist.Use(auth.RequireRole("receptionist"))
+			// Clinical encounters and SOAP notes
+			encounters := protected.Group("/encounters")
 			{
-				receptionist.POST("/", patientHandler.CreatePatient)
-				receptionist.GET("/", patientHandler.GetPatients)
-				receptionist.GET("/:id", patientHandler.GetPatient)
-				receptionist.PUT("/:id", patientHandler.UpdatePatient)
-				receptionist.DELETE("/:id", patientHandler.DeletePatient)
+				encounters.POST("/", auth.RequirePermission(models.PermissionEncounterWrite), encounterHandler.CreateEncounter)
+				encounters.GET("/:id", auth.RequirePermission(models.PermissionEncounterRead), encounterHandler.GetEncounter)
+				encounters.PUT("/:id", auth.RequirePermission(models.PermissionEncounterWrite), encounterHandler.UpdateEncounter)
+				encounters.DELETE("/:id", auth.RequirePermission(models.PermissionEncounterWrite), encounterHandler.DeleteEncounter)
+				encounters.POST("/:id/sign", auth.RequirePermission(models.PermissionEncounterWrite), encounterHandler.SignEncounter)
+				encounters.POST("/:id/addenda", auth.RequirePermission(models.PermissionEncounterWrite), encounterHandler.AddAddendum)
+				encounters.POST("/:id/diagnoses", auth.RequirePermission(models.PermissionEncounterWrite), problemHandler.AddDiagnosis)
+				encounters.DELETE("/:id/diagnoses/:diagnosisId", auth.RequirePermission(models.PermissionEncounterWrite), problemHandler.RemoveDiagnosis)
+			}
+
+			// ICD-10 diagnosis codes
+			codes := protected.Group("/icd10/codes")
+			{
+				codes.GET("/", auth.RequirePermission(models.PermissionProblemRead), problemHandler.SearchCodes)
+				codes.GET("/:code", auth.RequirePermission(models.PermissionProblemRead), problemHandler.GetCode)
+			}
+
+			// Prescriptions
+			prescriptions := protected.Group("/prescriptions")
+			{
+				prescriptions.POST("/", auth.RequirePermission(models.PermissionPrescriptionWrite), prescriptionHandler.Prescribe)
+				prescriptions.POST("/check", auth.RequirePermission(models.PermissionPrescriptionWrite), prescriptionHandler.CheckPrescription)
+				prescriptions.GET("/:id", auth.RequirePermission(models.PermissionPrescriptionRead), prescriptionHandler.GetPrescription)
+				prescriptions.GET("/:id/print", auth.RequirePermission(models.PermissionPrescriptionRead), prescriptionHandler.PrintPrescription)
+				prescriptions.POST("/:id/discontinue", auth.RequirePermission(models.PermissionPrescriptionWrite), prescriptionHandler.DiscontinuePrescription)
+				prescriptions.POST("/:id/refills", auth.RequirePermission(models.PermissionPrescriptionWrite), prescriptionHandler.RefillPrescription)
+			}
+
+			// Lab catalog, orders and results
+			labRoutes := protected.Group("/lab")
+			{
+				labRoutes.GET("/tests", auth.RequirePermission(models.PermissionLabRead), labHandler.ListTests)
+				labRoutes.POST("/tests", auth.RequirePermission(models.PermissionLabCatalog), labHandler.CreateTest)
+				labRoutes.PUT("/tests/:id", auth.RequirePermission(models.PermissionLabCatalog), labHandler.UpdateTest)
+				labRoutes.POST("/orders", auth.RequirePermission(models.PermissionLabOrder), labHandler.CreateOrder)
+				labRoutes.GET("/orders", auth.RequirePermission(models.PermissionLabRead), labHandler.GetOrders)
+				labRoutes.GET("/orders/:id", auth.RequirePermission(models.PermissionLabRead), labHandler.GetOrder)
+				labRoutes.POST("/orders/:id/cancel", auth.RequirePermission(models.PermissionLabOrder), labHandler.CancelOrder)
+				labRoutes.POST("/orders/:id/acknowledge", auth.RequirePermission(models.PermissionLabOrder), labHandler.AcknowledgeOrder)
+				labRoutes.PUT("/orders/:id/results/:resultId", auth.RequirePermission(models.PermissionLabResult), labHandler.EnterResult)
+			}
+
+			// Vital signs and early-warning scores
+			vitalSigns := protected.Group("/vitals")
+			{
+				vitalSigns.POST("/", auth.RequirePermission(models.PermissionVitalsWrite), vitalsHandler.RecordVitals)
+				vitalSigns.GET("/:id", auth.RequirePermission(models.PermissionVitalsRead), vitalsHandler.GetVitals)
+				vitalSigns.POST("/:id/acknowledge", auth.RequireRole(models.RoleDoctor), auth.RequirePermission(models.PermissionVitalsRead), vitalsHandler.AcknowledgeVitals)
+			}
+
+			// Wards, beds and inpatient admissions
+			wards := protected.Group("/wards")
+			{
+				wards.GET("/", auth.RequirePermission(models.PermissionAdmissionRead), admissionHandler.ListWards)
+				wards.POST("/", auth.RequirePermission(models.PermissionWardManage), admissionHandler.CreateWard)
+				wards.GET("/:id", auth.RequirePermission(models.PermissionAdmissionRead), admissionHandler.GetWard)
+				wards.PUT("/:id", auth.RequirePermission(models.PermissionWardManage), admissionHandler.UpdateWard)
+				wards.POST("/:id/rooms", auth.RequirePermission(models.PermissionWardManage), admissionHandler.AddRoom)
+			}
+			protected.POST("/rooms/:id/beds", auth.RequirePermission(models.PermissionWardManage), admissionHandler.AddBed)
+
+			beds := protected.Group("/beds")
+			{
+				beds.GET("/board", auth.RequirePermission(models.PermissionAdmissionRead), admissionHandler.GetBoard)
+				beds.PUT("/:id/status", auth.RequirePermission(models.PermissionAdmissionWrite), admissionHandler.UpdateBedStatus)
+			}
+
+			admissions := protected.Group("/admissions")
+			{
+				admissions.POST("/", auth.RequirePermission(models.PermissionAdmissionWrite), admissionHandler.Admit)
+				admissions.GET("/", auth.RequirePermission(models.PermissionAdmissionRead), admissionHandler.GetAdmissions)
+				admissions.GET("/:id", auth.RequirePermission(models.PermissionAdmissionRead), admissionHandler.GetAdmission)
+				admissions.POST("/:id/transfer", auth.RequirePermission(models.PermissionAdmissionWrite), admissionHandler.Transfer)
+				admissions.POST("/:id/discharge", auth.RequirePermission(models.PermissionAdmissionWrite), admissionHandler.Discharge)
+				admissions.POST("/:id/discharge-summary", auth.RequirePermission(models.PermissionEncounterWrite), dischargeHandler.DraftSummary)
+				admissions.GET("/:id/discharge-summary", auth.RequirePermission(models.PermissionEncounterRead), dischargeHandler.GetAdmissionSummary)
+			}
+
+			// Discharge summaries
+			summaries := protected.Group("/discharge-summaries")
+			{
+				summaries.GET("/:id", auth.RequirePermission(models.PermissionEncounterRead), dischargeHandler.GetSummary)
+				summaries.PUT("/:id", auth.RequirePermission(models.PermissionEncounterWrite), dischargeHandler.UpdateSummary)
+				summaries.POST("/:id/sign", auth.RequirePermission(models.PermissionEncounterWrite), dischargeHandler.SignSummary)
+				summaries.POST("/:id/reassign", auth.RequireAnyPermission(models.PermissionEncounterWrite, models.PermissionDischargeManage), dischargeHandler.ReassignSummary)
+				summaries.DELETE("/:id", auth.RequireAnyPermission(models.PermissionEncounterWrite, models.PermissionDischargeManage), dischargeHandler.DiscardSummary)
+				summaries.GET("/:id/print", auth.RequirePermission(models.PermissionEncounterRead), dischargeHandler.PrintSummary)
+				summaries.GET("/:id/pdf", auth.RequirePermission(models.PermissionEncounterRead), dischargeHandler.DownloadSummary)
 			}
 
 			// Doctor routes
 			doctor := protected.Group("/doctor")
-			doctor.Use(auth.RequireRole("doctor"))
 			{
-				doctor.GET("/patients", patientHandler.GetPatients)
-				doctor.GET("/patients/:id", patientHandler.GetPatient)
-				doctor.PUT("/patients/:id/medical-info", patientHandler.UpdateMedicalInfo)
+				doctor.GET("/appointments", auth.RequireRole(models.RoleDoctor), auth.RequirePermission(models.PermissionAppointmentRead), appointmentHandler.GetDoctorAgenda)
+				doctor.GET("/schedule", auth.RequireRole(models.RoleDoctor), scheduleHandler.GetSchedule)
+				doctor.PUT("/schedule/weekly", auth.RequireRole(models.RoleDoctor), scheduleHandler.SetWeeklyHours)
+				doctor.POST("/leave", auth.RequireRole(models.RoleDoctor), scheduleHandler.AddLeave)
+				doctor.DELETE("/leave/:leaveId", auth.RequireRole(models.RoleDoctor), scheduleHandler.DeleteLeave)
+				doctor.POST("/exceptions", auth.RequireRole(models.RoleDoctor), scheduleHandler.AddException)
+				doctor.DELETE("/exceptions/:exceptionId", auth.RequireRole(models.RoleDoctor), scheduleHandler.DeleteException)
+				doctor.GET("/queue", auth.RequireRole(models.RoleDoctor), auth.RequirePermission(models.PermissionQueueRead), queueHandler.GetDoctorQueue)
+				doctor.GET("/vitals-alerts", auth.RequireRole(models.RoleDoctor), auth.RequirePermission(models.PermissionVitalsRead), vitalsHandler.GetDoctorAlerts)
+				doctor.GET("/lab-results", auth.RequireRole(models.RoleDoctor), auth.RequirePermission(models.PermissionLabRead), labHandler.GetDoctorResults)
+				doctor.GET("/patients", auth.RequirePermission(models.PermissionPatientRead), patientHandler.GetPatients)
+				doctor.GET("/patients/:id", auth.RequirePermission(models.PermissionPatientRead), patientHandler.GetPatient)
+				doctor.PUT("/patients/:id/medical-info", auth.RequirePermission(models.PermissionMedicalWrite), patientHandler.UpdateMedicalInfo)
+			}
+
+			// Audit routes
+			protected.GET("/audit", auth.RequirePermission(models.PermissionAuditRead), auditHandler.List)
+			protected.GET("/audit/verify", auth.RequirePermission(models.PermissionAuditRead), auditHandler.Verify)
+
+			// Administration routes
+			adminRoutes := protected.Group("/admin")
+			{
+				adminRoutes.GET("/users", auth.RequirePermission(models.PermissionUserRead), adminHandler.ListUsers)
+				adminRoutes.GET("/users/deleted", auth.RequirePermission(models.PermissionUserRead), adminHandler.ListDeletedUsers)
+				adminRoutes.GET("/users/:id", auth.RequirePermission(models.PermissionUserRead), adminHandler.GetUser)
+				adminRoutes.POST("/users", auth.RequirePermission(models.PermissionUserWrite), adminHandler.CreateUser)
+				adminRoutes.DELETE("/users/:id", auth.RequirePermission(models.PermissionUserWrite), adminHandler.DeleteUser)
+				adminRoutes.POST("/users/:id/restore", auth.RequirePermission(models.PermissionUserWrite), adminHandler.RestoreUser)
+				adminRoutes.PUT("/users/:id/status", auth.RequirePermission(models.PermissionUserWrite), adminHandler.UpdateUserStatus)
+				adminRoutes.PUT("/users/:id/roles", auth.RequirePermission(models.PermissionUserWrite), adminHandler.UpdateUserRoles)
+				adminRoutes.POST("/users/:id/reset-password", auth.RequirePermission(models.PermissionUserWrite), adminHandler.ResetPassword)
+				adminRoutes.POST("/users/:id/approve", auth.RequirePermission(models.PermissionUserWrite), adminHandler.ApproveUser)
+				adminRoutes.POST("/users/:id/reject", auth.RequirePermission(models.PermissionUserWrite), adminHandler.RejectUser)
+				adminRoutes.GET("/invitations", auth.RequirePermission(models.PermissionUserRead), adminHandler.ListInvitations)
+				adminRoutes.POST("/invitations", auth.RequirePermission(models.PermissionUserWrite), adminHandler.CreateInvitation)
+				adminRoutes.DELETE("/invitations/:id", auth.RequirePermission(models.PermissionUserWrite), adminHandler.RevokeInvitation)
+				adminRoutes.GET("/roles", auth.RequirePermission(models.PermissionRoleManage), adminHandler.ListRoles)
+				adminRoutes.POST("/roles", auth.RequirePermission(models.PermissionRoleManage), adminHandler.CreateRole)
+				adminRoutes.PUT("/roles/:id/permissions", auth.RequirePermission(models.PermissionRoleManage), adminHandler.UpdateRolePermissions)
+				adminRoutes.GET("/permissions", auth.RequirePermission(models.PermissionRoleManage), adminHandler.ListPermissions)
+				adminRoutes.GET("/patients/deleted", auth.RequirePermission(models.PermissionPatientRestore), patientHandler.GetDeletedPatients)
+				adminRoutes.POST("/patients/:id/restore", auth.RequirePermission(models.PermissionPatientRestore), patientHandler.RestorePatient)
 			}
 		}
 	}
@@ -130,4 +433,19 @@ func main() {
 
 	log.Printf("Server starting on port %s", port)
 	log.Fatal(http.ListenAndServe(":"+port, router))
-}
\ No newline at end of file
+}
+
+// accessLogLine formats a request for the access log like gin's default
+// logger, but without the query string.
+func accessLogLine(param gin.LogFormatterParams) string {
+	path, _, _ := strings.Cut(param.Path, "?")
+	return fmt.Sprintf("[GIN] %v | %3d | %13v | %15s | %-7s %#v\n%s",
+		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+		param.StatusCode,
+		param.Latency,
+		param.ClientIP,
+		param.Method,
+		path,
+		param.ErrorMessage,
+	)
+}
@@ -0,0 +1,594 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"hospital-management/internal/audit"
+	"hospital-management/internal/auth"
+	"hospital-management/internal/models"
+	"hospital-management/internal/user"
+	"hospital-management/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type Handler struct {
+	userService  *user.Service
+	authService  *auth.Service
+	auditService *audit.Service
+}
+
+func NewHandler(userService *user.Service, authService *auth.Service, auditService *audit.Service) *Handler {
+	return &Handler{
+		userService:  userService,
+		authService:  authService,
+		auditService: auditService,
+	}
+}
+
+// @Summary List users
+// @Description List staff accounts filtered by role, status or search text
+// @Tags admin
+// @Security Bearer
+// @Produce json
+// @Param role query string false "Role name"
+// @Param is_active query bool false "Active status"
+// @Param q query string false "Search username, email or name"
+// @Success 200 {object} utils.Response
+// @Router /admin/users [get]
+func (h *Handler) ListUsers(c *gin.Context) {
+	var filter models.UserFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid filter", err)
+		return
+	}
+
+	users, err := h.userService.List(filter)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get users", err)
+		return
+	}
+
+	h.auditService.Record(c, "user.list", "user", 0, filter)
+	utils.SuccessResponse(c, "Users retrieved successfully", users)
+}
+
+// @Summary Get user
+// @Description Get a staff account by ID
+// @Tags admin
+// @Security Bearer
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/users/{id} [get]
+func (h *Handler) GetUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	u, err := h.userService.GetByIDWithRoles(uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "User not found", err)
+		return
+	}
+
+	h.auditService.Record(c, "user.read", "user", u.ID, nil)
+	if utils.NotModified(c, u.Version) {
+		return
+	}
+	utils.SuccessResponse(c, "User retrieved successfully", u)
+}
+
+// @Summary Create user
+// @Description Create a staff account with one or more roles
+// @Tags admin
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param request body models.CreateUserRequest true "User data"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Router /admin/users [post]
+func (h *Handler) CreateUser(c *gin.Context) {
+	var req models.CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	u, err := h.userService.CreateWithRoles(req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create user", err)
+		return
+	}
+
+	h.auditService.Record(c, "user.create", "user", u.ID, gin.H{
+		"username": u.Username,
+		"roles":    req.Roles,
+	})
+	utils.SuccessResponse(c, "User created successfully", u)
+}
+
+// @Summary Activate or deactivate user
+// @Description Deactivating a user ends all of their sessions immediately
+// @Tags admin
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param If-Match header string true "ETag of the user"
+// @Param request body models.UpdateUserStatusRequest true "Status"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 412 {object} utils.Response
+// @Failure 428 {object} utils.Response
+// @Router /admin/users/{id}/status [put]
+func (h *Handler) UpdateUserStatus(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	match, ok := utils.IfMatch(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateUserStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	if uint(id) == utils.CurrentUserID(c) && !*req.IsActive {
+		utils.ErrorResponse(c, http.StatusBadRequest, "You cannot deactivate your own account", nil)
+		return
+	}
+
+	u, err := h.userService.SetActive(uint(id), match, *req.IsActive)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err, http.StatusInternalServerError), "Failed to update user status", err)
+		return
+	}
+
+	if !u.IsActive {
+		if err := h.authService.RevokeUserSessions(u.ID); err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to revoke user sessions", err)
+			return
+		}
+	}
+
+	action := "user.reactivate"
+	if !u.IsActive {
+		action = "user.deactivate"
+	}
+	h.auditService.Record(c, action, "user", u.ID, nil)
+	utils.SetETag(c, u.Version)
+	utils.SuccessResponse(c, "User status updated successfully", u)
+}
+
+// @Summary Delete user
+// @Description Soft-delete a staff account and end its sessions. The account can be restored.
+// @Tags admin
+// @Security Bearer
+// @Produce json
+// @Param id path int true "User ID"
+// @Param If-Match header string true "ETag of the user"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 412 {object} utils.Response
+// @Failure 428 {object} utils.Response
+// @Router /admin/users/{id} [delete]
+func (h *Handler) DeleteUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	match, ok := utils.IfMatch(c)
+	if !ok {
+		return
+	}
+
+	if uint(id) == utils.CurrentUserID(c) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "You cannot delete your own account", nil)
+		return
+	}
+
+	if err := h.userService.Delete(uint(id), match); err != nil {
+		utils.ErrorResponse(c, statusFor(err, http.StatusNotFound), "Failed to delete user", err)
+		return
+	}
+
+	if err := h.authService.RevokeUserSessions(uint(id)); err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to revoke user sessions", err)
+		return
+	}
+
+	h.auditService.Record(c, "user.delete", "user", uint(id), nil)
+	utils.SuccessResponse(c, "User deleted successfully", nil)
+}
+
+// @Summary List deleted users
+// @Description List soft-deleted staff accounts
+// @Tags admin
+// @Security Bearer
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /admin/users/deleted [get]
+func (h *Handler) ListDeletedUsers(c *gin.Context) {
+	users, err := h.userService.ListDeleted()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get deleted users", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Deleted users retrieved successfully", users)
+}
+
+// @Summary Restore user
+// @Description Restore a soft-deleted staff account
+// @Tags admin
+// @Security Bearer
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/users/{id}/restore [post]
+func (h *Handler) RestoreUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	u, err := h.userService.Restore(uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Failed to restore user", err)
+		return
+	}
+
+	h.auditService.Record(c, "user.restore", "user", u.ID, nil)
+	utils.SuccessResponse(c, "User restored successfully", u)
+}
+
+// @Summary Change user roles
+// @Description Replace the roles held by a user
+// @Tags admin
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param If-Match header string true "ETag of the user"
+// @Param request body models.UpdateUserRolesRequest true "Roles"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 412 {object} utils.Response
+// @Failure 428 {object} utils.Response
+// @Router /admin/users/{id}/roles [put]
+func (h *Handler) UpdateUserRoles(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	match, ok := utils.IfMatch(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateUserRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	before, err := h.userService.GetByIDWithRoles(uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "User not found", err)
+		return
+	}
+	previousRoles := before.RoleNames()
+
+	u, err := h.userService.SetRoles(uint(id), match, req.Roles)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err, http.StatusBadRequest), "Failed to update user roles", err)
+		return
+	}
+
+	h.auditService.Record(c, "user.roles.update", "user", u.ID, gin.H{
+		"before": previousRoles,
+		"after":  u.RoleNames(),
+	})
+	utils.SetETag(c, u.Version)
+	utils.SuccessResponse(c, "User roles updated successfully", u)
+}
+
+// @Summary Force password reset
+// @Description Set a new or generated temporary password, end the user's sessions and require a change at next login
+// @Tags admin
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body models.ResetPasswordRequest false "Optional new password"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Router /admin/users/{id}/reset-password [post]
+func (h *Handler) ResetPassword(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	var req models.ResetPasswordRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+			return
+		}
+	}
+
+	password, err := h.userService.ResetPassword(uint(id), req.Password)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to reset password", err)
+		return
+	}
+
+	if err := h.authService.RevokeUserSessions(uint(id)); err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to revoke user sessions", err)
+		return
+	}
+
+	h.auditService.Record(c, "user.password.reset", "user", uint(id), gin.H{
+		"generated": req.Password == "",
+	})
+	utils.SuccessResponse(c, "Password reset successfully", gin.H{
+		"temporary_password": password,
+	})
+}
+
+// @Summary Approve registration
+// @Description Activate an account that registered while approval was required
+// @Tags admin
+// @Security Bearer
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Router /admin/users/{id}/approve [post]
+func (h *Handler) ApproveUser(c *gin.Context) {
+	h.reviewUser(c, true)
+}
+
+// @Summary Reject registration
+// @Description Remove an account from the approval queue without activating it
+// @Tags admin
+// @Security Bearer
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Router /admin/users/{id}/reject [post]
+func (h *Handler) RejectUser(c *gin.Context) {
+	h.reviewUser(c, false)
+}
+
+func (h *Handler) reviewUser(c *gin.Context, approve bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	u, err := h.userService.Review(uint(id), approve)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to review user", err)
+		return
+	}
+
+	action, message := "user.approve", "User approved successfully"
+	if !approve {
+		action, message = "user.reject", "User rejected successfully"
+	}
+	h.auditService.Record(c, action, "user", u.ID, nil)
+	utils.SuccessResponse(c, message, u)
+}
+
+// @Summary List invitations
+// @Description List registration invitations, optionally only those still redeemable
+// @Tags admin
+// @Security Bearer
+// @Produce json
+// @Param pending query bool false "Only unused, unexpired invitations"
+// @Success 200 {object} utils.Response
+// @Router /admin/invitations [get]
+func (h *Handler) ListInvitations(c *gin.Context) {
+	pendingOnly := c.Query("pending") == "true"
+
+	invitations, err := h.authService.ListInvitations(pendingOnly)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get invitations", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Invitations retrieved successfully", invitations)
+}
+
+// @Summary Create invitation
+// @Description Issue a single-use registration token bound to an email and role. The token is only returned once.
+// @Tags admin
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param request body models.CreateInvitationRequest true "Invitation data"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Router /admin/invitations [post]
+func (h *Handler) CreateInvitation(c *gin.Context) {
+	var req models.CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	invitation, token, err := h.authService.CreateInvitation(req, utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create invitation", err)
+		return
+	}
+
+	h.auditService.Record(c, "invitation.create", "invitation", invitation.ID, gin.H{
+		"email": invitation.Email,
+		"role":  invitation.Role,
+	})
+	utils.SuccessResponse(c, "Invitation created successfully", gin.H{
+		"invitation":   invitation,
+		"invite_token": token,
+	})
+}
+
+// @Summary Revoke invitation
+// @Description Revoke an unused invitation
+// @Tags admin
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Invitation ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Router /admin/invitations/{id} [delete]
+func (h *Handler) RevokeInvitation(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid invitation ID", err)
+		return
+	}
+
+	if err := h.authService.RevokeInvitation(uint(id)); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to revoke invitation", err)
+		return
+	}
+
+	h.auditService.Record(c, "invitation.revoke", "invitation", uint(id), nil)
+	utils.SuccessResponse(c, "Invitation revoked successfully", nil)
+}
+
+// @Summary List roles
+// @Description List roles with their permissions
+// @Tags admin
+// @Security Bearer
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /admin/roles [get]
+func (h *Handler) ListRoles(c *gin.Context) {
+	roles, err := h.userService.ListRoles()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get roles", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Roles retrieved successfully", roles)
+}
+
+// @Summary Create role
+// @Description Create a role from existing permissions
+// @Tags admin
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param request body models.CreateRoleRequest true "Role data"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Router /admin/roles [post]
+func (h *Handler) CreateRole(c *gin.Context) {
+	var req models.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	role, err := h.userService.CreateRole(req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create role", err)
+		return
+	}
+
+	h.auditService.Record(c, "role.create", "role", role.ID, gin.H{
+		"name":        role.Name,
+		"permissions": req.Permissions,
+	})
+	utils.SuccessResponse(c, "Role created successfully", role)
+}
+
+// @Summary Update role permissions
+// @Description Replace the permissions granted by a role
+// @Tags admin
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Role ID"
+// @Param request body models.UpdateRolePermissionsRequest true "Permissions"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Router /admin/roles/{id}/permissions [put]
+func (h *Handler) UpdateRolePermissions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid role ID", err)
+		return
+	}
+
+	var req models.UpdateRolePermissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	role, err := h.userService.SetRolePermissions(uint(id), req.Permissions)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update role permissions", err)
+		return
+	}
+
+	h.auditService.Record(c, "role.permissions.update", "role", role.ID, gin.H{
+		"permissions": req.Permissions,
+	})
+	utils.SuccessResponse(c, "Role permissions updated successfully", role)
+}
+
+// @Summary List permissions
+// @Description List every permission that can be granted to a role
+// @Tags admin
+// @Security Bearer
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /admin/permissions [get]
+func (h *Handler) ListPermissions(c *gin.Context) {
+	permissions, err := h.userService.ListPermissions()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get permissions", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Permissions retrieved successfully", permissions)
+}
+
+// statusFor maps user service errors to HTTP status codes, falling back to
+// the given status for errors it does not recognise.
+func statusFor(err error, fallback int) int {
+	switch {
+	case errors.Is(err, utils.ErrVersionConflict):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	}
+	return fallback
+}
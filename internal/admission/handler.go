@@ -0,0 +1,459 @@
+package admission
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+	"hospital-management/internal/audit"
+	"hospital-management/internal/models"
+	"hospital-management/internal/patient"
+	"hospital-management/internal/user"
+	"hospital-management/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// heartbeatInterval is how often an idle board stream sends a ping so that
+// proxies keep the connection open.
+const heartbeatInterval = 30 * time.Second
+
+type Handler struct {
+	service *Service
+	audit   *audit.Service
+}
+
+func NewHandler(service *Service, auditService *audit.Service) *Handler {
+	return &Handler{service: service, audit: auditService}
+}
+
+// wardDiffIgnored lists fields left out of audit diffs because they change
+// on every write or are loaded from other tables.
+var wardDiffIgnored = []string{"updated_at", "rooms"}
+
+// ListWards returns the wards with their rooms and beds. Pass
+// include_inactive=true to include closed wards.
+func (h *Handler) ListWards(c *gin.Context) {
+	wards, err := h.service.ListWards(c.Query("include_inactive") == "true")
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get wards", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Wards retrieved successfully", wards)
+}
+
+func (h *Handler) GetWard(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ward ID", err)
+		return
+	}
+
+	ward, err := h.service.GetWard(uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Ward not found", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Ward retrieved successfully", ward)
+}
+
+func (h *Handler) CreateWard(c *gin.Context) {
+	var req models.CreateWardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	ward, err := h.service.CreateWard(req)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to create ward", err)
+		return
+	}
+
+	changes, _ := utils.DiffFields(nil, ward, wardDiffIgnored...)
+	h.audit.Log(c, audit.Entry{
+		Action:       "ward.create",
+		ResourceType: "ward",
+		ResourceID:   ward.ID,
+		Changes:      changes,
+	})
+
+	utils.SuccessResponse(c, "Ward created successfully", ward)
+}
+
+func (h *Handler) UpdateWard(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ward ID", err)
+		return
+	}
+
+	var req models.UpdateWardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	before, err := h.service.GetWard(uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Ward not found", err)
+		return
+	}
+
+	ward, err := h.service.UpdateWard(uint(id), req)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to update ward", err)
+		return
+	}
+
+	changes, _ := utils.DiffFields(before, ward, wardDiffIgnored...)
+	h.audit.Log(c, audit.Entry{
+		Action:       "ward.update",
+		ResourceType: "ward",
+		ResourceID:   ward.ID,
+		Changes:      changes,
+	})
+
+	utils.SuccessResponse(c, "Ward updated successfully", ward)
+}
+
+func (h *Handler) AddRoom(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ward ID", err)
+		return
+	}
+
+	var req models.CreateRoomRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	room, err := h.service.AddRoom(uint(id), req)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to add room", err)
+		return
+	}
+
+	h.audit.Log(c, audit.Entry{
+		Action:       "room.create",
+		ResourceType: "room",
+		ResourceID:   room.ID,
+		Details:      gin.H{"ward_id": room.WardID, "number": room.Number},
+	})
+
+	utils.SuccessResponse(c, "Room added successfully", room)
+}
+
+func (h *Handler) AddBed(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid room ID", err)
+		return
+	}
+
+	var req models.CreateBedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	bed, err := h.service.AddBed(uint(id), req)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to add bed", err)
+		return
+	}
+
+	h.audit.Log(c, audit.Entry{
+		Action:       "bed.create",
+		ResourceType: "bed",
+		ResourceID:   bed.ID,
+		Details:      gin.H{"room_id": bed.RoomID, "label": bed.Label},
+	})
+
+	utils.SuccessResponse(c, "Bed added successfully", bed)
+}
+
+func (h *Handler) UpdateBedStatus(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid bed ID", err)
+		return
+	}
+
+	var req models.UpdateBedStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	bed, err := h.service.SetBedStatus(uint(id), req)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to update bed", err)
+		return
+	}
+
+	h.audit.Log(c, audit.Entry{
+		Action:       "bed.status",
+		ResourceType: "bed",
+		ResourceID:   bed.ID,
+		Details:      gin.H{"status": bed.Status},
+	})
+
+	utils.SuccessResponse(c, "Bed updated successfully", bed)
+}
+
+func (h *Handler) Admit(c *gin.Context) {
+	var req models.AdmitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	admission, err := h.service.Admit(req, utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to admit patient", err)
+		return
+	}
+
+	h.audit.Log(c, audit.Entry{
+		Action:       "admission.create",
+		ResourceType: "admission",
+		ResourceID:   admission.ID,
+		PatientID:    &admission.PatientID,
+		Details:      gin.H{"bed_id": admission.BedID, "attending_doctor_id": admission.AttendingDoctorID},
+	})
+
+	utils.SuccessResponse(c, "Patient admitted successfully", admission)
+}
+
+func (h *Handler) Transfer(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid admission ID", err)
+		return
+	}
+
+	var req models.TransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	admission, err := h.service.Transfer(uint(id), req, utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to transfer patient", err)
+		return
+	}
+
+	transfer := admission.Transfers[len(admission.Transfers)-1]
+	h.audit.Log(c, audit.Entry{
+		Action:       "admission.transfer",
+		ResourceType: "admission",
+		ResourceID:   admission.ID,
+		PatientID:    &admission.PatientID,
+		Details:      gin.H{"transfer": transfer},
+	})
+
+	utils.SuccessResponse(c, "Patient transferred successfully", admission)
+}
+
+func (h *Handler) Discharge(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid admission ID", err)
+		return
+	}
+
+	var req models.DischargeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	admission, err := h.service.Discharge(uint(id), req, utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to discharge patient", err)
+		return
+	}
+
+	h.audit.Log(c, audit.Entry{
+		Action:       "admission.discharge",
+		ResourceType: "admission",
+		ResourceID:   admission.ID,
+		PatientID:    &admission.PatientID,
+		Details:      gin.H{"disposition": req.Disposition},
+	})
+
+	utils.SuccessResponse(c, "Patient discharged successfully", admission)
+}
+
+func (h *Handler) GetAdmission(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid admission ID", err)
+		return
+	}
+
+	admission, err := h.service.GetAdmission(uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Admission not found", err)
+		return
+	}
+
+	h.audit.Log(c, audit.Entry{
+		Action:       "admission.read",
+		ResourceType: "admission",
+		ResourceID:   admission.ID,
+		PatientID:    &admission.PatientID,
+	})
+	utils.SuccessResponse(c, "Admission retrieved successfully", admission)
+}
+
+// GetAdmissions lists admissions, filtered by ward, doctor, patient or
+// status.
+func (h *Handler) GetAdmissions(c *gin.Context) {
+	var filter models.AdmissionFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query", err)
+		return
+	}
+
+	admissions, meta, err := h.service.ListAdmissions(filter)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to get admissions", err)
+		return
+	}
+
+	h.logList(c, "admission.list", nil, admissions)
+	utils.PaginatedResponse(c, "Admissions retrieved successfully", admissions, meta)
+}
+
+// GetPatientAdmissions returns the patient's admission history.
+func (h *Handler) GetPatientAdmissions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	var filter models.AdmissionFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query", err)
+		return
+	}
+
+	admissions, meta, err := h.service.ListForPatient(uint(id), filter)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to get admissions", err)
+		return
+	}
+
+	patientID := uint(id)
+	h.logList(c, "admission.list", &patientID, admissions)
+	utils.PaginatedResponse(c, "Admissions retrieved successfully", admissions, meta)
+}
+
+// GetBoard returns the bed occupancy board.
+func (h *Handler) GetBoard(c *gin.Context) {
+	board, err := h.service.Board()
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to get bed board", err)
+		return
+	}
+
+	h.logBoard(c, "admission.board.read", board)
+	utils.SuccessResponse(c, "Bed board retrieved successfully", board)
+}
+
+// StreamBoard sends the bed occupancy board and then a fresh "board" event
+// whenever a bed changes, with a "ping" event when idle, until the client
+// disconnects.
+func (h *Handler) StreamBoard(c *gin.Context) {
+	updates, unsubscribe := h.service.Subscribe()
+	defer unsubscribe()
+
+	board, err := h.service.Board()
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to get bed board", err)
+		return
+	}
+	h.logBoard(c, "admission.board.stream", board)
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.SSEvent(EventName, board)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-updates:
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.Name, event.Data)
+			return true
+		case now := <-heartbeat.C:
+			c.SSEvent("ping", now.UTC())
+			return true
+		}
+	})
+}
+
+// logList audits a listing of admissions.
+func (h *Handler) logList(c *gin.Context, action string, patientID *uint, admissions []models.Admission) {
+	ids := make([]uint, 0, len(admissions))
+	for _, a := range admissions {
+		ids = append(ids, a.ID)
+	}
+	h.audit.Log(c, audit.Entry{
+		Action:       action,
+		ResourceType: "admission",
+		PatientID:    patientID,
+		Details:      gin.H{"admission_ids": ids},
+	})
+}
+
+// logBoard audits a view of the bed board with the patients it disclosed.
+func (h *Handler) logBoard(c *gin.Context, action string, board *models.OccupancyBoard) {
+	ids := []uint{}
+	patientIDs := []uint{}
+	for _, ward := range board.Wards {
+		for _, bed := range ward.Beds {
+			if bed.Admission != nil {
+				ids = append(ids, bed.Admission.ID)
+				patientIDs = append(patientIDs, bed.Admission.PatientID)
+			}
+		}
+	}
+	h.audit.Log(c, audit.Entry{
+		Action:       action,
+		ResourceType: "admission",
+		Details:      gin.H{"admission_ids": ids, "patient_ids": patientIDs},
+	})
+}
+
+// statusFor maps service errors to HTTP status codes.
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrDuplicateWard), errors.Is(err, ErrDuplicateRoom), errors.Is(err, ErrDuplicateBed),
+		errors.Is(err, ErrBedUnavailable), errors.Is(err, ErrBedOccupied), errors.Is(err, ErrAlreadyAdmitted),
+		errors.Is(err, ErrNotAdmitted), errors.Is(err, ErrWardInactive):
+		return http.StatusConflict
+	case errors.Is(err, user.ErrNotDoctor), errors.Is(err, ErrNoChange), errors.Is(err, patient.ErrPatientMerged):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,323 @@
+package admission
+
+import (
+	"time"
+	"hospital-management/internal/models"
+	"hospital-management/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// withDetails preloads what an admission view shows.
+func withDetails(db *gorm.DB) *gorm.DB {
+	return db.Preload("Patient", utils.WithDeleted).
+		Preload("AttendingDoctor", utils.WithDeleted).
+		Preload("Bed.Room").
+		Preload("Transfers", func(db *gorm.DB) *gorm.DB {
+			return db.Order("transferred_at, id")
+		})
+}
+
+// withLayout preloads a ward's rooms and beds in display order.
+func withLayout(db *gorm.DB) *gorm.DB {
+	return db.Preload("Rooms", func(db *gorm.DB) *gorm.DB {
+		return db.Order("number")
+	}).Preload("Rooms.Beds", func(db *gorm.DB) *gorm.DB {
+		return db.Order("label")
+	})
+}
+
+// conflictErrors maps the unique indexes on wards, rooms, beds and
+// admissions to admission errors.
+var conflictErrors = map[string]error{
+	"idx_wards_name":             ErrDuplicateWard,
+	"idx_rooms_ward_number":      ErrDuplicateRoom,
+	"idx_beds_room_label":        ErrDuplicateBed,
+	"admissions_one_per_bed":     ErrBedOccupied,
+	"admissions_one_per_patient": ErrAlreadyAdmitted,
+}
+
+func (r *Repository) ListWards(includeInactive bool) ([]models.Ward, error) {
+	query := withLayout(r.db)
+	if !includeInactive {
+		query = query.Where("is_active = ?", true)
+	}
+
+	var wards []models.Ward
+	err := query.Order("name").Find(&wards).Error
+	return wards, err
+}
+
+func (r *Repository) GetWard(id uint) (*models.Ward, error) {
+	var ward models.Ward
+	err := withLayout(r.db).First(&ward, id).Error
+	return &ward, err
+}
+
+func (r *Repository) CreateWard(ward *models.Ward) error {
+	return utils.TranslateConflict(r.db.Create(ward).Error, conflictErrors)
+}
+
+func (r *Repository) UpdateWard(ward *models.Ward) error {
+	return utils.TranslateConflict(r.db.Omit("Rooms").Save(ward).Error, conflictErrors)
+}
+
+func (r *Repository) CreateRoom(room *models.Room) error {
+	return utils.TranslateConflict(r.db.Omit("Beds").Create(room).Error, conflictErrors)
+}
+
+func (r *Repository) GetRoom(id uint) (*models.Room, error) {
+	var room models.Room
+	err := r.db.First(&room, id).Error
+	return &room, err
+}
+
+func (r *Repository) CreateBed(bed *models.Bed) error {
+	return utils.TranslateConflict(r.db.Omit("Room").Create(bed).Error, conflictErrors)
+}
+
+// GetBed returns a bed together with its room.
+func (r *Repository) GetBed(id uint) (*models.Bed, error) {
+	var bed models.Bed
+	err := r.db.Preload("Room").First(&bed, id).Error
+	return &bed, err
+}
+
+// SetBedStatus changes the housekeeping status of a bed that no patient
+// occupies, locking it against a concurrent admission.
+func (r *Repository) SetBedStatus(id uint, status string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		bed, err := lockBed(tx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkBedFree(tx, bed.ID); err != nil {
+			return err
+		}
+		return tx.Model(bed).Update("status", status).Error
+	})
+}
+
+// Admit saves an admission after locking its bed and checking that the bed
+// is available and the patient is not already admitted. The unique indexes
+// on active admissions back these checks up.
+func (r *Repository) Admit(admission *models.Admission) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		bed, err := lockBed(tx, admission.BedID)
+		if err != nil {
+			return err
+		}
+		if bed.Status != models.BedAvailable {
+			return ErrBedUnavailable
+		}
+		if err := checkBedFree(tx, bed.ID); err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.Admission{}).
+			Where("patient_id = ? AND status = ?", admission.PatientID, models.AdmissionAdmitted).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrAlreadyAdmitted
+		}
+
+		return utils.TranslateConflict(tx.Omit("Patient", "Bed", "AttendingDoctor", "Transfers").Create(admission).Error, conflictErrors)
+	})
+}
+
+// Transfer moves an active admission to the transfer's bed and doctor and
+// records the transfer. When the bed changes both beds are locked, in ID
+// order so that crossing transfers cannot deadlock, and the bed left behind
+// needs cleaning.
+func (r *Repository) Transfer(admissionID uint, transfer *models.AdmissionTransfer) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		admission, err := lockAdmission(tx, admissionID)
+		if err != nil {
+			return err
+		}
+
+		transfer.AdmissionID = admission.ID
+		transfer.PatientID = admission.PatientID
+		transfer.FromBedID = admission.BedID
+		transfer.FromDoctorID = admission.AttendingDoctorID
+		if transfer.ToBedID == 0 {
+			transfer.ToBedID = admission.BedID
+		}
+		if transfer.ToDoctorID == 0 {
+			transfer.ToDoctorID = admission.AttendingDoctorID
+		}
+		if transfer.ToBedID == transfer.FromBedID && transfer.ToDoctorID == transfer.FromDoctorID {
+			return ErrNoChange
+		}
+
+		if transfer.ToBedID != transfer.FromBedID {
+			first, second := transfer.FromBedID, transfer.ToBedID
+			if second < first {
+				first, second = second, first
+			}
+			if _, err := lockBed(tx, first); err != nil {
+				return err
+			}
+			if _, err := lockBed(tx, second); err != nil {
+				return err
+			}
+
+			var target models.Bed
+			if err := tx.First(&target, transfer.ToBedID).Error; err != nil {
+				return err
+			}
+			if target.Status != models.BedAvailable {
+				return ErrBedUnavailable
+			}
+			if err := checkBedFree(tx, target.ID); err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Bed{}).Where("id = ?", transfer.FromBedID).
+				Update("status", models.BedCleaning).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := utils.TranslateConflict(tx.Model(admission).Updates(map[string]interface{}{
+			"bed_id":              transfer.ToBedID,
+			"attending_doctor_id": transfer.ToDoctorID,
+		}).Error, conflictErrors); err != nil {
+			return err
+		}
+		return tx.Create(transfer).Error
+	})
+}
+
+// Discharge ends an active admission and leaves its bed to be cleaned.
+func (r *Repository) Discharge(admissionID uint, disposition, note string, dischargedBy uint, at time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		admission, err := lockAdmission(tx, admissionID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(admission).Updates(map[string]interface{}{
+			"status":                models.AdmissionDischarged,
+			"discharged_at":         at,
+			"discharged_by_id":      dischargedBy,
+			"discharge_disposition": disposition,
+			"discharge_note":        note,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Bed{}).Where("id = ?", admission.BedID).
+			Update("status", models.BedCleaning).Error
+	})
+}
+
+func (r *Repository) GetAdmission(id uint) (*models.Admission, error) {
+	var admission models.Admission
+	err := withDetails(r.db).First(&admission, id).Error
+	return &admission, err
+}
+
+// FindActiveForPatient returns the patient's current admission.
+func (r *Repository) FindActiveForPatient(patientID uint) (*models.Admission, error) {
+	var admission models.Admission
+	err := withDetails(r.db).
+		Where("patient_id = ? AND status = ?", patientID, models.AdmissionAdmitted).
+		First(&admission).Error
+	return &admission, err
+}
+
+// ListAdmissions returns one page of admissions, most recent first.
+func (r *Repository) ListAdmissions(filter models.AdmissionFilter) ([]models.Admission, *utils.Meta, error) {
+	page, pageSize := utils.NormalizePage(filter.Page, filter.PageSize)
+
+	query := r.db.Model(&models.Admission{})
+	if filter.PatientID != 0 {
+		query = query.Where("admissions.patient_id = ?", filter.PatientID)
+	}
+	if filter.AttendingDoctorID != 0 {
+		query = query.Where("admissions.attending_doctor_id = ?", filter.AttendingDoctorID)
+	}
+	if filter.Status != "" {
+		query = query.Where("admissions.status = ?", filter.Status)
+	}
+	if filter.WardID != 0 {
+		query = query.Where("admissions.bed_id IN (?)", r.db.Model(&models.Bed{}).
+			Select("beds.id").
+			Joins("JOIN rooms ON rooms.id = beds.room_id").
+			Where("rooms.ward_id = ?", filter.WardID))
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var admissions []models.Admission
+	err := withDetails(query).
+		Order("admissions.admitted_at DESC, admissions.id DESC").
+		Limit(pageSize).
+		Offset((page - 1) * pageSize).
+		Find(&admissions).Error
+
+	meta := &utils.Meta{
+		Total:    total,
+		PageSize: pageSize,
+		Page:     page,
+		HasMore:  int64(page*pageSize) < total,
+	}
+	return admissions, meta, err
+}
+
+// ListActiveAdmissions returns every current admission as the board shows
+// it.
+func (r *Repository) ListActiveAdmissions() ([]models.BedAdmission, error) {
+	var admissions []models.BedAdmission
+	err := r.db.Preload("Patient").
+		Preload("AttendingDoctor", utils.WithDeleted).
+		Where("status = ?", models.AdmissionAdmitted).
+		Find(&admissions).Error
+	return admissions, err
+}
+
+func lockBed(tx *gorm.DB, id uint) (*models.Bed, error) {
+	var bed models.Bed
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&bed, id).Error
+	return &bed, err
+}
+
+// lockAdmission locks an admission that is still active, returning
+// ErrNotAdmitted once it has been discharged.
+func lockAdmission(tx *gorm.DB, id uint) (*models.Admission, error) {
+	var admission models.Admission
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&admission, id).Error; err != nil {
+		return nil, err
+	}
+	if admission.Status != models.AdmissionAdmitted {
+		return nil, ErrNotAdmitted
+	}
+	return &admission, nil
+}
+
+// checkBedFree returns ErrBedOccupied when a patient is admitted to the bed.
+func checkBedFree(tx *gorm.DB, bedID uint) error {
+	var count int64
+	if err := tx.Model(&models.Admission{}).
+		Where("bed_id = ? AND status = ?", bedID, models.AdmissionAdmitted).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrBedOccupied
+	}
+	return nil
+}
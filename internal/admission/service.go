@@ -0,0 +1,307 @@
+package admission
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+	"hospital-management/internal/models"
+	"hospital-management/internal/patient"
+	"hospital-management/internal/user"
+	"hospital-management/pkg/events"
+	"hospital-management/pkg/utils"
+)
+
+const (
+	// Topic is the broker topic carrying bed occupancy board snapshots.
+	Topic = "beds"
+
+	// EventName names bed occupancy board events.
+	EventName = "board"
+)
+
+var (
+	ErrDuplicateWard   = errors.New("a ward with this name already exists")
+	ErrDuplicateRoom   = errors.New("the ward already has a room with this number")
+	ErrDuplicateBed    = errors.New("the room already has a bed with this label")
+	ErrWardInactive    = errors.New("ward is closed")
+	ErrBedUnavailable  = errors.New("bed is being cleaned or is out of service")
+	ErrBedOccupied     = errors.New("bed is occupied by another patient")
+	ErrAlreadyAdmitted = errors.New("patient is already admitted")
+	ErrNotAdmitted     = errors.New("admission has already been discharged")
+	ErrNoChange        = errors.New("transfer needs a different bed or attending doctor")
+)
+
+type Service struct {
+	repo           *Repository
+	patientService *patient.Service
+	userService    *user.Service
+	broker         *events.Broker
+}
+
+func NewService(repo *Repository, patientService *patient.Service, userService *user.Service, broker *events.Broker) *Service {
+	return &Service{
+		repo:           repo,
+		patientService: patientService,
+		userService:    userService,
+		broker:         broker,
+	}
+}
+
+// ListWards returns the wards with their rooms and beds. Closed wards are
+// only included when asked for.
+func (s *Service) ListWards(includeInactive bool) ([]models.Ward, error) {
+	return s.repo.ListWards(includeInactive)
+}
+
+func (s *Service) GetWard(id uint) (*models.Ward, error) {
+	return s.repo.GetWard(id)
+}
+
+func (s *Service) CreateWard(req models.CreateWardRequest) (*models.Ward, error) {
+	ward := &models.Ward{
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		IsActive:    true,
+	}
+	if err := s.repo.CreateWard(ward); err != nil {
+		return nil, err
+	}
+	s.publish()
+	return s.repo.GetWard(ward.ID)
+}
+
+// UpdateWard renames, describes, opens or closes a ward. Patients already
+// admitted to a closed ward stay until they are transferred or discharged.
+func (s *Service) UpdateWard(id uint, req models.UpdateWardRequest) (*models.Ward, error) {
+	ward, err := s.repo.GetWard(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		ward.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		ward.Description = *req.Description
+	}
+	if req.IsActive != nil {
+		ward.IsActive = *req.IsActive
+	}
+
+	if err := s.repo.UpdateWard(ward); err != nil {
+		return nil, err
+	}
+	s.publish()
+	return s.repo.GetWard(id)
+}
+
+func (s *Service) AddRoom(wardID uint, req models.CreateRoomRequest) (*models.Room, error) {
+	if _, err := s.repo.GetWard(wardID); err != nil {
+		return nil, err
+	}
+
+	room := &models.Room{WardID: wardID, Number: strings.TrimSpace(req.Number)}
+	if err := s.repo.CreateRoom(room); err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+func (s *Service) AddBed(roomID uint, req models.CreateBedRequest) (*models.Bed, error) {
+	if _, err := s.repo.GetRoom(roomID); err != nil {
+		return nil, err
+	}
+
+	bed := &models.Bed{RoomID: roomID, Label: strings.TrimSpace(req.Label), Status: models.BedAvailable}
+	if err := s.repo.CreateBed(bed); err != nil {
+		return nil, err
+	}
+	s.publish()
+	return s.repo.GetBed(bed.ID)
+}
+
+// SetBedStatus changes the housekeeping status of an unoccupied bed, for
+// example to make a cleaned bed available again.
+func (s *Service) SetBedStatus(id uint, req models.UpdateBedStatusRequest) (*models.Bed, error) {
+	if err := s.repo.SetBedStatus(id, req.Status); err != nil {
+		return nil, err
+	}
+	s.publish()
+	return s.repo.GetBed(id)
+}
+
+// Admit admits a patient to a bed under an attending doctor, who must be an
+// active doctor.
+func (s *Service) Admit(req models.AdmitRequest, admittedBy uint) (*models.Admission, error) {
+	if _, err := s.patientService.GetActivePatient(req.PatientID); err != nil {
+		return nil, err
+	}
+	if _, err := s.userService.GetActiveDoctor(req.AttendingDoctorID); err != nil {
+		return nil, err
+	}
+	if err := s.checkWardOpen(req.BedID); err != nil {
+		return nil, err
+	}
+
+	admission := &models.Admission{
+		PatientID:         req.PatientID,
+		BedID:             req.BedID,
+		AttendingDoctorID: req.AttendingDoctorID,
+		Status:            models.AdmissionAdmitted,
+		Reason:            strings.TrimSpace(req.Reason),
+		AdmittedByID:      admittedBy,
+		AdmittedAt:        time.Now().UTC(),
+	}
+	if err := s.repo.Admit(admission); err != nil {
+		return nil, err
+	}
+
+	s.publish()
+	return s.repo.GetAdmission(admission.ID)
+}
+
+// Transfer moves an admitted patient to another bed, hands them over to
+// another attending doctor, or both.
+func (s *Service) Transfer(id uint, req models.TransferRequest, transferredBy uint) (*models.Admission, error) {
+	if req.BedID == 0 && req.AttendingDoctorID == 0 {
+		return nil, ErrNoChange
+	}
+	if req.AttendingDoctorID != 0 {
+		if _, err := s.userService.GetActiveDoctor(req.AttendingDoctorID); err != nil {
+			return nil, err
+		}
+	}
+	if req.BedID != 0 {
+		if err := s.checkWardOpen(req.BedID); err != nil {
+			return nil, err
+		}
+	}
+
+	transfer := &models.AdmissionTransfer{
+		ToBedID:         req.BedID,
+		ToDoctorID:      req.AttendingDoctorID,
+		Reason:          req.Reason,
+		TransferredByID: transferredBy,
+		TransferredAt:   time.Now().UTC(),
+	}
+	if err := s.repo.Transfer(id, transfer); err != nil {
+		return nil, err
+	}
+
+	s.publish()
+	return s.repo.GetAdmission(id)
+}
+
+// Discharge ends an admission. The bed is left to be cleaned before the
+// next patient.
+func (s *Service) Discharge(id uint, req models.DischargeRequest, dischargedBy uint) (*models.Admission, error) {
+	if err := s.repo.Discharge(id, req.Disposition, req.Note, dischargedBy, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+
+	s.publish()
+	return s.repo.GetAdmission(id)
+}
+
+func (s *Service) GetAdmission(id uint) (*models.Admission, error) {
+	return s.repo.GetAdmission(id)
+}
+
+// ActiveAdmission returns the patient's current admission, or
+// gorm.ErrRecordNotFound when they are not admitted.
+func (s *Service) ActiveAdmission(patientID uint) (*models.Admission, error) {
+	return s.repo.FindActiveForPatient(patientID)
+}
+
+func (s *Service) ListAdmissions(filter models.AdmissionFilter) ([]models.Admission, *utils.Meta, error) {
+	return s.repo.ListAdmissions(filter)
+}
+
+// ListForPatient returns the patient's admissions, most recent first.
+func (s *Service) ListForPatient(patientID uint, filter models.AdmissionFilter) ([]models.Admission, *utils.Meta, error) {
+	if _, err := s.patientService.GetPatientByID(patientID); err != nil {
+		return nil, nil, err
+	}
+	filter.PatientID = patientID
+	return s.repo.ListAdmissions(filter)
+}
+
+// Board returns the occupancy of every bed in the open wards.
+func (s *Service) Board() (*models.OccupancyBoard, error) {
+	wards, err := s.repo.ListWards(false)
+	if err != nil {
+		return nil, err
+	}
+	admissions, err := s.repo.ListActiveAdmissions()
+	if err != nil {
+		return nil, err
+	}
+
+	byBed := make(map[uint]*models.BedAdmission, len(admissions))
+	for i := range admissions {
+		byBed[admissions[i].BedID] = &admissions[i]
+	}
+
+	board := &models.OccupancyBoard{Wards: make([]models.WardOccupancy, 0, len(wards)), GeneratedAt: time.Now().UTC()}
+	for _, ward := range wards {
+		occupancy := models.WardOccupancy{Beds: []models.BedOccupancy{}}
+		for _, room := range ward.Rooms {
+			for _, bed := range room.Beds {
+				admission := byBed[bed.ID]
+				occupancy.Beds = append(occupancy.Beds, models.BedOccupancy{
+					Bed:       bed,
+					Room:      room.Number,
+					Occupied:  admission != nil,
+					Admission: admission,
+				})
+				occupancy.Total++
+				switch {
+				case admission != nil:
+					occupancy.Occupied++
+				case bed.Status == models.BedAvailable:
+					occupancy.Available++
+				}
+			}
+		}
+		ward.Rooms = nil
+		occupancy.Ward = ward
+
+		board.Wards = append(board.Wards, occupancy)
+		board.Total += occupancy.Total
+		board.Occupied += occupancy.Occupied
+		board.Available += occupancy.Available
+	}
+	return board, nil
+}
+
+// Subscribe returns a channel of board snapshots, sent whenever a bed
+// changes, and a function ending the subscription.
+func (s *Service) Subscribe() (<-chan events.Event, func()) {
+	return s.broker.Subscribe(Topic)
+}
+
+// publish sends the current board to its subscribers.
+func (s *Service) publish() {
+	board, err := s.Board()
+	if err != nil {
+		log.Printf("admission: failed to publish bed board: %v", err)
+		return
+	}
+	s.broker.Publish(Topic, events.Event{Name: EventName, Data: board})
+}
+
+// checkWardOpen returns ErrWardInactive when the bed is in a closed ward.
+func (s *Service) checkWardOpen(bedID uint) error {
+	bed, err := s.repo.GetBed(bedID)
+	if err != nil {
+		return err
+	}
+	ward, err := s.repo.GetWard(bed.Room.WardID)
+	if err != nil {
+		return err
+	}
+	if !ward.IsActive {
+		return ErrWardInactive
+	}
+	return nil
+}
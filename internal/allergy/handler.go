@@ -0,0 +1,186 @@
+package allergy
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"hospital-management/internal/audit"
+	"hospital-management/internal/models"
+	"hospital-management/internal/patient"
+	"hospital-management/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type Handler struct {
+	service *Service
+	audit   *audit.Service
+}
+
+func NewHandler(service *Service, auditService *audit.Service) *Handler {
+	return &Handler{service: service, audit: auditService}
+}
+
+// allergyDiffIgnored lists fields left out of audit diffs because they
+// change on every write or are loaded from other tables.
+var allergyDiffIgnored = []string{"updated_at", "recorded_by"}
+
+// ids parses the :id patient and, when present, :allergyId parameters.
+func ids(c *gin.Context) (uint, uint, bool) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid patient ID", err)
+		return 0, 0, false
+	}
+	if c.Param("allergyId") == "" {
+		return uint(patientID), 0, true
+	}
+
+	allergyID, err := strconv.ParseUint(c.Param("allergyId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid allergy ID", err)
+		return 0, 0, false
+	}
+	return uint(patientID), uint(allergyID), true
+}
+
+// GetAllergies lists a patient's allergies. Entries marked entered in error
+// are included with ?include_errors=true.
+func (h *Handler) GetAllergies(c *gin.Context) {
+	patientID, _, ok := ids(c)
+	if !ok {
+		return
+	}
+
+	allergies, err := h.service.List(patientID, c.Query("include_errors") == "true")
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to get allergies", err)
+		return
+	}
+
+	allergyIDs := make([]uint, 0, len(allergies))
+	for _, a := range allergies {
+		allergyIDs = append(allergyIDs, a.ID)
+	}
+	h.audit.Log(c, audit.Entry{
+		Action:       "allergy.list",
+		ResourceType: "allergy",
+		PatientID:    &patientID,
+		Details:      gin.H{"allergy_ids": allergyIDs},
+	})
+
+	utils.SuccessResponse(c, "Allergies retrieved successfully", allergies)
+}
+
+func (h *Handler) CreateAllergy(c *gin.Context) {
+	patientID, _, ok := ids(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateAllergyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	allergy, err := h.service.Create(patientID, req, utils.CurrentPermissions(c), utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to record allergy", err)
+		return
+	}
+
+	changes, _ := utils.DiffFields(nil, allergy, allergyDiffIgnored...)
+	h.audit.Log(c, audit.Entry{
+		Action:       "allergy.create",
+		ResourceType: "allergy",
+		ResourceID:   allergy.ID,
+		PatientID:    &allergy.PatientID,
+		Changes:      changes,
+	})
+
+	utils.SuccessResponse(c, "Allergy recorded successfully", allergy)
+}
+
+func (h *Handler) UpdateAllergy(c *gin.Context) {
+	patientID, allergyID, ok := ids(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateAllergyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	before, err := h.service.Get(patientID, allergyID)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Allergy not found", err)
+		return
+	}
+
+	allergy, err := h.service.Update(patientID, allergyID, req, utils.CurrentPermissions(c), utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to update allergy", err)
+		return
+	}
+
+	changes, _ := utils.DiffFields(before, allergy, allergyDiffIgnored...)
+	h.audit.Log(c, audit.Entry{
+		Action:       "allergy.update",
+		ResourceType: "allergy",
+		ResourceID:   allergy.ID,
+		PatientID:    &allergy.PatientID,
+		Changes:      changes,
+	})
+
+	utils.SuccessResponse(c, "Allergy updated successfully", allergy)
+}
+
+// DeleteAllergy marks an allergy as entered in error. Allergies are never
+// removed from the record.
+func (h *Handler) DeleteAllergy(c *gin.Context) {
+	patientID, allergyID, ok := ids(c)
+	if !ok {
+		return
+	}
+
+	before, err := h.service.Get(patientID, allergyID)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Allergy not found", err)
+		return
+	}
+
+	allergy, err := h.service.MarkEnteredInError(patientID, allergyID, utils.CurrentPermissions(c), utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to delete allergy", err)
+		return
+	}
+
+	changes, _ := utils.DiffFields(before, allergy, allergyDiffIgnored...)
+	h.audit.Log(c, audit.Entry{
+		Action:       "allergy.delete",
+		ResourceType: "allergy",
+		ResourceID:   allergy.ID,
+		PatientID:    &allergy.PatientID,
+		Changes:      changes,
+	})
+
+	utils.SuccessResponse(c, "Allergy marked as entered in error", allergy)
+}
+
+// statusFor maps service errors to HTTP status codes.
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrVerifyForbidden), errors.Is(err, ErrEditVerified):
+		return http.StatusForbidden
+	case errors.Is(err, ErrEnteredInError):
+		return http.StatusConflict
+	case errors.Is(err, ErrInvalidOnset), errors.Is(err, patient.ErrPatientMerged):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
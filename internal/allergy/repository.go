@@ -0,0 +1,46 @@
+package allergy
+
+import (
+	"hospital-management/internal/models"
+	"hospital-management/pkg/utils"
+	"gorm.io/gorm"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func withRecorder(db *gorm.DB) *gorm.DB {
+	return db.Preload("RecordedBy", utils.WithDeleted)
+}
+
+func (r *Repository) Create(allergy *models.AllergyIntolerance) error {
+	return r.db.Omit("RecordedBy").Create(allergy).Error
+}
+
+func (r *Repository) Update(allergy *models.AllergyIntolerance) error {
+	return r.db.Omit("RecordedBy").Save(allergy).Error
+}
+
+// Get returns the patient's allergy with the given ID.
+func (r *Repository) Get(patientID, id uint) (*models.AllergyIntolerance, error) {
+	var allergy models.AllergyIntolerance
+	err := withRecorder(r.db).Where("patient_id = ?", patientID).First(&allergy, id).Error
+	return &allergy, err
+}
+
+// ListForPatient returns a patient's allergies, active ones first. Entries
+// marked entered_in_error are left out unless includeErrors is set.
+func (r *Repository) ListForPatient(patientID uint, includeErrors bool) ([]models.AllergyIntolerance, error) {
+	var allergies []models.AllergyIntolerance
+	query := withRecorder(r.db).Where("patient_id = ?", patientID)
+	if !includeErrors {
+		query = query.Where("verification_status <> ?", models.AllergyEnteredInError)
+	}
+	err := query.Order("status = 'active' DESC, substance, id").Find(&allergies).Error
+	return allergies, err
+}
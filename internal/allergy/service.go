@@ -0,0 +1,203 @@
+package allergy
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"hospital-management/internal/models"
+	"hospital-management/internal/patient"
+)
+
+var (
+	ErrInvalidOnset    = errors.New("onset must be a date formatted as YYYY-MM-DD and not in the future")
+	ErrVerifyForbidden = errors.New("only clinical staff can verify allergies")
+	ErrEditVerified    = errors.New("only clinical staff can change an allergy once it has been verified")
+	ErrEnteredInError  = errors.New("allergy was marked as entered in error")
+)
+
+type Service struct {
+	repo           *Repository
+	patientService *patient.Service
+}
+
+func NewService(repo *Repository, patientService *patient.Service) *Service {
+	return &Service{repo: repo, patientService: patientService}
+}
+
+// List returns the patient's allergies.
+func (s *Service) List(patientID uint, includeErrors bool) ([]models.AllergyIntolerance, error) {
+	if _, err := s.patientService.GetPatientByID(patientID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListForPatient(patientID, includeErrors)
+}
+
+func (s *Service) Get(patientID, id uint) (*models.AllergyIntolerance, error) {
+	return s.repo.Get(patientID, id)
+}
+
+// Create records an allergy. Without medical:write the entry stays
+// unverified.
+func (s *Service) Create(patientID uint, req models.CreateAllergyRequest, permissions []string, recordedBy uint) (*models.AllergyIntolerance, error) {
+	if _, err := s.patientService.GetActivePatient(patientID); err != nil {
+		return nil, err
+	}
+
+	onset, err := parseOnset(req.Onset)
+	if err != nil {
+		return nil, err
+	}
+
+	allergy := &models.AllergyIntolerance{
+		PatientID:          patientID,
+		Substance:          strings.TrimSpace(req.Substance),
+		SubstanceCode:      strings.TrimSpace(req.SubstanceCode),
+		CodeSystem:         strings.TrimSpace(req.CodeSystem),
+		Reaction:           req.Reaction,
+		Severity:           req.Severity,
+		Status:             req.Status,
+		VerificationStatus: req.VerificationStatus,
+		Onset:              onset,
+		Note:               req.Note,
+		RecordedByID:       recordedBy,
+	}
+	if allergy.Status == "" {
+		allergy.Status = models.AllergyActive
+	}
+	if allergy.VerificationStatus == "" {
+		allergy.VerificationStatus = models.AllergyUnverified
+	}
+	if err := s.setVerification(allergy, allergy.VerificationStatus, permissions, recordedBy); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(allergy); err != nil {
+		return nil, err
+	}
+	return s.repo.Get(patientID, allergy.ID)
+}
+
+// Update changes an allergy. Front desk staff may only correct unverified
+// entries and cannot verify them.
+func (s *Service) Update(patientID, id uint, req models.UpdateAllergyRequest, permissions []string, changedBy uint) (*models.AllergyIntolerance, error) {
+	allergy, err := s.getMutable(patientID, id, permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Substance != nil {
+		allergy.Substance = strings.TrimSpace(*req.Substance)
+	}
+	if req.SubstanceCode != nil {
+		allergy.SubstanceCode = strings.TrimSpace(*req.SubstanceCode)
+	}
+	if req.CodeSystem != nil {
+		allergy.CodeSystem = strings.TrimSpace(*req.CodeSystem)
+	}
+	if req.Reaction != nil {
+		allergy.Reaction = *req.Reaction
+	}
+	if req.Severity != nil {
+		allergy.Severity = *req.Severity
+	}
+	if req.Status != nil {
+		allergy.Status = *req.Status
+	}
+	if req.Onset != nil {
+		onset, err := parseOnset(*req.Onset)
+		if err != nil {
+			return nil, err
+		}
+		allergy.Onset = onset
+	}
+	if req.Note != nil {
+		allergy.Note = *req.Note
+	}
+	if req.VerificationStatus != nil && *req.VerificationStatus != allergy.VerificationStatus {
+		if err := s.setVerification(allergy, *req.VerificationStatus, permissions, changedBy); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.Update(allergy); err != nil {
+		return nil, err
+	}
+	return s.repo.Get(patientID, id)
+}
+
+// MarkEnteredInError retracts an allergy recorded by mistake. The entry is
+// kept for the record but no longer listed by default.
+func (s *Service) MarkEnteredInError(patientID, id uint, permissions []string, changedBy uint) (*models.AllergyIntolerance, error) {
+	allergy, err := s.getMutable(patientID, id, permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	allergy.VerificationStatus = models.AllergyEnteredInError
+	allergy.VerifiedByID = &changedBy
+	allergy.VerifiedAt = &now
+
+	if err := s.repo.Update(allergy); err != nil {
+		return nil, err
+	}
+	return s.repo.Get(patientID, id)
+}
+
+func (s *Service) getMutable(patientID, id uint, permissions []string) (*models.AllergyIntolerance, error) {
+	if _, err := s.patientService.GetActivePatient(patientID); err != nil {
+		return nil, err
+	}
+
+	allergy, err := s.repo.Get(patientID, id)
+	if err != nil {
+		return nil, err
+	}
+	if allergy.VerificationStatus == models.AllergyEnteredInError {
+		return nil, ErrEnteredInError
+	}
+	if allergy.VerificationStatus != models.AllergyUnverified && !clinical(permissions) {
+		return nil, ErrEditVerified
+	}
+	return allergy, nil
+}
+
+// setVerification moves allergy to status, recording who verified it.
+func (s *Service) setVerification(allergy *models.AllergyIntolerance, status string, permissions []string, by uint) error {
+	if status == models.AllergyUnverified {
+		allergy.VerificationStatus = status
+		allergy.VerifiedByID = nil
+		allergy.VerifiedAt = nil
+		return nil
+	}
+	if !clinical(permissions) {
+		return ErrVerifyForbidden
+	}
+
+	now := time.Now()
+	allergy.VerificationStatus = status
+	allergy.VerifiedByID = &by
+	allergy.VerifiedAt = &now
+	return nil
+}
+
+// clinical reports whether permissions include editing medical information.
+func clinical(permissions []string) bool {
+	for _, p := range permissions {
+		if p == models.PermissionMedicalWrite {
+			return true
+		}
+	}
+	return false
+}
+
+func parseOnset(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	onset, err := time.Parse("2006-01-02", value)
+	if err != nil || onset.After(time.Now()) {
+		return nil, ErrInvalidOnset
+	}
+	return &onset, nil
+}
@@ -0,0 +1,268 @@
+package appointment
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+	"hospital-management/internal/audit"
+	"hospital-management/internal/models"
+	"hospital-management/internal/patient"
+	"hospital-management/internal/schedule"
+	"hospital-management/internal/user"
+	"hospital-management/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type Handler struct {
+	service *Service
+	audit   *audit.Service
+}
+
+func NewHandler(service *Service, auditService *audit.Service) *Handler {
+	return &Handler{service: service, audit: auditService}
+}
+
+// appointmentDiffIgnored lists fields left out of audit diffs because they
+// change on every write or are loaded from other tables.
+var appointmentDiffIgnored = []string{"updated_at", "patient", "doctor", "appointment_type"}
+
+func (h *Handler) ListTypes(c *gin.Context) {
+	includeInactive := c.Query("include_inactive") == "true"
+
+	types, err := h.service.ListTypes(includeInactive)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get appointment types", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Appointment types retrieved successfully", types)
+}
+
+func (h *Handler) CreateType(c *gin.Context) {
+	var req models.CreateAppointmentTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	appointmentType, err := h.service.CreateType(req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create appointment type", err)
+		return
+	}
+
+	h.audit.Record(c, "appointment_type.create", "appointment_type", appointmentType.ID, appointmentType)
+	utils.SuccessResponse(c, "Appointment type created successfully", appointmentType)
+}
+
+func (h *Handler) UpdateType(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid appointment type ID", err)
+		return
+	}
+
+	var req models.UpdateAppointmentTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	appointmentType, err := h.service.UpdateType(uint(id), req)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to update appointment type", err)
+		return
+	}
+
+	h.audit.Record(c, "appointment_type.update", "appointment_type", appointmentType.ID, req)
+	utils.SuccessResponse(c, "Appointment type updated successfully", appointmentType)
+}
+
+func (h *Handler) BookAppointment(c *gin.Context) {
+	var req models.CreateAppointmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	appointment, err := h.service.Book(req, utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to book appointment", err)
+		return
+	}
+
+	changes, _ := utils.DiffFields(nil, appointment, appointmentDiffIgnored...)
+	h.audit.Log(c, audit.Entry{
+		Action:       "appointment.create",
+		ResourceType: "appointment",
+		ResourceID:   appointment.ID,
+		PatientID:    &appointment.PatientID,
+		Changes:      changes,
+	})
+
+	utils.SuccessResponse(c, "Appointment booked successfully", appointment)
+}
+
+func (h *Handler) GetAppointments(c *gin.Context) {
+	var filter models.AppointmentFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query", err)
+		return
+	}
+
+	appointments, meta, err := h.service.ListAppointments(filter)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get appointments", err)
+		return
+	}
+
+	h.logList(c, "appointment.list", appointments)
+	utils.PaginatedResponse(c, "Appointments retrieved successfully", appointments, meta)
+}
+
+func (h *Handler) GetAppointment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid appointment ID", err)
+		return
+	}
+
+	appointment, err := h.service.GetAppointment(uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Appointment not found", err)
+		return
+	}
+
+	h.audit.Log(c, audit.Entry{
+		Action:       "appointment.read",
+		ResourceType: "appointment",
+		ResourceID:   appointment.ID,
+		PatientID:    &appointment.PatientID,
+	})
+	utils.SuccessResponse(c, "Appointment retrieved successfully", appointment)
+}
+
+func (h *Handler) RescheduleAppointment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid appointment ID", err)
+		return
+	}
+
+	var req models.RescheduleAppointmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	before, err := h.service.GetAppointment(uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Appointment not found", err)
+		return
+	}
+
+	appointment, err := h.service.Reschedule(uint(id), req)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to reschedule appointment", err)
+		return
+	}
+
+	changes, _ := utils.DiffFields(before, appointment, appointmentDiffIgnored...)
+	h.audit.Log(c, audit.Entry{
+		Action:       "appointment.reschedule",
+		ResourceType: "appointment",
+		ResourceID:   appointment.ID,
+		PatientID:    &appointment.PatientID,
+		Changes:      changes,
+	})
+
+	utils.SuccessResponse(c, "Appointment rescheduled successfully", appointment)
+}
+
+func (h *Handler) CancelAppointment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid appointment ID", err)
+		return
+	}
+
+	var req models.CancelAppointmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	appointment, err := h.service.Cancel(uint(id), req.Reason, utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to cancel appointment", err)
+		return
+	}
+
+	h.audit.Log(c, audit.Entry{
+		Action:       "appointment.cancel",
+		ResourceType: "appointment",
+		ResourceID:   appointment.ID,
+		PatientID:    &appointment.PatientID,
+		Details:      gin.H{"reason": req.Reason},
+	})
+
+	utils.SuccessResponse(c, "Appointment cancelled successfully", appointment)
+}
+
+// GetDoctorAgenda returns the authenticated doctor's appointments for the
+// day given as ?date=YYYY-MM-DD, today by default.
+func (h *Handler) GetDoctorAgenda(c *gin.Context) {
+	day := time.Now().In(h.service.Location())
+	if date := c.Query("date"); date != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", date, h.service.Location())
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid date, expected YYYY-MM-DD", err)
+			return
+		}
+		day = parsed
+	}
+
+	appointments, err := h.service.DoctorAgenda(utils.CurrentUserID(c), day)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get agenda", err)
+		return
+	}
+
+	h.logList(c, "appointment.agenda", appointments)
+	utils.SuccessResponse(c, "Agenda retrieved successfully", gin.H{
+		"date":         day.Format("2006-01-02"),
+		"appointments": appointments,
+	})
+}
+
+// logList audits a listing of appointments with the patients it disclosed.
+func (h *Handler) logList(c *gin.Context, action string, appointments []models.Appointment) {
+	ids := make([]uint, 0, len(appointments))
+	patientIDs := make([]uint, 0, len(appointments))
+	for _, a := range appointments {
+		ids = append(ids, a.ID)
+		patientIDs = append(patientIDs, a.PatientID)
+	}
+	h.audit.Log(c, audit.Entry{
+		Action:       action,
+		ResourceType: "appointment",
+		Details:      gin.H{"appointment_ids": ids, "patient_ids": patientIDs},
+	})
+}
+
+// statusFor maps service errors to HTTP status codes.
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrDoubleBooked), errors.Is(err, ErrPatientDoubleBooked), errors.Is(err, ErrNotScheduled),
+		errors.Is(err, schedule.ErrDoctorUnavailable):
+		return http.StatusConflict
+	case errors.Is(err, user.ErrNotDoctor), errors.Is(err, patient.ErrPatientMerged),
+		errors.Is(err, ErrTypeInactive), errors.Is(err, ErrInPast):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
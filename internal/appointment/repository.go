@@ -0,0 +1,136 @@
+package appointment
+
+import (
+	"time"
+	"hospital-management/internal/models"
+	"hospital-management/pkg/utils"
+	"gorm.io/gorm"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// withDetails preloads what an agenda or booking view shows about an
+// appointment.
+func withDetails(db *gorm.DB) *gorm.DB {
+	return db.Preload("Patient", utils.WithDeleted).
+		Preload("Doctor", utils.WithDeleted).
+		Preload("AppointmentType")
+}
+
+func (r *Repository) ListTypes(includeInactive bool) ([]models.AppointmentType, error) {
+	var types []models.AppointmentType
+	query := r.db.Order("name")
+	if !includeInactive {
+		query = query.Where("is_active = ?", true)
+	}
+	err := query.Find(&types).Error
+	return types, err
+}
+
+func (r *Repository) GetType(id uint) (*models.AppointmentType, error) {
+	var appointmentType models.AppointmentType
+	err := r.db.First(&appointmentType, id).Error
+	return &appointmentType, err
+}
+
+func (r *Repository) CreateType(appointmentType *models.AppointmentType) error {
+	return r.db.Create(appointmentType).Error
+}
+
+// UpdateType writes only the given columns, so concurrent edits to other
+// fields of the type are kept.
+func (r *Repository) UpdateType(id uint, changes map[string]interface{}) error {
+	return r.db.Model(&models.AppointmentType{}).Where("id = ?", id).Updates(changes).Error
+}
+
+func (r *Repository) Create(appointment *models.Appointment) error {
+	return utils.TranslateConflict(r.db.Omit("Patient", "Doctor", "AppointmentType").Create(appointment).Error, conflictErrors)
+}
+
+// Update writes changes to the appointment as long as it is still
+// scheduled, so a request racing with a cancellation or check-in cannot undo
+// it. It returns ErrNotScheduled when the appointment has moved on.
+func (r *Repository) Update(id uint, changes map[string]interface{}) error {
+	result := r.db.Model(&models.Appointment{}).
+		Where("id = ? AND status = ?", id, models.AppointmentScheduled).
+		Updates(changes)
+	if err := utils.TranslateConflict(result.Error, conflictErrors); err != nil {
+		return err
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotScheduled
+	}
+	return nil
+}
+
+func (r *Repository) GetByID(id uint) (*models.Appointment, error) {
+	var appointment models.Appointment
+	err := withDetails(r.db).First(&appointment, id).Error
+	return &appointment, err
+}
+
+// List returns one page of appointments matching filter, earliest first.
+func (r *Repository) List(filter models.AppointmentFilter) ([]models.Appointment, *utils.Meta, error) {
+	page, pageSize := utils.NormalizePage(filter.Page, filter.PageSize)
+
+	query := r.db.Model(&models.Appointment{})
+	if filter.PatientID != 0 {
+		query = query.Where("patient_id = ?", filter.PatientID)
+	}
+	if filter.DoctorID != 0 {
+		query = query.Where("doctor_id = ?", filter.DoctorID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("ends_at > ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("starts_at < ?", filter.To)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var appointments []models.Appointment
+	err := withDetails(query).
+		Order("starts_at, id").
+		Limit(pageSize).
+		Offset((page - 1) * pageSize).
+		Find(&appointments).Error
+
+	meta := &utils.Meta{
+		Total:    total,
+		PageSize: pageSize,
+		Page:     page,
+		HasMore:  int64(page*pageSize) < total,
+	}
+	return appointments, meta, err
+}
+
+// ListForDoctor returns a doctor's appointments overlapping [from, to),
+// including cancelled ones so the agenda shows freed slots.
+func (r *Repository) ListForDoctor(doctorID uint, from, to time.Time) ([]models.Appointment, error) {
+	var appointments []models.Appointment
+	err := withDetails(r.db).
+		Where("doctor_id = ? AND starts_at < ? AND ends_at > ?", doctorID, to, from).
+		Order("starts_at, id").
+		Find(&appointments).Error
+	return appointments, err
+}
+
+// conflictErrors maps the exclusion constraints against double booking to
+// booking errors.
+var conflictErrors = map[string]error{
+	"appointments_doctor_no_overlap":  ErrDoubleBooked,
+	"appointments_patient_no_overlap": ErrPatientDoubleBooked,
+}
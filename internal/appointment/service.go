@@ -0,0 +1,219 @@
+package appointment
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"hospital-management/internal/config"
+	"hospital-management/internal/models"
+	"hospital-management/internal/patient"
+	"hospital-management/internal/schedule"
+	"hospital-management/internal/user"
+	"hospital-management/pkg/utils"
+)
+
+var (
+	ErrDoubleBooked        = errors.New("doctor already has an appointment at that time")
+	ErrPatientDoubleBooked = errors.New("patient already has an appointment at that time")
+	ErrTypeInactive        = errors.New("appointment type is not active")
+	ErrInPast              = errors.New("appointment must start in the future")
+	ErrNotScheduled        = errors.New("appointment is not scheduled")
+)
+
+type Service struct {
+	repo            *Repository
+	patientService  *patient.Service
+	userService     *user.Service
+	scheduleService *schedule.Service
+	location        *time.Location
+}
+
+func NewService(repo *Repository, patientService *patient.Service, userService *user.Service, scheduleService *schedule.Service, cfg *config.Config) *Service {
+	return &Service{
+		repo:            repo,
+		patientService:  patientService,
+		userService:     userService,
+		scheduleService: scheduleService,
+		location:        cfg.Location,
+	}
+}
+
+func (s *Service) ListTypes(includeInactive bool) ([]models.AppointmentType, error) {
+	return s.repo.ListTypes(includeInactive)
+}
+
+func (s *Service) CreateType(req models.CreateAppointmentTypeRequest) (*models.AppointmentType, error) {
+	appointmentType := &models.AppointmentType{
+		Name:            strings.TrimSpace(req.Name),
+		Description:     req.Description,
+		DurationMinutes: req.DurationMinutes,
+		IsActive:        true,
+	}
+	if err := s.repo.CreateType(appointmentType); err != nil {
+		return nil, err
+	}
+	return appointmentType, nil
+}
+
+// UpdateType changes an appointment type. A new duration only applies to
+// appointments booked afterwards.
+func (s *Service) UpdateType(id uint, req models.UpdateAppointmentTypeRequest) (*models.AppointmentType, error) {
+	appointmentType, err := s.repo.GetType(id)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]interface{}{}
+	if req.Description != nil {
+		changes["description"] = *req.Description
+	}
+	if req.DurationMinutes != nil {
+		changes["duration_minutes"] = *req.DurationMinutes
+	}
+	if req.IsActive != nil {
+		changes["is_active"] = *req.IsActive
+	}
+	if len(changes) == 0 {
+		return appointmentType, nil
+	}
+
+	if err := s.repo.UpdateType(id, changes); err != nil {
+		return nil, err
+	}
+	return s.repo.GetType(id)
+}
+
+// Book schedules a new appointment. Its length comes from the appointment
+// type and it must fit the doctor's schedule; overlapping bookings are
+// rejected by the database.
+func (s *Service) Book(req models.CreateAppointmentRequest, bookedBy uint) (*models.Appointment, error) {
+	if !req.StartsAt.After(time.Now()) {
+		return nil, ErrInPast
+	}
+
+	appointmentType, err := s.repo.GetType(req.AppointmentTypeID)
+	if err != nil {
+		return nil, err
+	}
+	if !appointmentType.IsActive {
+		return nil, ErrTypeInactive
+	}
+
+	if _, err := s.patientService.GetActivePatient(req.PatientID); err != nil {
+		return nil, err
+	}
+	if err := s.checkDoctor(req.DoctorID); err != nil {
+		return nil, err
+	}
+
+	startsAt := req.StartsAt.UTC()
+	endsAt := startsAt.Add(time.Duration(appointmentType.DurationMinutes) * time.Minute)
+	if err := s.scheduleService.CheckAvailable(req.DoctorID, startsAt, endsAt); err != nil {
+		return nil, err
+	}
+
+	appointment := &models.Appointment{
+		PatientID:         req.PatientID,
+		DoctorID:          req.DoctorID,
+		AppointmentTypeID: appointmentType.ID,
+		StartsAt:          startsAt,
+		EndsAt:            endsAt,
+		Status:            models.AppointmentScheduled,
+		Reason:            req.Reason,
+		BookedByID:        bookedBy,
+	}
+	if err := s.repo.Create(appointment); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(appointment.ID)
+}
+
+// Reschedule moves a scheduled appointment to a new start time and,
+// optionally, to another doctor. The original duration is kept.
+func (s *Service) Reschedule(id uint, req models.RescheduleAppointmentRequest) (*models.Appointment, error) {
+	appointment, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if appointment.Status != models.AppointmentScheduled {
+		return nil, ErrNotScheduled
+	}
+	if !req.StartsAt.After(time.Now()) {
+		return nil, ErrInPast
+	}
+
+	doctorID := appointment.DoctorID
+	if req.DoctorID != 0 && req.DoctorID != doctorID {
+		if err := s.checkDoctor(req.DoctorID); err != nil {
+			return nil, err
+		}
+		doctorID = req.DoctorID
+	}
+
+	startsAt := req.StartsAt.UTC()
+	endsAt := startsAt.Add(appointment.EndsAt.Sub(appointment.StartsAt))
+	if err := s.scheduleService.CheckAvailable(doctorID, startsAt, endsAt); err != nil {
+		return nil, err
+	}
+
+	changes := map[string]interface{}{
+		"doctor_id": doctorID,
+		"starts_at": startsAt,
+		"ends_at":   endsAt,
+	}
+	if err := s.repo.Update(id, changes); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(id)
+}
+
+// Cancel cancels a scheduled appointment, freeing its slot.
+func (s *Service) Cancel(id uint, reason string, cancelledBy uint) (*models.Appointment, error) {
+	appointment, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if appointment.Status != models.AppointmentScheduled {
+		return nil, ErrNotScheduled
+	}
+
+	changes := map[string]interface{}{
+		"status":          models.AppointmentCancelled,
+		"cancel_reason":   reason,
+		"cancelled_at":    time.Now(),
+		"cancelled_by_id": cancelledBy,
+	}
+	if err := s.repo.Update(id, changes); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(id)
+}
+
+func (s *Service) GetAppointment(id uint) (*models.Appointment, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *Service) ListAppointments(filter models.AppointmentFilter) ([]models.Appointment, *utils.Meta, error) {
+	return s.repo.List(filter)
+}
+
+// DoctorAgenda returns a doctor's appointments on the given calendar day in
+// the clinic's time zone.
+func (s *Service) DoctorAgenda(doctorID uint, day time.Time) ([]models.Appointment, error) {
+	from := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, s.location)
+	to := from.AddDate(0, 0, 1)
+	return s.repo.ListForDoctor(doctorID, from, to)
+}
+
+// Location returns the clinic's time zone.
+func (s *Service) Location() *time.Location {
+	return s.location
+}
+
+func (s *Service) checkDoctor(id uint) error {
+	_, err := s.userService.GetActiveDoctor(id)
+	return err
+}
@@ -0,0 +1,69 @@
+package audit
+
+import (
+	"net/http"
+	"hospital-management/internal/models"
+	"hospital-management/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// @Summary Query audit log
+// @Description List audit entries filtered by actor, patient, action, resource type and time range
+// @Tags audit
+// @Security Bearer
+// @Produce json
+// @Param actor_id query int false "Actor user ID"
+// @Param patient_id query int false "Patient ID"
+// @Param action query string false "Action, e.g. patient.read"
+// @Param resource_type query string false "Resource type"
+// @Param from query string false "Start time (RFC3339)"
+// @Param to query string false "End time (RFC3339)"
+// @Param limit query int false "Page size (max 500)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Router /audit [get]
+func (h *Handler) List(c *gin.Context) {
+	var filter models.AuditFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid filter", err)
+		return
+	}
+
+	entries, total, err := h.service.List(filter)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get audit log", err)
+		return
+	}
+
+	h.service.Record(c, "audit.query", "audit", 0, filter)
+	utils.SuccessResponse(c, "Audit log retrieved successfully", gin.H{
+		"entries": entries,
+		"total":   total,
+	})
+}
+
+// @Summary Verify audit log
+// @Description Recompute the audit hash chain and report the first tampered entry, if any
+// @Tags audit
+// @Security Bearer
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /audit/verify [get]
+func (h *Handler) Verify(c *gin.Context) {
+	result, err := h.service.Verify()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to verify audit log", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Audit log verified", result)
+}
@@ -0,0 +1,87 @@
+package audit
+
+import (
+	"hospital-management/internal/models"
+	"gorm.io/gorm"
+)
+
+// chainLockKey identifies the advisory lock that serialises appends so that
+// two entries can never claim the same predecessor.
+const chainLockKey = 7426001
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// Append links entry to the current head of the chain and stores it.
+func (r *Repository) Append(entry *models.AuditLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", chainLockKey).Error; err != nil {
+			return err
+		}
+
+		var head models.AuditLog
+		result := tx.Select("hash").Order("id DESC").Limit(1).Find(&head)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		entry.PrevHash = head.Hash
+		entry.Hash = entry.ComputeHash()
+		return tx.Create(entry).Error
+	})
+}
+
+func (r *Repository) List(filter models.AuditFilter) ([]models.AuditLog, int64, error) {
+	var entries []models.AuditLog
+	var total int64
+
+	query := r.db.Model(&models.AuditLog{})
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.PatientID != 0 {
+		query = query.Where("patient_id = ?", filter.PatientID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.ResourceType != "" {
+		query = query.Where("resource_type = ?", filter.ResourceType)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	limit := filter.Limit
+	if limit == 0 {
+		limit = 100
+	}
+
+	err := query.Order("id DESC").Limit(limit).Offset(filter.Offset).Find(&entries).Error
+	return entries, total, err
+}
+
+// Walk calls fn for every entry in chain order, loading them in batches.
+func (r *Repository) Walk(fn func(entry *models.AuditLog) error) error {
+	var batch []models.AuditLog
+	return r.db.Order("id").FindInBatches(&batch, 1000, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			if err := fn(&batch[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
@@ -0,0 +1,148 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"hospital-management/internal/models"
+	"hospital-management/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+var errStopWalk = errors.New("stop")
+
+type Service struct {
+	repo *Repository
+}
+
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Entry describes an audited action. PatientID is set whenever the action
+// touches protected health information so that access to a patient's record
+// can be reported on.
+type Entry struct {
+	Action       string
+	ResourceType string
+	ResourceID   uint
+	PatientID    *uint
+	Changes      map[string]utils.FieldChange
+	Details      interface{}
+}
+
+// VerifyResult reports the outcome of walking the audit chain.
+type VerifyResult struct {
+	Valid    bool   `json:"valid"`
+	Checked  int    `json:"checked"`
+	BrokenAt uint   `json:"broken_at,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Record stores an audit entry for the authenticated user of the request.
+// Failures are logged rather than returned so that auditing never masks the
+// outcome of the action being audited.
+func (s *Service) Record(c *gin.Context, action, resourceType string, resourceID uint, details interface{}) {
+	s.Log(c, Entry{
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Details:      details,
+	})
+}
+
+// RecordPatient stores an audit entry for an action on a patient record.
+func (s *Service) RecordPatient(c *gin.Context, action string, patientID uint, changes map[string]utils.FieldChange) {
+	s.Log(c, Entry{
+		Action:       action,
+		ResourceType: "patient",
+		ResourceID:   patientID,
+		PatientID:    &patientID,
+		Changes:      changes,
+	})
+}
+
+func (s *Service) Log(c *gin.Context, e Entry) {
+	entry := &models.AuditLog{ClientIP: c.ClientIP()}
+
+	if userInterface, exists := c.Get("user"); exists {
+		user := userInterface.(map[string]interface{})
+		entry.ActorID = user["id"].(uint)
+		entry.ActorRole = user["role"].(string)
+	}
+
+	s.append(entry, e)
+}
+
+// LogSystem records an action taken by the system itself, such as a
+// scheduled job, rather than by a user.
+func (s *Service) LogSystem(e Entry) {
+	s.append(&models.AuditLog{ActorRole: "system"}, e)
+}
+
+func (s *Service) append(entry *models.AuditLog, e Entry) {
+	entry.Action = e.Action
+	entry.ResourceType = e.ResourceType
+	entry.ResourceID = e.ResourceID
+	entry.PatientID = e.PatientID
+	entry.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+
+	if len(e.Changes) > 0 {
+		entry.Changes = encode(e.Action, e.Changes)
+	}
+	if e.Details != nil {
+		entry.Details = encode(e.Action, e.Details)
+	}
+
+	if err := s.repo.Append(entry); err != nil {
+		log.Printf("audit: failed to record %s on %s %d: %v", e.Action, e.ResourceType, e.ResourceID, err)
+	}
+}
+
+func (s *Service) List(filter models.AuditFilter) ([]models.AuditLog, int64, error) {
+	return s.repo.List(filter)
+}
+
+// Verify walks the whole chain and reports the first entry whose hash or
+// link to its predecessor does not match.
+func (s *Service) Verify() (*VerifyResult, error) {
+	result := &VerifyResult{Valid: true}
+	prevHash := ""
+
+	err := s.repo.Walk(func(entry *models.AuditLog) error {
+		result.Checked++
+
+		if entry.PrevHash != prevHash {
+			result.Valid = false
+			result.BrokenAt = entry.ID
+			result.Reason = "previous hash does not match the preceding entry"
+			return errStopWalk
+		}
+
+		if entry.Hash != entry.ComputeHash() {
+			result.Valid = false
+			result.BrokenAt = entry.ID
+			result.Reason = "entry content does not match its hash"
+			return errStopWalk
+		}
+
+		prevHash = entry.Hash
+		return nil
+	})
+	if err != nil && !errors.Is(err, errStopWalk) {
+		return nil, fmt.Errorf("walk audit chain: %w", err)
+	}
+
+	return result, nil
+}
+
+func encode(action string, v interface{}) string {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("audit: failed to encode %s: %v", action, err)
+		return ""
+	}
+	return string(encoded)
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"net/http"
+	"time"
 	"hospital-management/internal/models"
 	"hospital-management/pkg/utils"
 	"github.com/gin-gonic/gin"
@@ -31,20 +32,117 @@ func (h *Handler) Login(c *gin.Context) {
 		return
 	}
 
-	token, user, err := h.service.Login(req)
+	tokens, user, err := h.service.Login(req)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusUnauthorized, "Login failed", err)
 		return
 	}
 
 	utils.SuccessResponse(c, "Login successful", gin.H{
-		"token": token,
-		"user":  user,
+		"token":              tokens.AccessToken,
+		"expires_at":         tokens.AccessExpiresAt,
+		"refresh_token":      tokens.RefreshToken,
+		"refresh_expires_at": tokens.RefreshExpiresAt,
+		"user":               user,
 	})
 }
 
+// @Summary Refresh access token
+// @Description Exchange a refresh token for a new access and refresh token pair
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.RefreshRequest true "Refresh token"
+// @Success 200 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /token/refresh [post]
+func (h *Handler) Refresh(c *gin.Context) {
+	var req models.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	tokens, err := h.service.Refresh(req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Token refresh failed", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Token refreshed successfully", tokens)
+}
+
+// @Summary User logout
+// @Description Revoke the current session and its refresh tokens
+// @Tags auth
+// @Security Bearer
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /logout [post]
+func (h *Handler) Logout(c *gin.Context) {
+	user := c.MustGet("user").(map[string]interface{})
+	sessionID := user["session_id"].(string)
+
+	if err := h.service.Logout(sessionID); err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Logout failed", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Logout successful", nil)
+}
+
+// @Summary Issue stream token
+// @Description Issue a token for opening an event stream as ?stream_token=, for clients such as the browser EventSource that cannot send an Authorization header. It opens a single stream and must be used within a minute; the stream closes when the access token it was issued from expires or the session ends.
+// @Tags auth
+// @Security Bearer
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /token/stream [post]
+func (h *Handler) StreamToken(c *gin.Context) {
+	user := c.MustGet("user").(map[string]interface{})
+
+	token, err := h.service.IssueStreamToken(utils.CurrentUserID(c), user["role"].(string),
+		user["session_id"].(string), user["expires_at"].(time.Time))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to issue stream token", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Stream token issued successfully", gin.H{"stream_token": token})
+}
+
+// @Summary Change password
+// @Description Change the current user's password and sign out their other sessions
+// @Tags user
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param request body models.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Router /profile/password [put]
+func (h *Handler) ChangePassword(c *gin.Context) {
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	user := c.MustGet("user").(map[string]interface{})
+	sessionID := user["session_id"].(string)
+
+	if err := h.service.ChangePassword(utils.CurrentUserID(c), sessionID, req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to change password", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Password changed successfully", nil)
+}
+
 // @Summary User registration
-// @Description Register a new user
+// @Description Register a new user with an invitation token issued by an administrator
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -1,45 +1,158 @@
 package auth
 
 import (
+	"context"
 	"net/http"
 	"strings"
+	"time"
+	"hospital-management/internal/models"
 	"hospital-management/pkg/utils"
 	"github.com/gin-gonic/gin"
 )
 
-func RequireAuth(jwtSecret string) gin.HandlerFunc {
+// streamCheckInterval is how often an open event stream checks that its
+// session is still valid.
+const streamCheckInterval = 30 * time.Second
+
+func RequireAuth(service *Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Authorization header required", nil)
-			c.Abort()
+		tokenString, ok := bearerToken(c)
+		if !ok {
 			return
 		}
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenString == authHeader {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid authorization format", nil)
+		claims, user, err := service.Authenticate(tokenString)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid token", err)
 			c.Abort()
 			return
 		}
 
-		claims, err := utils.ValidateToken(tokenString, jwtSecret)
+		setUser(c, claims, user)
+		c.Next()
+	}
+}
+
+// RequireStreamAuth authenticates an event stream with either a bearer
+// token or a ?stream_token= issued by POST /token/stream. The stream is
+// cancelled when the token expires, and checked every streamCheckInterval
+// so it also ends when the session is revoked or the user deactivated.
+func RequireStreamAuth(service *Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authenticate := service.Authenticate
+		tokenString := c.Query("stream_token")
+		if tokenString != "" {
+			authenticate = service.AuthenticateStream
+		} else {
+			var ok bool
+			if tokenString, ok = bearerToken(c); !ok {
+				return
+			}
+		}
+
+		claims, user, err := authenticate(tokenString)
 		if err != nil {
 			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid token", err)
 			c.Abort()
 			return
 		}
+		setUser(c, claims, user)
+
+		ctx, cancel := context.WithDeadline(c.Request.Context(), claims.ExpiresAt.Time)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		go watchSession(ctx, cancel, service, claims)
+
+		c.Next()
+	}
+}
+
+// watchSession cancels ctx once the session behind claims is no longer
+// valid. Streams select on the request context and close when it is done.
+func watchSession(ctx context.Context, cancel context.CancelFunc, service *Service, claims *utils.Claims) {
+	ticker := time.NewTicker(streamCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := service.CheckSession(claims.SessionID, claims.UserID); err != nil {
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// bearerToken returns the token from the Authorization header. When there
+// is none it responds with 401 and reports false.
+func bearerToken(c *gin.Context) (string, bool) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Authorization header required", nil)
+		c.Abort()
+		return "", false
+	}
 
-		c.Set("user", map[string]interface{}{
-			"id":   claims.UserID,
-			"role": claims.Role,
-		})
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	if tokenString == authHeader {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid authorization format", nil)
+		c.Abort()
+		return "", false
+	}
+	return tokenString, true
+}
+
+func setUser(c *gin.Context, claims *utils.Claims, user *models.User) {
+	c.Set("user", map[string]interface{}{
+		"id":                   user.ID,
+		"role":                 user.Role,
+		"roles":                user.RoleNames(),
+		"permissions":          user.PermissionNames(),
+		"session_id":           claims.SessionID,
+		"expires_at":           claims.ExpiresAt.Time,
+		"must_change_password": user.MustChangePassword,
+	})
+}
+
+// RequirePasswordChanged blocks users whose password was reset by an
+// administrator until they have chosen a new one.
+func RequirePasswordChanged() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := c.MustGet("user").(map[string]interface{})
+		if user["must_change_password"].(bool) {
+			utils.ErrorResponse(c, http.StatusForbidden, "Password change required", nil)
+			c.Abort()
+			return
+		}
 
 		c.Next()
 	}
 }
 
+// RequireRole allows the request when the user holds the given role.
+// Prefer RequirePermission so that new roles can be granted access without
+// code changes.
 func RequireRole(role string) gin.HandlerFunc {
+	return requireMembership("roles", role)
+}
+
+// RequirePermission allows the request when any of the user's roles grants
+// the given permission.
+func RequirePermission(permission string) gin.HandlerFunc {
+	return requireMembership("permissions", permission)
+}
+
+// RequireAnyPermission allows the request when the user holds at least one
+// of the given permissions. Handlers behind it are expected to check finer
+// grained access themselves.
+func RequireAnyPermission(permissions ...string) gin.HandlerFunc {
+	return requireMembership("permissions", permissions...)
+}
+
+func requireMembership(key string, values ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userInterface, exists := c.Get("user")
 		if !exists {
@@ -49,14 +162,18 @@ func RequireRole(role string) gin.HandlerFunc {
 		}
 
 		user := userInterface.(map[string]interface{})
-		userRole := user["role"].(string)
+		granted := user[key].([]string)
 
-		if userRole != role {
-			utils.ErrorResponse(c, http.StatusForbidden, "Insufficient permissions", nil)
-			c.Abort()
-			return
+		for _, g := range granted {
+			for _, value := range values {
+				if g == value {
+					c.Next()
+					return
+				}
+			}
 		}
 
-		c.Next()
+		utils.ErrorResponse(c, http.StatusForbidden, "Insufficient permissions", nil)
+		c.Abort()
 	}
 }
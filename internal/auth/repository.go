@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"errors"
+	"time"
+	"hospital-management/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) CreateRefreshToken(token *models.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *Repository) GetRefreshTokenByHash(hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := r.db.Where("token_hash = ?", hash).First(&token).Error
+	return &token, err
+}
+
+// RotateRefreshToken revokes old and stores next as its replacement. It
+// reports false when old had already been revoked by a concurrent request.
+func (r *Repository) RotateRefreshToken(old *models.RefreshToken, next *models.RefreshToken) (bool, error) {
+	rotated := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", old.ID).
+			Updates(map[string]interface{}{
+				"revoked_at":     time.Now(),
+				"replaced_by_id": next.ID,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			// Roll back the new token; the caller treats this as reuse.
+			return gorm.ErrRecordNotFound
+		}
+
+		rotated = true
+		return nil
+	})
+	if err == gorm.ErrRecordNotFound {
+		return false, nil
+	}
+	return rotated, err
+}
+
+func (r *Repository) RevokeFamily(familyID string) error {
+	return r.db.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *Repository) RevokeAllForUser(userID uint) error {
+	return r.db.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeOtherFamilies revokes every session of the user except the token
+// family keepFamilyID.
+func (r *Repository) RevokeOtherFamilies(userID uint, keepFamilyID string) error {
+	return r.db.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND family_id <> ? AND revoked_at IS NULL", userID, keepFamilyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// IsSessionActive reports whether the token family still holds an unrevoked,
+// unexpired refresh token.
+func (r *Repository) IsSessionActive(familyID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL AND expires_at > ?", familyID, time.Now()).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// UseStreamToken records that the stream token with the given ID was used.
+// It reports false when the token had been used before. Records older than
+// expiredBefore, whose tokens can no longer be used anyway, are dropped.
+func (r *Repository) UseStreamToken(id string, expiredBefore time.Time) (bool, error) {
+	if err := r.db.Where("used_at < ?", expiredBefore).Delete(&models.UsedStreamToken{}).Error; err != nil {
+		return false, err
+	}
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.UsedStreamToken{ID: id, UsedAt: time.Now()})
+	return result.RowsAffected == 1, result.Error
+}
+
+var errInvitationUnavailable = errors.New("invitation unavailable")
+
+func (r *Repository) CreateInvitation(invitation *models.Invitation) error {
+	return r.db.Create(invitation).Error
+}
+
+func (r *Repository) GetInvitationByHash(hash string) (*models.Invitation, error) {
+	var invitation models.Invitation
+	err := r.db.Where("token_hash = ?", hash).First(&invitation).Error
+	return &invitation, err
+}
+
+func (r *Repository) GetInvitationByID(id uint) (*models.Invitation, error) {
+	var invitation models.Invitation
+	err := r.db.First(&invitation, id).Error
+	return &invitation, err
+}
+
+// ListInvitations returns invitations newest first. With pendingOnly set,
+// used, revoked and expired invitations are left out.
+func (r *Repository) ListInvitations(pendingOnly bool) ([]models.Invitation, error) {
+	var invitations []models.Invitation
+	query := r.db.Order("created_at DESC")
+	if pendingOnly {
+		query = query.Where("used_at IS NULL AND revoked_at IS NULL AND expires_at > ?", time.Now())
+	}
+	err := query.Find(&invitations).Error
+	return invitations, err
+}
+
+func (r *Repository) RevokeInvitation(id uint) error {
+	result := r.db.Model(&models.Invitation{}).
+		Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errInvitationUnavailable
+	}
+	return nil
+}
+
+// RedeemInvitation creates user and marks the invitation used in a single
+// transaction, so an invitation can never be redeemed twice.
+func (r *Repository) RedeemInvitation(invitation *models.Invitation, user *models.User) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// is_active defaults to true, so gorm leaves a false value out of
+		// the insert and reads the default back; pending accounts have to be
+		// deactivated explicitly.
+		active := user.IsActive
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		if !active {
+			user.IsActive = false
+			if err := tx.Model(user).UpdateColumn("is_active", false).Error; err != nil {
+				return err
+			}
+		}
+
+		result := tx.Model(&models.Invitation{}).
+			Where("id = ? AND used_at IS NULL AND revoked_at IS NULL AND expires_at > ?", invitation.ID, time.Now()).
+			Updates(map[string]interface{}{
+				"used_at":    time.Now(),
+				"used_by_id": user.ID,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errInvitationUnavailable
+		}
+		return nil
+	})
+}
@@ -2,61 +2,377 @@ package auth
 
 import (
 	"errors"
+	"strings"
+	"time"
+	"hospital-management/internal/config"
 	"hospital-management/internal/models"
 	"hospital-management/internal/user"
 	"hospital-management/pkg/utils"
 )
 
+// StreamScope is the scope of tokens that can only open event streams.
+const StreamScope = "stream"
+
+// streamTokenWindow is how long after it was issued a stream token can be
+// used to open a stream.
+const streamTokenWindow = time.Minute
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+	ErrSessionRevoked      = errors.New("session has been revoked")
+	ErrAccountInactive     = errors.New("account is deactivated")
+	ErrAccountPending      = errors.New("account is pending approval")
+	ErrInvalidInvitation   = errors.New("invitation is invalid, expired or already used")
+	ErrInvalidScope        = errors.New("token cannot be used for this request")
+	ErrStreamTokenUsed     = errors.New("stream token has already been used")
+)
+
 type Service struct {
-	userService *user.Service
-	jwtSecret   string
+	repo            *Repository
+	userService     *user.Service
+	jwtSecret       string
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+	invitationTTL   time.Duration
+	requireApproval bool
 }
 
-func NewService(userService *user.Service, jwtSecret string) *Service {
+type TokenPair struct {
+	AccessToken      string    `json:"token"`
+	AccessExpiresAt  time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+func NewService(repo *Repository, userService *user.Service, cfg *config.Config) *Service {
 	return &Service{
-		userService: userService,
-		jwtSecret:   jwtSecret,
+		repo:            repo,
+		userService:     userService,
+		jwtSecret:       cfg.JWTSecret,
+		accessTokenTTL:  cfg.AccessTokenTTL,
+		refreshTokenTTL: cfg.RefreshTokenTTL,
+		invitationTTL:   cfg.InvitationTTL,
+		requireApproval: cfg.RegistrationRequiresApproval,
 	}
 }
 
-func (s *Service) Login(req models.LoginRequest) (string, *models.User, error) {
+func (s *Service) Login(req models.LoginRequest) (*TokenPair, *models.User, error) {
 	user, err := s.userService.GetByUsername(req.Username)
 	if err != nil {
-		return "", nil, errors.New("invalid credentials")
+		return nil, nil, errors.New("invalid credentials")
 	}
 
 	if !utils.CheckPasswordHash(req.Password, user.Password) {
-		return "", nil, errors.New("invalid credentials")
+		return nil, nil, errors.New("invalid credentials")
+	}
+
+	if user.PendingApproval {
+		return nil, nil, ErrAccountPending
+	}
+
+	if !user.IsActive {
+		return nil, nil, ErrAccountInactive
+	}
+
+	familyID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refresh, refreshToken, err := s.newRefreshToken(user.ID, familyID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.repo.CreateRefreshToken(refresh); err != nil {
+		return nil, nil, err
+	}
+
+	pair, err := s.issue(user, refresh, refreshToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pair, user, nil
+}
+
+// Refresh exchanges a refresh token for a new token pair. Each refresh token
+// is single-use: presenting one that was already rotated revokes the whole
+// session, since either the client or an attacker is replaying a stolen copy.
+func (s *Service) Refresh(req models.RefreshRequest) (*TokenPair, error) {
+	current, err := s.repo.GetRefreshTokenByHash(utils.HashToken(req.RefreshToken))
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if current.ReplacedByID != nil {
+		if err := s.repo.RevokeFamily(current.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	if current.RevokedAt != nil || time.Now().After(current.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.userService.GetByID(current.UserID)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if !user.IsActive {
+		if err := s.repo.RevokeFamily(current.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrAccountInactive
+	}
+
+	next, refreshToken, err := s.newRefreshToken(user.ID, current.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	rotated, err := s.repo.RotateRefreshToken(current, next)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		if err := s.repo.RevokeFamily(current.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	return s.issue(user, next, refreshToken)
+}
+
+func (s *Service) Logout(sessionID string) error {
+	return s.repo.RevokeFamily(sessionID)
+}
+
+// RevokeUserSessions ends every session of the given user, e.g. after a
+// password change or deactivation.
+func (s *Service) RevokeUserSessions(userID uint) error {
+	return s.repo.RevokeAllForUser(userID)
+}
+
+// ChangePassword changes the user's password and ends their other sessions,
+// so that a session opened with the old password does not outlive it. The
+// session the change was made from stays signed in.
+func (s *Service) ChangePassword(userID uint, sessionID string, req models.ChangePasswordRequest) error {
+	if err := s.userService.ChangePassword(userID, req); err != nil {
+		return err
+	}
+	return s.repo.RevokeOtherFamilies(userID, sessionID)
+}
+
+// Authenticate validates an access token and checks that its session has
+// not been revoked and that the user is still active. The returned user has
+// its roles and permissions loaded.
+func (s *Service) Authenticate(tokenString string) (*utils.Claims, *models.User, error) {
+	claims, err := utils.ValidateToken(tokenString, s.jwtSecret)
+	if err != nil {
+		return nil, nil, err
+	}
+	if claims.Scope != "" {
+		return nil, nil, ErrInvalidScope
+	}
+	return s.authorize(claims)
+}
+
+// AuthenticateStream is Authenticate for stream tokens. A stream token
+// opens a single stream, within a minute of being issued.
+func (s *Service) AuthenticateStream(tokenString string) (*utils.Claims, *models.User, error) {
+	claims, err := utils.ValidateToken(tokenString, s.jwtSecret)
+	if err != nil {
+		return nil, nil, err
+	}
+	if claims.Scope != StreamScope || claims.ID == "" || claims.IssuedAt == nil ||
+		time.Since(claims.IssuedAt.Time) > streamTokenWindow {
+		return nil, nil, ErrInvalidScope
 	}
+	claims, user, err := s.authorize(claims)
+	if err != nil {
+		return nil, nil, err
+	}
+	first, err := s.repo.UseStreamToken(claims.ID, time.Now().Add(-streamTokenWindow))
+	if err != nil {
+		return nil, nil, err
+	}
+	if !first {
+		return nil, nil, ErrStreamTokenUsed
+	}
+	return claims, user, nil
+}
+
+// IssueStreamToken issues a token that opens event streams for clients that
+// cannot send an Authorization header, such as the browser EventSource. It
+// expires with the access token it was issued from.
+func (s *Service) IssueStreamToken(userID uint, role, sessionID string, expiresAt time.Time) (string, error) {
+	return utils.GenerateScopedToken(userID, role, sessionID, StreamScope, s.jwtSecret, expiresAt)
+}
 
+// CheckSession returns ErrSessionRevoked once the session has been revoked
+// or has expired, and ErrAccountInactive once the user is deactivated.
+func (s *Service) CheckSession(sessionID string, userID uint) error {
+	active, err := s.repo.IsSessionActive(sessionID)
+	if err != nil {
+		return err
+	}
+	if !active {
+		return ErrSessionRevoked
+	}
+
+	user, err := s.userService.GetByID(userID)
+	if err != nil {
+		return err
+	}
 	if !user.IsActive {
-		return "", nil, errors.New("account is deactivated")
+		return ErrAccountInactive
 	}
+	return nil
+}
 
-	token, err := utils.GenerateToken(user.ID, user.Role, s.jwtSecret)
+func (s *Service) authorize(claims *utils.Claims) (*utils.Claims, *models.User, error) {
+	active, err := s.repo.IsSessionActive(claims.SessionID)
 	if err != nil {
-		return "", nil, err
+		return nil, nil, err
+	}
+	if !active {
+		return nil, nil, ErrSessionRevoked
 	}
 
-	return token, user, nil
+	user, err := s.userService.GetByIDWithPermissions(claims.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !user.IsActive {
+		return nil, nil, ErrAccountInactive
+	}
+
+	return claims, user, nil
 }
 
+// Register creates an account from a single-use invitation. The role comes
+// from the invitation and the email must match the one it was issued for.
+// When approval is required the account stays inactive until an
+// administrator approves it.
 func (s *Service) Register(req models.RegisterRequest) (*models.User, error) {
+	invitation, err := s.repo.GetInvitationByHash(utils.HashToken(req.InviteToken))
+	if err != nil {
+		return nil, ErrInvalidInvitation
+	}
+
+	if invitation.UsedAt != nil || invitation.RevokedAt != nil || time.Now().After(invitation.ExpiresAt) {
+		return nil, ErrInvalidInvitation
+	}
+
+	if !strings.EqualFold(invitation.Email, req.Email) {
+		return nil, errors.New("email does not match the invitation")
+	}
+
+	role, err := s.userService.GetRoleByName(invitation.Role)
+	if err != nil {
+		return nil, ErrInvalidInvitation
+	}
+
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
 		return nil, err
 	}
 
 	user := &models.User{
-		Username:  req.Username,
-		Email:     req.Email,
-		Password:  hashedPassword,
-		Role:      req.Role,
-		FirstName: req.FirstName,
-		LastName:  req.LastName,
-		Phone:     req.Phone,
-		IsActive:  true,
+		Username:        req.Username,
+		Email:           req.Email,
+		Password:        hashedPassword,
+		Role:            role.Name,
+		FirstName:       req.FirstName,
+		LastName:        req.LastName,
+		Phone:           req.Phone,
+		IsActive:        !s.requireApproval,
+		PendingApproval: s.requireApproval,
+		Roles:           []models.Role{*role},
+	}
+
+	if err := s.repo.RedeemInvitation(invitation, user); err != nil {
+		if err == errInvitationUnavailable {
+			return nil, ErrInvalidInvitation
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// CreateInvitation issues an invitation for the given email and role. The
+// returned token is only available here; just its hash is stored.
+func (s *Service) CreateInvitation(req models.CreateInvitationRequest, createdBy uint) (*models.Invitation, string, error) {
+	if _, err := s.userService.GetRoleByName(req.Role); err != nil {
+		return nil, "", user.ErrUnknownRole
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+
+	ttl := s.invitationTTL
+	if req.ExpiresInHours > 0 {
+		ttl = time.Duration(req.ExpiresInHours) * time.Hour
+	}
+
+	invitation := &models.Invitation{
+		TokenHash:   utils.HashToken(token),
+		Email:       req.Email,
+		Role:        req.Role,
+		ExpiresAt:   time.Now().Add(ttl),
+		CreatedByID: createdBy,
+	}
+	if err := s.repo.CreateInvitation(invitation); err != nil {
+		return nil, "", err
+	}
+
+	return invitation, token, nil
+}
+
+func (s *Service) ListInvitations(pendingOnly bool) ([]models.Invitation, error) {
+	return s.repo.ListInvitations(pendingOnly)
+}
+
+func (s *Service) RevokeInvitation(id uint) error {
+	if err := s.repo.RevokeInvitation(id); err != nil {
+		if err == errInvitationUnavailable {
+			return ErrInvalidInvitation
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *Service) newRefreshToken(userID uint, familyID string) (*models.RefreshToken, string, error) {
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &models.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(s.refreshTokenTTL),
+	}, token, nil
+}
+
+func (s *Service) issue(user *models.User, refresh *models.RefreshToken, refreshToken string) (*TokenPair, error) {
+	accessToken, expiresAt, err := utils.GenerateToken(user.ID, user.Role, refresh.FamilyID, s.jwtSecret, s.accessTokenTTL)
+	if err != nil {
+		return nil, err
 	}
 
-	return s.userService.Create(user)
+	return &TokenPair{
+		AccessToken:      accessToken,
+		AccessExpiresAt:  expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refresh.ExpiresAt,
+	}, nil
 }
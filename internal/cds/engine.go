@@ -0,0 +1,335 @@
+// Package cds is a clinical decision support engine that checks a drug
+// about to be prescribed against a patient's current medications and
+// allergies. Its knowledge comes from a local dataset of drug classes,
+// allergy cross-reactions and drug interactions; nothing is looked up over
+// the network.
+package cds
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+	"hospital-management/internal/models"
+)
+
+//go:embed data/dataset.json
+var defaultDataset []byte
+
+// maxTermWords is the longest drug or allergen name, in words, that is
+// looked for inside free-text drug and substance names.
+const maxTermWords = 3
+
+var severityRank = map[string]int{
+	models.AlertContraindicated: 4,
+	models.AlertMajor:           3,
+	models.AlertModerate:        2,
+	models.AlertMinor:           1,
+}
+
+// Dataset is the engine's knowledge base. Drugs maps drug names to their
+// classes and Allergens maps allergy names that are not drugs, such as
+// "sulfa", to drug names or classes. A drug and an allergy conflict when
+// they share a name or one of AllergyClasses; CrossReactions and
+// Interactions pair names or classes.
+type Dataset struct {
+	AllergyClasses []string            `json:"allergy_classes"`
+	Drugs          map[string][]string `json:"drugs"`
+	Allergens      map[string][]string `json:"allergens"`
+	CrossReactions []CrossReaction     `json:"cross_reactions"`
+	Interactions   []Interaction       `json:"interactions"`
+}
+
+// CrossReaction warns that a patient allergic to Allergen may react to Drug.
+type CrossReaction struct {
+	Allergen string `json:"allergen"`
+	Drug     string `json:"drug"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// Interaction warns against taking A and B together.
+type Interaction struct {
+	A        string `json:"a"`
+	B        string `json:"b"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+type Engine struct {
+	data           Dataset
+	allergyClasses map[string]bool
+}
+
+// Load returns an engine using the dataset in the JSON file at path, or the
+// built-in dataset when path is empty.
+func Load(path string) (*Engine, error) {
+	raw := defaultDataset
+	if path != "" {
+		var err error
+		if raw, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("cds: reading dataset: %w", err)
+		}
+	}
+
+	var data Dataset
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("cds: parsing dataset: %w", err)
+	}
+	return New(data)
+}
+
+// New returns an engine using data, which is validated and normalized.
+func New(data Dataset) (*Engine, error) {
+	e := &Engine{
+		data: Dataset{
+			Drugs:     normalizeMap(data.Drugs),
+			Allergens: normalizeMap(data.Allergens),
+		},
+		allergyClasses: make(map[string]bool, len(data.AllergyClasses)),
+	}
+	for _, class := range data.AllergyClasses {
+		e.allergyClasses[normalize(class)] = true
+	}
+
+	for i, r := range data.CrossReactions {
+		if _, ok := severityRank[r.Severity]; !ok || r.Allergen == "" || r.Drug == "" {
+			return nil, fmt.Errorf("cds: cross reaction %d is invalid", i)
+		}
+		r.Allergen, r.Drug = normalize(r.Allergen), normalize(r.Drug)
+		e.data.CrossReactions = append(e.data.CrossReactions, r)
+	}
+	for i, r := range data.Interactions {
+		if _, ok := severityRank[r.Severity]; !ok || r.A == "" || r.B == "" {
+			return nil, fmt.Errorf("cds: interaction %d is invalid", i)
+		}
+		r.A, r.B = normalize(r.A), normalize(r.B)
+		e.data.Interactions = append(e.data.Interactions, r)
+	}
+
+	return e, nil
+}
+
+// Check returns the alerts raised by prescribing drug to a patient taking
+// current and with allergies, most severe first. Inactive allergies and
+// allergies refuted or entered in error are ignored.
+func (e *Engine) Check(drug string, current []models.Prescription, allergies []models.AllergyIntolerance) []models.PrescriptionAlert {
+	mentioned := termSet(drug)
+	names := e.drugNames(drug)
+	classes := e.classesOf(names)
+
+	var alerts []models.PrescriptionAlert
+	for i := range allergies {
+		if alert, ok := e.checkAllergy(drug, mentioned, names, classes, &allergies[i]); ok {
+			alerts = append(alerts, alert)
+		}
+	}
+	for i := range current {
+		if alert, ok := e.checkInteraction(drug, mentioned, names, classes, &current[i]); ok {
+			alerts = append(alerts, alert)
+		}
+	}
+
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return severityRank[alerts[i].Severity] > severityRank[alerts[j].Severity]
+	})
+	return alerts
+}
+
+// Blocking reports whether any of alerts must be overridden.
+func Blocking(alerts []models.PrescriptionAlert) bool {
+	for i := range alerts {
+		if alerts[i].Blocking() {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAllergy and checkInteraction first look for the allergy substance or
+// the other drug by name among the terms mentioned in drug, so that drugs
+// missing from the dataset are still caught, and only then consult it.
+func (e *Engine) checkAllergy(drug string, mentioned, names, classes map[string]bool, allergy *models.AllergyIntolerance) (models.PrescriptionAlert, bool) {
+	if allergy.Status != models.AllergyActive ||
+		allergy.VerificationStatus == models.AllergyRefuted ||
+		allergy.VerificationStatus == models.AllergyEnteredInError {
+		return models.PrescriptionAlert{}, false
+	}
+
+	allergen := e.allergenTerms(allergy.Substance)
+	best := models.PrescriptionAlert{}
+
+	conflict := mentioned[phrase(allergy.Substance)]
+	for term := range allergen {
+		conflict = conflict || names[term] || (classes[term] && e.allergyClasses[term])
+	}
+	if conflict {
+		best = models.PrescriptionAlert{
+			Severity: models.AlertContraindicated,
+			Message:  fmt.Sprintf("%s conflicts with the patient's %s allergy", drug, allergy.Substance),
+		}
+	}
+	if best.Severity == "" {
+		for _, r := range e.data.CrossReactions {
+			if allergen[r.Allergen] && (names[r.Drug] || classes[r.Drug]) && severityRank[r.Severity] > severityRank[best.Severity] {
+				best = models.PrescriptionAlert{
+					Severity: r.Severity,
+					Message:  fmt.Sprintf("%s: patient has a %s allergy. %s", drug, allergy.Substance, r.Message),
+				}
+			}
+		}
+	}
+	if best.Severity == "" {
+		return best, false
+	}
+
+	if allergy.Reaction != "" {
+		best.Message += fmt.Sprintf(" (reaction: %s)", allergy.Reaction)
+	}
+	if allergy.VerificationStatus == models.AllergyUnverified {
+		best.Message += " [allergy unverified]"
+	}
+	best.Type = models.AlertDrugAllergy
+	best.PatientID = allergy.PatientID
+	best.AllergyID = &allergy.ID
+	return best, true
+}
+
+func (e *Engine) checkInteraction(drug string, mentioned, names, classes map[string]bool, other *models.Prescription) (models.PrescriptionAlert, bool) {
+	otherNames := e.drugNames(other.Drug)
+	otherClasses := e.classesOf(otherNames)
+	best := models.PrescriptionAlert{}
+
+	duplicate := mentioned[phrase(other.Drug)] || termSet(other.Drug)[phrase(drug)]
+	for name := range names {
+		duplicate = duplicate || otherNames[name]
+	}
+	if duplicate {
+		return models.PrescriptionAlert{
+			Type:                  models.AlertDuplicate,
+			Severity:              models.AlertModerate,
+			Message:               fmt.Sprintf("Patient is already prescribed %s (%s %s)", other.Drug, other.Dose, other.Frequency),
+			PatientID:             other.PatientID,
+			RelatedPrescriptionID: &other.ID,
+		}, true
+	}
+
+	has := func(terms, classes map[string]bool, term string) bool {
+		return terms[term] || classes[term]
+	}
+	for _, r := range e.data.Interactions {
+		matches := (has(names, classes, r.A) && has(otherNames, otherClasses, r.B)) ||
+			(has(names, classes, r.B) && has(otherNames, otherClasses, r.A))
+		if matches && severityRank[r.Severity] > severityRank[best.Severity] {
+			best = models.PrescriptionAlert{
+				Type:     models.AlertDrugDrug,
+				Severity: r.Severity,
+				Message:  fmt.Sprintf("%s with %s: %s", drug, other.Drug, r.Message),
+			}
+		}
+	}
+	if best.Severity == "" {
+		return best, false
+	}
+
+	best.PatientID = other.PatientID
+	best.RelatedPrescriptionID = &other.ID
+	return best, true
+}
+
+// drugNames returns the known drugs named in a free-text drug name, for
+// example both drugs in "amoxicillin + clarithromycin".
+func (e *Engine) drugNames(drug string) map[string]bool {
+	names := make(map[string]bool)
+	for _, term := range terms(drug) {
+		if _, ok := e.data.Drugs[term]; ok {
+			names[term] = true
+		}
+	}
+	return names
+}
+
+func (e *Engine) classesOf(names map[string]bool) map[string]bool {
+	classes := make(map[string]bool)
+	for name := range names {
+		for _, class := range e.data.Drugs[name] {
+			classes[class] = true
+		}
+	}
+	return classes
+}
+
+// allergenTerms returns the drug names and classes an allergy to substance
+// covers: drugs named in it with their allergy classes, and anything listed
+// for allergens named in it.
+func (e *Engine) allergenTerms(substance string) map[string]bool {
+	covered := make(map[string]bool)
+	for _, term := range terms(substance) {
+		if classes, ok := e.data.Drugs[term]; ok {
+			covered[term] = true
+			for _, class := range classes {
+				if e.allergyClasses[class] {
+					covered[class] = true
+				}
+			}
+		}
+		for _, t := range e.data.Allergens[term] {
+			covered[t] = true
+		}
+	}
+	return covered
+}
+
+// terms returns the normalized text and every run of up to maxTermWords
+// consecutive words in it.
+func terms(text string) []string {
+	words := words(text)
+
+	found := []string{normalize(text), strings.Join(words, " ")}
+	for i := range words {
+		for n := 1; n <= maxTermWords && i+n <= len(words); n++ {
+			found = append(found, strings.Join(words[i:i+n], " "))
+		}
+	}
+	return found
+}
+
+func termSet(text string) map[string]bool {
+	set := make(map[string]bool)
+	for _, term := range terms(text) {
+		if term != "" {
+			set[term] = true
+		}
+	}
+	return set
+}
+
+// phrase returns the words of text, without punctuation, as one term.
+func phrase(text string) string {
+	return strings.Join(words(text), " ")
+}
+
+func words(text string) []string {
+	return strings.FieldsFunc(normalize(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-'
+	})
+}
+
+func normalize(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+func normalizeMap(m map[string][]string) map[string][]string {
+	normalized := make(map[string][]string, len(m))
+	for key, values := range m {
+		key = normalize(key)
+		normalized[key] = make([]string, 0, len(values))
+		for _, v := range values {
+			normalized[key] = append(normalized[key], normalize(v))
+		}
+	}
+	return normalized
+}
@@ -0,0 +1,93 @@
+package cds
+
+import (
+	"testing"
+	"hospital-management/internal/models"
+)
+
+type alert struct {
+	typ, severity string
+}
+
+func allergy(substance string) models.AllergyIntolerance {
+	return models.AllergyIntolerance{
+		Substance:          substance,
+		Status:             models.AllergyActive,
+		VerificationStatus: models.AllergyConfirmed,
+	}
+}
+
+func TestCheck(t *testing.T) {
+	engine, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		drug      string
+		current   []string
+		allergies []models.AllergyIntolerance
+		want      []alert
+	}{
+		{"known drug, allergy to it", "Amoxicillin 500 mg", nil,
+			[]models.AllergyIntolerance{allergy("amoxicillin")},
+			[]alert{{models.AlertDrugAllergy, models.AlertContraindicated}}},
+		{"allergy to the drug's class", "amoxicillin", nil,
+			[]models.AllergyIntolerance{allergy("Penicillin")},
+			[]alert{{models.AlertDrugAllergy, models.AlertContraindicated}}},
+		{"class cross-reaction", "Ceftriaxone 1 g IV", nil,
+			[]models.AllergyIntolerance{allergy("penicillins")},
+			[]alert{{models.AlertDrugAllergy, models.AlertModerate}}},
+		{"allergen that is not a drug", "co-trimoxazole", nil,
+			[]models.AllergyIntolerance{allergy("Sulfa")},
+			[]alert{{models.AlertDrugAllergy, models.AlertContraindicated}}},
+		{"unknown drug, allergy to it", "Zyxlor 10 mg tablets", nil,
+			[]models.AllergyIntolerance{allergy("ZYXLOR")},
+			[]alert{{models.AlertDrugAllergy, models.AlertContraindicated}}},
+		{"unknown drug, unrelated allergy", "Zyxlor 10 mg", nil,
+			[]models.AllergyIntolerance{allergy("latex")},
+			nil},
+		{"refuted allergy", "amoxicillin", nil,
+			[]models.AllergyIntolerance{{Substance: "amoxicillin", Status: models.AllergyActive, VerificationStatus: models.AllergyRefuted}},
+			nil},
+		{"known drug already prescribed", "Ibuprofen 200 mg", []string{"ibuprofen 400 mg"}, nil,
+			[]alert{{models.AlertDuplicate, models.AlertModerate}}},
+		{"unknown drug already prescribed", "Zyxlor 20 mg", []string{"zyxlor"}, nil,
+			[]alert{{models.AlertDuplicate, models.AlertModerate}}},
+		{"unknown drugs sharing a dose", "Zyxlor 10 mg", []string{"Quentrol 10 mg"}, nil,
+			nil},
+		{"class interaction", "clarithromycin", []string{"Simvastatin 40 mg"}, nil,
+			[]alert{{models.AlertDrugDrug, models.AlertContraindicated}}},
+		{"most severe first", "Aspirin 75 mg", []string{"warfarin"},
+			[]models.AllergyIntolerance{allergy("latex"), allergy("ibuprofen")},
+			[]alert{
+				{models.AlertDrugAllergy, models.AlertContraindicated},
+				{models.AlertDrugDrug, models.AlertMajor},
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var current []models.Prescription
+			for _, drug := range tt.current {
+				current = append(current, models.Prescription{Drug: drug})
+			}
+
+			alerts := engine.Check(tt.drug, current, tt.allergies)
+
+			var got []alert
+			for _, a := range alerts {
+				got = append(got, alert{a.Type, a.Severity})
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("alerts = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("alerts = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
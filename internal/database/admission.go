@@ -0,0 +1,20 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// limitActiveAdmissions backs the bed locking done by admissions: a bed
+// holds at most one admitted patient, and a patient is admitted at most once
+// at a time.
+func limitActiveAdmissions(db *gorm.DB) error {
+	return db.Exec(`
+		CREATE UNIQUE INDEX IF NOT EXISTS admissions_one_per_bed
+			ON admissions (bed_id)
+			WHERE status = 'admitted';
+
+		CREATE UNIQUE INDEX IF NOT EXISTS admissions_one_per_patient
+			ON admissions (patient_id)
+			WHERE status = 'admitted';
+	`).Error
+}
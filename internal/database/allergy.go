@@ -0,0 +1,28 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// migrateLegacyAllergies moves the free-text patients.allergies column into
+// one unverified allergy entry per patient for a clinician to review, and
+// then drops the column.
+func migrateLegacyAllergies(db *gorm.DB) error {
+	if !db.Migrator().HasColumn("patients", "allergies") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+			INSERT INTO allergy_intolerances
+				(patient_id, substance, status, verification_status, note, recorded_by_id, created_at, updated_at)
+			SELECT id, btrim(allergies), 'active', 'unverified',
+				'Migrated from the free-text allergies field', created_by, NOW(), NOW()
+			FROM patients
+			WHERE btrim(COALESCE(allergies, '')) <> ''
+		`).Error; err != nil {
+			return err
+		}
+		return tx.Migrator().DropColumn("patients", "allergies")
+	})
+}
@@ -0,0 +1,29 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// preventDoubleBooking adds exclusion constraints so that neither a doctor
+// nor a patient can hold two scheduled appointments whose time ranges
+// overlap. Cancelled appointments free their slot.
+func preventDoubleBooking(db *gorm.DB) error {
+	return db.Exec(`
+		CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+		DO $$
+		BEGIN
+			IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'appointments_doctor_no_overlap') THEN
+				ALTER TABLE appointments ADD CONSTRAINT appointments_doctor_no_overlap
+					EXCLUDE USING gist (doctor_id WITH =, tstzrange(starts_at, ends_at, '[)') WITH &&)
+					WHERE (status <> 'cancelled');
+			END IF;
+			IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'appointments_patient_no_overlap') THEN
+				ALTER TABLE appointments ADD CONSTRAINT appointments_patient_no_overlap
+					EXCLUDE USING gist (patient_id WITH =, tstzrange(starts_at, ends_at, '[)') WITH &&)
+					WHERE (status <> 'cancelled');
+			END IF;
+		END
+		$$;
+	`).Error
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"gorm.io/gorm"
+	"hospital-management/internal/models"
+)
+
+// protectAuditLog links entries written before hash chaining existed into the
+// chain and then installs a trigger that rejects UPDATE and DELETE on
+// audit_logs.
+func protectAuditLog(db *gorm.DB) error {
+	if err := backfillAuditChain(db); err != nil {
+		return err
+	}
+
+	return db.Exec(`
+		CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'audit_logs is append-only';
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS audit_logs_append_only ON audit_logs;
+		CREATE TRIGGER audit_logs_append_only
+			BEFORE UPDATE OR DELETE ON audit_logs
+			FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only();
+	`).Error
+}
+
+func backfillAuditChain(db *gorm.DB) error {
+	var unchained int64
+	if err := db.Model(&models.AuditLog{}).Where("hash = ''").Count(&unchained).Error; err != nil {
+		return err
+	}
+	if unchained == 0 {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var entries []models.AuditLog
+		if err := tx.Order("id").Find(&entries).Error; err != nil {
+			return err
+		}
+
+		prevHash := ""
+		for i := range entries {
+			entry := &entries[i]
+			if entry.Hash == "" {
+				entry.PrevHash = prevHash
+				entry.Hash = entry.ComputeHash()
+				if err := tx.Model(entry).Updates(map[string]interface{}{
+					"prev_hash": entry.PrevHash,
+					"hash":      entry.Hash,
+				}).Error; err != nil {
+					return err
+				}
+			}
+			prevHash = entry.Hash
+		}
+		return nil
+	})
+}
@@ -0,0 +1,32 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// protectSignedDischargeSummaries installs a trigger that rejects changes to
+// a signed discharge summary. Only patient_id may change, so that patient
+// merges can move it.
+func protectSignedDischargeSummaries(db *gorm.DB) error {
+	return db.Exec(`
+		CREATE OR REPLACE FUNCTION discharge_summaries_signed_immutable() RETURNS trigger AS $$
+		BEGIN
+			IF OLD.status = 'signed' AND
+				(NEW.status, NEW.admission_id, NEW.author_id, NEW.diagnoses, NEW.hospital_course,
+				 NEW.procedures, NEW.medications, NEW.allergies, NEW.follow_up, NEW.signed_at)
+				IS DISTINCT FROM
+				(OLD.status, OLD.admission_id, OLD.author_id, OLD.diagnoses, OLD.hospital_course,
+				 OLD.procedures, OLD.medications, OLD.allergies, OLD.follow_up, OLD.signed_at)
+			THEN
+				RAISE EXCEPTION 'signed discharge summary % cannot be changed', OLD.id;
+			END IF;
+			RETURN NEW;
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS discharge_summaries_signed_immutable ON discharge_summaries;
+		CREATE TRIGGER discharge_summaries_signed_immutable
+			BEFORE UPDATE ON discharge_summaries
+			FOR EACH ROW EXECUTE FUNCTION discharge_summaries_signed_immutable();
+	`).Error
+}
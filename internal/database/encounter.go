@@ -0,0 +1,49 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// protectSignedEncounters installs triggers that reject changes to a signed
+// encounter's note and to addenda. Only patient_id may change, so that
+// patient merges can move them.
+func protectSignedEncounters(db *gorm.DB) error {
+	return db.Exec(`
+		CREATE OR REPLACE FUNCTION encounters_signed_immutable() RETURNS trigger AS $$
+		BEGIN
+			IF OLD.status = 'signed' AND
+				(NEW.status, NEW.doctor_id, NEW.visit_id, NEW.encounter_at, NEW.reason,
+				 NEW.subjective, NEW.objective, NEW.assessment, NEW.plan, NEW.signed_at, NEW.signed_by_id)
+				IS DISTINCT FROM
+				(OLD.status, OLD.doctor_id, OLD.visit_id, OLD.encounter_at, OLD.reason,
+				 OLD.subjective, OLD.objective, OLD.assessment, OLD.plan, OLD.signed_at, OLD.signed_by_id)
+			THEN
+				RAISE EXCEPTION 'signed encounter % cannot be changed', OLD.id;
+			END IF;
+			RETURN NEW;
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS encounters_signed_immutable ON encounters;
+		CREATE TRIGGER encounters_signed_immutable
+			BEFORE UPDATE ON encounters
+			FOR EACH ROW EXECUTE FUNCTION encounters_signed_immutable();
+
+		CREATE OR REPLACE FUNCTION encounter_addenda_immutable() RETURNS trigger AS $$
+		BEGIN
+			IF (NEW.encounter_id, NEW.author_id, NEW.content, NEW.created_at)
+				IS DISTINCT FROM
+				(OLD.encounter_id, OLD.author_id, OLD.content, OLD.created_at)
+			THEN
+				RAISE EXCEPTION 'encounter addendum % cannot be changed', OLD.id;
+			END IF;
+			RETURN NEW;
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS encounter_addenda_immutable ON encounter_addenda;
+		CREATE TRIGGER encounter_addenda_immutable
+			BEFORE UPDATE ON encounter_addenda
+			FOR EACH ROW EXECUTE FUNCTION encounter_addenda_immutable();
+	`).Error
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"gorm.io/gorm"
+	"hospital-management/internal/models"
+)
+
+// adultAgeDays is the age, in days, from which adult reference ranges apply.
+const adultAgeDays = 18*365 + 4
+
+var defaultLabTests = []models.LabTest{
+	{
+		Code: "HGB", Name: "Hemoglobin", Unit: "g/dL", Specimen: "blood",
+		ReferenceRanges: []models.LabReferenceRange{
+			{MaxAgeDays: intPtr(adultAgeDays), Low: floatPtr(11.0), High: floatPtr(14.5), CriticalLow: floatPtr(7.0), CriticalHigh: floatPtr(20.0)},
+			{Sex: "male", MinAgeDays: adultAgeDays, Low: floatPtr(13.5), High: floatPtr(17.5), CriticalLow: floatPtr(7.0), CriticalHigh: floatPtr(20.0)},
+			{Sex: "female", MinAgeDays: adultAgeDays, Low: floatPtr(12.0), High: floatPtr(15.5), CriticalLow: floatPtr(7.0), CriticalHigh: floatPtr(20.0)},
+		},
+	},
+	{
+		Code: "WBC", Name: "White blood cell count", Unit: "10^9/L", Specimen: "blood",
+		ReferenceRanges: []models.LabReferenceRange{
+			{MaxAgeDays: intPtr(adultAgeDays), Low: floatPtr(5.0), High: floatPtr(14.5), CriticalLow: floatPtr(2.0), CriticalHigh: floatPtr(30.0)},
+			{MinAgeDays: adultAgeDays, Low: floatPtr(4.5), High: floatPtr(11.0), CriticalLow: floatPtr(2.0), CriticalHigh: floatPtr(30.0)},
+		},
+	},
+	{
+		Code: "PLT", Name: "Platelet count", Unit: "10^9/L", Specimen: "blood",
+		ReferenceRanges: []models.LabReferenceRange{
+			{Low: floatPtr(150), High: floatPtr(450), CriticalLow: floatPtr(50), CriticalHigh: floatPtr(1000)},
+		},
+	},
+	{
+		Code: "NA", Name: "Sodium", Unit: "mmol/L", Specimen: "serum",
+		ReferenceRanges: []models.LabReferenceRange{
+			{Low: floatPtr(135), High: floatPtr(145), CriticalLow: floatPtr(120), CriticalHigh: floatPtr(160)},
+		},
+	},
+	{
+		Code: "K", Name: "Potassium", Unit: "mmol/L", Specimen: "serum",
+		ReferenceRanges: []models.LabReferenceRange{
+			{Low: floatPtr(3.5), High: floatPtr(5.1), CriticalLow: floatPtr(2.5), CriticalHigh: floatPtr(6.5)},
+		},
+	},
+	{
+		Code: "CREA", Name: "Creatinine", Unit: "mg/dL", Specimen: "serum",
+		ReferenceRanges: []models.LabReferenceRange{
+			{MaxAgeDays: intPtr(adultAgeDays), Low: floatPtr(0.3), High: floatPtr(0.7)},
+			{Sex: "male", MinAgeDays: adultAgeDays, Low: floatPtr(0.74), High: floatPtr(1.35), CriticalHigh: floatPtr(10.0)},
+			{Sex: "female", MinAgeDays: adultAgeDays, Low: floatPtr(0.59), High: floatPtr(1.04), CriticalHigh: floatPtr(10.0)},
+		},
+	},
+	{
+		Code: "GLU", Name: "Glucose, fasting", Unit: "mg/dL", Specimen: "plasma",
+		ReferenceRanges: []models.LabReferenceRange{
+			{Low: floatPtr(70), High: floatPtr(99), CriticalLow: floatPtr(40), CriticalHigh: floatPtr(400)},
+		},
+	},
+	{
+		Code: "HBA1C", Name: "Hemoglobin A1c", Unit: "%", Specimen: "blood",
+		ReferenceRanges: []models.LabReferenceRange{
+			{High: floatPtr(5.6)},
+		},
+	},
+	{
+		Code: "TSH", Name: "Thyroid stimulating hormone", Unit: "mIU/L", Specimen: "serum",
+		ReferenceRanges: []models.LabReferenceRange{
+			{Low: floatPtr(0.4), High: floatPtr(4.0)},
+		},
+	},
+}
+
+// SeedLabTests adds the built-in lab tests that are missing from the
+// catalog, with their reference ranges. Tests already in the catalog are left
+// as the lab configured them.
+func SeedLabTests(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, t := range defaultLabTests {
+			var count int64
+			if err := tx.Model(&models.LabTest{}).Where("code = ?", t.Code).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
+
+			test := t
+			test.IsActive = true
+			test.ReferenceRanges = append([]models.LabReferenceRange(nil), t.ReferenceRanges...)
+			if err := tx.Create(&test).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
@@ -3,11 +3,105 @@ package database
 import (
 	"gorm.io/gorm"
 	"hospital-management/internal/models"
+	"hospital-management/internal/patient"
 )
 
 func RunMigrations(db *gorm.DB) error {
-	return db.AutoMigrate(
+	if err := db.AutoMigrate(
+		&models.Permission{},
+		&models.Role{},
 		&models.User{},
 		&models.Patient{},
-	)
+		&models.PatientMerge{},
+		&models.PatientRevision{},
+		&models.RefreshToken{},
+		&models.UsedStreamToken{},
+		&models.Invitation{},
+		&models.AuditLog{},
+		&models.AppointmentType{},
+		&models.Appointment{},
+		&models.WeeklyHours{},
+		&models.DoctorLeave{},
+		&models.ScheduleException{},
+		&models.Visit{},
+		&models.Encounter{},
+		&models.EncounterAddendum{},
+		&models.AllergyIntolerance{},
+		&models.Prescription{},
+		&models.PrescriptionRefill{},
+		&models.PrescriptionAlert{},
+		&models.LabTest{},
+		&models.LabReferenceRange{},
+		&models.LabOrder{},
+		&models.LabResult{},
+		&models.VitalSigns{},
+		&models.Ward{},
+		&models.Room{},
+		&models.Bed{},
+		&models.Admission{},
+		&models.AdmissionTransfer{},
+		&models.DischargeSummary{},
+		&models.Problem{},
+		&models.EncounterDiagnosis{},
+	); err != nil {
+		return err
+	}
+
+	// Roles used to be limited by a CHECK constraint; they now live in the
+	// roles table.
+	if db.Migrator().HasConstraint(&models.User{}, "chk_users_role") {
+		if err := db.Migrator().DropConstraint(&models.User{}, "chk_users_role"); err != nil {
+			return err
+		}
+	}
+
+	if err := patient.RecordBaselines(db); err != nil {
+		return err
+	}
+
+	if err := foldPatientNames(db); err != nil {
+		return err
+	}
+
+	if err := migrateLegacyAllergies(db); err != nil {
+		return err
+	}
+
+	if err := protectAuditLog(db); err != nil {
+		return err
+	}
+
+	if err := preventDoubleBooking(db); err != nil {
+		return err
+	}
+
+	if err := limitActiveVisits(db); err != nil {
+		return err
+	}
+
+	if err := protectSignedEncounters(db); err != nil {
+		return err
+	}
+
+	if err := limitActiveAdmissions(db); err != nil {
+		return err
+	}
+
+	if err := protectSignedDischargeSummaries(db); err != nil {
+		return err
+	}
+
+	if err := limitOpenProblems(db); err != nil {
+		return err
+	}
+
+	if err := SeedAppointmentTypes(db); err != nil {
+		return err
+	}
+
+	if err := SeedLabTests(db); err != nil {
+		return err
+	}
+
+	return SeedRBAC(db)
 }
@@ -0,0 +1,28 @@
+package database
+
+import (
+	"gorm.io/gorm"
+	"hospital-management/internal/models"
+	"hospital-management/pkg/matching"
+)
+
+// foldPatientNames fills the folded name keys of patients stored before the
+// keys existed, including deleted ones so that restored records are found.
+func foldPatientNames(db *gorm.DB) error {
+	var patients []models.Patient
+	return db.Unscoped().
+		Select("id", "first_name", "last_name").
+		Where("first_name_key = '' AND last_name_key = ''").
+		FindInBatches(&patients, 500, func(tx *gorm.DB, batch int) error {
+			for _, p := range patients {
+				if err := db.Unscoped().Model(&models.Patient{}).Where("id = ?", p.ID).
+					UpdateColumns(map[string]interface{}{
+						"first_name_key": matching.NormalizeName(p.FirstName),
+						"last_name_key":  matching.NormalizeName(p.LastName),
+					}).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
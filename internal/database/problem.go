@@ -0,0 +1,15 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// limitOpenProblems allows each patient at most one problem per code that
+// has not been resolved.
+func limitOpenProblems(db *gorm.DB) error {
+	return db.Exec(`
+		CREATE UNIQUE INDEX IF NOT EXISTS problems_one_open_per_code
+			ON problems (patient_id, code)
+			WHERE status <> 'resolved';
+	`).Error
+}
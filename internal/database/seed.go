@@ -0,0 +1,243 @@
+package database
+
+import (
+	"gorm.io/gorm"
+	"hospital-management/internal/models"
+	"hospital-management/pkg/utils"
+)
+
+var defaultPermissions = []models.Permission{
+	{Name: models.PermissionPatientRead, Description: "View patient records"},
+	{Name: models.PermissionPatientWrite, Description: "Register and edit patient demographics"},
+	{Name: models.PermissionPatientDelete, Description: "Delete patient records"},
+	{Name: models.PermissionPatientMerge, Description: "Merge and unmerge duplicate patient records"},
+	{Name: models.PermissionPatientRestore, Description: "List and restore deleted patient records"},
+	{Name: models.PermissionMedicalWrite, Description: "Edit patient medical information"},
+	{Name: models.PermissionUserRead, Description: "View staff accounts"},
+	{Name: models.PermissionUserWrite, Description: "Create, deactivate and reset staff accounts"},
+	{Name: models.PermissionRoleManage, Description: "Manage roles and their permissions"},
+	{Name: models.PermissionAuditRead, Description: "Query and verify the audit log"},
+	{Name: models.PermissionAppointmentRead, Description: "View appointments and agendas"},
+	{Name: models.PermissionAppointmentWrite, Description: "Book, reschedule and cancel appointments"},
+	{Name: models.PermissionScheduleManage, Description: "Manage appointment types and doctor schedules"},
+	{Name: models.PermissionQueueRead, Description: "View waiting queues"},
+	{Name: models.PermissionQueueWrite, Description: "Check patients in and move them through the queue"},
+	{Name: models.PermissionEncounterRead, Description: "View encounters and clinical notes"},
+	{Name: models.PermissionEncounterWrite, Description: "Write, sign and amend clinical notes"},
+	{Name: models.PermissionPrescriptionRead, Description: "View prescriptions and medication lists"},
+	{Name: models.PermissionPrescriptionWrite, Description: "Prescribe, refill and discontinue medications"},
+	{Name: models.PermissionLabRead, Description: "View lab orders and results"},
+	{Name: models.PermissionLabOrder, Description: "Order lab tests and acknowledge results"},
+	{Name: models.PermissionLabResult, Description: "Enter and correct lab results"},
+	{Name: models.PermissionLabCatalog, Description: "Manage the lab test catalog and reference ranges"},
+	{Name: models.PermissionVitalsRead, Description: "View vital signs and early-warning scores"},
+	{Name: models.PermissionVitalsWrite, Description: "Record vital signs"},
+	{Name: models.PermissionAdmissionRead, Description: "View admissions and the bed board"},
+	{Name: models.PermissionAdmissionWrite, Description: "Admit, transfer and discharge inpatients and update bed status"},
+	{Name: models.PermissionWardManage, Description: "Manage wards, rooms and beds"},
+	{Name: models.PermissionProblemRead, Description: "View problem lists and search diagnosis codes"},
+	{Name: models.PermissionProblemWrite, Description: "Add and update coded problems and encounter diagnoses"},
+	{Name: models.PermissionDischargeManage, Description: "Reassign and discard other doctors' draft discharge summaries"},
+}
+
+type roleSeed struct {
+	name        string
+	description string
+	permissions []string
+}
+
+var defaultRoles = []roleSeed{
+	{
+		name:        models.RoleAdmin,
+		description: "System administrator",
+		permissions: []string{
+			models.PermissionUserRead,
+			models.PermissionUserWrite,
+			models.PermissionRoleManage,
+			models.PermissionPatientRestore,
+			models.PermissionScheduleManage,
+			models.PermissionLabCatalog,
+			models.PermissionWardManage,
+			models.PermissionDischargeManage,
+		},
+	},
+	{
+		name:        models.RoleCompliance,
+		description: "Compliance officer",
+		permissions: []string{
+			models.PermissionAuditRead,
+		},
+	},
+	{
+		name:        models.RoleReceptionist,
+		description: "Front desk staff",
+		permissions: []string{
+			models.PermissionPatientRead,
+			models.PermissionPatientWrite,
+			models.PermissionPatientDelete,
+			models.PermissionPatientMerge,
+			models.PermissionAppointmentRead,
+			models.PermissionAppointmentWrite,
+			models.PermissionQueueRead,
+			models.PermissionQueueWrite,
+			models.PermissionAdmissionRead,
+			models.PermissionAdmissionWrite,
+		},
+	},
+	{
+		name:        models.RoleDoctor,
+		description: "Medical staff",
+		permissions: []string{
+			models.PermissionPatientRead,
+			models.PermissionMedicalWrite,
+			models.PermissionAppointmentRead,
+			models.PermissionQueueRead,
+			models.PermissionQueueWrite,
+			models.PermissionEncounterRead,
+			models.PermissionEncounterWrite,
+			models.PermissionPrescriptionRead,
+			models.PermissionPrescriptionWrite,
+			models.PermissionLabRead,
+			models.PermissionLabOrder,
+			models.PermissionVitalsRead,
+			models.PermissionVitalsWrite,
+			models.PermissionAdmissionRead,
+			models.PermissionAdmissionWrite,
+			models.PermissionProblemRead,
+			models.PermissionProblemWrite,
+		},
+	},
+	{
+		name:        models.RoleNurse,
+		description: "Nursing staff",
+		permissions: []string{
+			models.PermissionPatientRead,
+			models.PermissionQueueRead,
+			models.PermissionQueueWrite,
+			models.PermissionVitalsRead,
+			models.PermissionVitalsWrite,
+			models.PermissionAdmissionRead,
+			models.PermissionAdmissionWrite,
+			models.PermissionProblemRead,
+		},
+	},
+	{
+		name:        models.RoleLabTechnician,
+		description: "Laboratory staff",
+		permissions: []string{
+			models.PermissionLabRead,
+			models.PermissionLabResult,
+		},
+	},
+}
+
+// SeedRBAC makes sure the built-in permissions and roles exist. Seeding is
+// additive: permissions granted to roles by administrators are left alone, but
+// a built-in permission removed from a built-in role is granted again.
+func SeedRBAC(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		permissions := make(map[string]models.Permission)
+		for _, p := range defaultPermissions {
+			permission := p
+			if err := tx.Where(models.Permission{Name: permission.Name}).
+				Attrs(models.Permission{Description: permission.Description}).
+				FirstOrCreate(&permission).Error; err != nil {
+				return err
+			}
+			permissions[permission.Name] = permission
+		}
+
+		for _, seed := range defaultRoles {
+			role := models.Role{Name: seed.name}
+			if err := tx.Where(models.Role{Name: seed.name}).
+				Attrs(models.Role{Description: seed.description}).
+				FirstOrCreate(&role).Error; err != nil {
+				return err
+			}
+
+			granted := make([]models.Permission, 0, len(seed.permissions))
+			for _, name := range seed.permissions {
+				granted = append(granted, permissions[name])
+			}
+			if err := tx.Model(&role).Association("Permissions").Append(granted); err != nil {
+				return err
+			}
+		}
+
+		return backfillUserRoles(tx)
+	})
+}
+
+var defaultAppointmentTypes = []models.AppointmentType{
+	{Name: "consultation", Description: "New patient consultation", DurationMinutes: 30},
+	{Name: "follow_up", Description: "Follow-up visit", DurationMinutes: 15},
+	{Name: "procedure", Description: "Minor procedure", DurationMinutes: 60},
+}
+
+// SeedAppointmentTypes creates the built-in appointment types that do not
+// exist yet. Existing types are left as administrators configured them.
+func SeedAppointmentTypes(db *gorm.DB) error {
+	for _, t := range defaultAppointmentTypes {
+		appointmentType := t
+		if err := db.Where(models.AppointmentType{Name: appointmentType.Name}).
+			Attrs(appointmentType).
+			FirstOrCreate(&appointmentType).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillUserRoles grants every user without role memberships the role named
+// in their legacy users.role column.
+func backfillUserRoles(tx *gorm.DB) error {
+	return tx.Exec(`
+		INSERT INTO user_roles (user_id, role_id)
+		SELECT u.id, r.id
+		FROM users u
+		JOIN roles r ON r.name = u.role
+		WHERE NOT EXISTS (SELECT 1 FROM user_roles ur WHERE ur.user_id = u.id)
+	`).Error
+}
+
+// SeedAdmin creates the bootstrap administrator when no user holds the admin
+// role yet. It does nothing when no password is configured. The account must
+// change its password at first login.
+func SeedAdmin(db *gorm.DB, username, email, password string) error {
+	if password == "" {
+		return nil
+	}
+
+	var count int64
+	if err := db.Table("user_roles").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("roles.name = ?", models.RoleAdmin).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	var role models.Role
+	if err := db.Where("name = ?", models.RoleAdmin).First(&role).Error; err != nil {
+		return err
+	}
+
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	return db.Create(&models.User{
+		Username:           username,
+		Email:              email,
+		Password:           hashedPassword,
+		Role:               models.RoleAdmin,
+		FirstName:          "System",
+		LastName:           "Administrator",
+		IsActive:           true,
+		MustChangePassword: true,
+		Roles:              []models.Role{role},
+	}).Error
+}
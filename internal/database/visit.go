@@ -0,0 +1,19 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// limitActiveVisits allows each patient at most one visit that is still in
+// a queue, and each doctor at most one patient in consultation.
+func limitActiveVisits(db *gorm.DB) error {
+	return db.Exec(`
+		CREATE UNIQUE INDEX IF NOT EXISTS visits_one_active_per_patient
+			ON visits (patient_id)
+			WHERE status IN ('arrived', 'triaged', 'in_consultation');
+
+		CREATE UNIQUE INDEX IF NOT EXISTS visits_one_consultation_per_doctor
+			ON visits (doctor_id)
+			WHERE status = 'in_consultation';
+	`).Error
+}
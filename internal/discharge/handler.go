@@ -0,0 +1,268 @@
+package discharge
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"hospital-management/internal/audit"
+	"hospital-management/internal/models"
+	"hospital-management/internal/user"
+	"hospital-management/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type Handler struct {
+	service *Service
+	audit   *audit.Service
+}
+
+func NewHandler(service *Service, auditService *audit.Service) *Handler {
+	return &Handler{service: service, audit: auditService}
+}
+
+// summaryDiffIgnored lists fields left out of audit diffs because they
+// change on every write or are loaded from other tables.
+var summaryDiffIgnored = []string{"updated_at", "admission", "author"}
+
+// DraftSummary starts the discharge summary of an admission, filled in from
+// the patient record.
+func (h *Handler) DraftSummary(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid admission ID", err)
+		return
+	}
+
+	summary, err := h.service.Draft(uint(id), utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to draft discharge summary", err)
+		return
+	}
+
+	changes, _ := utils.DiffFields(nil, summary, summaryDiffIgnored...)
+	h.audit.Log(c, audit.Entry{
+		Action:       "discharge_summary.create",
+		ResourceType: "discharge_summary",
+		ResourceID:   summary.ID,
+		PatientID:    &summary.PatientID,
+		Changes:      changes,
+	})
+
+	utils.SuccessResponse(c, "Discharge summary drafted successfully", summary)
+}
+
+// GetAdmissionSummary returns the discharge summary of an admission.
+func (h *Handler) GetAdmissionSummary(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid admission ID", err)
+		return
+	}
+
+	summary, err := h.service.GetForAdmission(uint(id), utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Discharge summary not found", err)
+		return
+	}
+
+	h.logRead(c, "discharge_summary.read", summary)
+	utils.SuccessResponse(c, "Discharge summary retrieved successfully", summary)
+}
+
+func (h *Handler) GetSummary(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid discharge summary ID", err)
+		return
+	}
+
+	summary, err := h.service.Get(uint(id), utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Discharge summary not found", err)
+		return
+	}
+
+	h.logRead(c, "discharge_summary.read", summary)
+	utils.SuccessResponse(c, "Discharge summary retrieved successfully", summary)
+}
+
+func (h *Handler) UpdateSummary(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid discharge summary ID", err)
+		return
+	}
+
+	var req models.UpdateDischargeSummaryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	before, err := h.service.Get(uint(id), utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Discharge summary not found", err)
+		return
+	}
+
+	summary, err := h.service.Update(uint(id), req, utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to update discharge summary", err)
+		return
+	}
+
+	changes, _ := utils.DiffFields(before, summary, summaryDiffIgnored...)
+	h.audit.Log(c, audit.Entry{
+		Action:       "discharge_summary.update",
+		ResourceType: "discharge_summary",
+		ResourceID:   summary.ID,
+		PatientID:    &summary.PatientID,
+		Changes:      changes,
+	})
+
+	utils.SuccessResponse(c, "Discharge summary updated successfully", summary)
+}
+
+func (h *Handler) SignSummary(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid discharge summary ID", err)
+		return
+	}
+
+	summary, err := h.service.Sign(uint(id), utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to sign discharge summary", err)
+		return
+	}
+
+	h.audit.Log(c, audit.Entry{
+		Action:       "discharge_summary.sign",
+		ResourceType: "discharge_summary",
+		ResourceID:   summary.ID,
+		PatientID:    &summary.PatientID,
+	})
+
+	utils.SuccessResponse(c, "Discharge summary signed successfully", summary)
+}
+
+// ReassignSummary hands a draft over to another doctor.
+func (h *Handler) ReassignSummary(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid discharge summary ID", err)
+		return
+	}
+
+	var req models.ReassignDischargeSummaryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	summary, err := h.service.Reassign(uint(id), req, utils.CurrentPermissions(c), utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to reassign discharge summary", err)
+		return
+	}
+
+	h.audit.Log(c, audit.Entry{
+		Action:       "discharge_summary.reassign",
+		ResourceType: "discharge_summary",
+		ResourceID:   summary.ID,
+		PatientID:    &summary.PatientID,
+		Details:      gin.H{"author_id": summary.AuthorID},
+	})
+
+	utils.SuccessResponse(c, "Discharge summary reassigned successfully", summary)
+}
+
+// DiscardSummary deletes a draft so that the admission can be summarized
+// again.
+func (h *Handler) DiscardSummary(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid discharge summary ID", err)
+		return
+	}
+
+	summary, err := h.service.Discard(uint(id), utils.CurrentPermissions(c), utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to discard discharge summary", err)
+		return
+	}
+
+	changes, _ := utils.DiffFields(summary, nil, summaryDiffIgnored...)
+	h.audit.Log(c, audit.Entry{
+		Action:       "discharge_summary.delete",
+		ResourceType: "discharge_summary",
+		ResourceID:   summary.ID,
+		PatientID:    &summary.PatientID,
+		Changes:      changes,
+	})
+
+	utils.SuccessResponse(c, "Discharge summary discarded successfully", nil)
+}
+
+// PrintSummary returns the summary as an HTML page for printing.
+func (h *Handler) PrintSummary(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid discharge summary ID", err)
+		return
+	}
+
+	summary, page, err := h.service.RenderHTML(uint(id), utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to print discharge summary", err)
+		return
+	}
+
+	h.logRead(c, "discharge_summary.print", summary)
+	c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+}
+
+// DownloadSummary returns the summary as a PDF document.
+func (h *Handler) DownloadSummary(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid discharge summary ID", err)
+		return
+	}
+
+	summary, document, err := h.service.RenderPDF(uint(id), utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to export discharge summary", err)
+		return
+	}
+
+	h.logRead(c, "discharge_summary.pdf", summary)
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=\"discharge-summary-%d.pdf\"", summary.ID))
+	c.Data(http.StatusOK, "application/pdf", document)
+}
+
+func (h *Handler) logRead(c *gin.Context, action string, summary *models.DischargeSummary) {
+	h.audit.Log(c, audit.Entry{
+		Action:       action,
+		ResourceType: "discharge_summary",
+		ResourceID:   summary.ID,
+		PatientID:    &summary.PatientID,
+	})
+}
+
+// statusFor maps service errors to HTTP status codes.
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, user.ErrNotDoctor), errors.Is(err, ErrNotAuthor), errors.Is(err, ErrNotManager):
+		return http.StatusForbidden
+	case errors.Is(err, ErrSummaryExists), errors.Is(err, ErrAlreadySigned), errors.Is(err, ErrSummaryChanged):
+		return http.StatusConflict
+	case errors.Is(err, ErrNoDiagnoses):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
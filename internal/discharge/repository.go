@@ -0,0 +1,92 @@
+package discharge
+
+import (
+	"time"
+	"hospital-management/internal/models"
+	"hospital-management/pkg/utils"
+	"gorm.io/gorm"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// withDetails preloads what a discharge summary shows.
+func withDetails(db *gorm.DB) *gorm.DB {
+	return db.Preload("Admission").
+		Preload("Admission.Patient", utils.WithDeleted).
+		Preload("Admission.AttendingDoctor", utils.WithDeleted).
+		Preload("Admission.Bed.Room").
+		Preload("Author", utils.WithDeleted)
+}
+
+// Create saves a draft, returning ErrSummaryExists when the admission
+// already has a summary.
+func (r *Repository) Create(summary *models.DischargeSummary) error {
+	return utils.TranslateConflict(r.db.Omit("Admission", "Author").Create(summary).Error, map[string]error{
+		"idx_discharge_summaries_admission_id": ErrSummaryExists,
+	})
+}
+
+// Update writes changes to a summary that is still a draft, returning
+// ErrAlreadySigned when it was signed in the meantime.
+func (r *Repository) Update(id uint, changes map[string]interface{}) error {
+	result := r.db.Model(&models.DischargeSummary{}).
+		Where("id = ? AND status = ?", id, models.DischargeSummaryDraft).
+		Updates(changes)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAlreadySigned
+	}
+	return nil
+}
+
+// Sign signs a draft summary as long as it is unchanged since it was read,
+// so that what was checked before signing is what gets signed. It returns
+// ErrSummaryChanged otherwise.
+func (r *Repository) Sign(summary *models.DischargeSummary, at time.Time) error {
+	result := r.db.Model(&models.DischargeSummary{}).
+		Where("id = ? AND status = ? AND updated_at = ?", summary.ID, models.DischargeSummaryDraft, summary.UpdatedAt).
+		Updates(map[string]interface{}{
+			"status":    models.DischargeSummarySigned,
+			"signed_at": at,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSummaryChanged
+	}
+	return nil
+}
+
+// DeleteDraft deletes a summary that is still a draft, returning
+// ErrAlreadySigned when it was signed in the meantime.
+func (r *Repository) DeleteDraft(id uint) error {
+	result := r.db.Where("status = ?", models.DischargeSummaryDraft).Delete(&models.DischargeSummary{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAlreadySigned
+	}
+	return nil
+}
+
+func (r *Repository) GetByID(id uint) (*models.DischargeSummary, error) {
+	var summary models.DischargeSummary
+	err := withDetails(r.db).First(&summary, id).Error
+	return &summary, err
+}
+
+func (r *Repository) GetByAdmission(admissionID uint) (*models.DischargeSummary, error) {
+	var summary models.DischargeSummary
+	err := withDetails(r.db).Where("admission_id = ?", admissionID).First(&summary).Error
+	return &summary, err
+}
@@ -0,0 +1,455 @@
+package discharge
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+	"hospital-management/internal/admission"
+	"hospital-management/internal/allergy"
+	"hospital-management/internal/config"
+	"hospital-management/internal/encounter"
+	"hospital-management/internal/lab"
+	"hospital-management/internal/models"
+	"hospital-management/internal/prescription"
+	"hospital-management/internal/problem"
+	"hospital-management/internal/user"
+	"hospital-management/pkg/pdf"
+)
+
+var (
+	ErrSummaryExists  = errors.New("admission already has a discharge summary")
+	ErrNotAuthor      = errors.New("only the author can change a draft discharge summary, and only they and the attending doctor can view it")
+	ErrAlreadySigned  = errors.New("discharge summary is signed and can no longer change")
+	ErrNoDiagnoses    = errors.New("a discharge summary needs diagnoses before it can be signed")
+	ErrNotManager     = errors.New("only the author, the attending doctor or an administrator can reassign or discard a draft discharge summary")
+	ErrSummaryChanged = errors.New("discharge summary was updated by someone else; reload it and try again")
+)
+
+//go:embed templates/*
+var templateFiles embed.FS
+
+var (
+	htmlTemplate = template.Must(template.ParseFS(templateFiles, "templates/summary.html"))
+
+	// textTemplate lays out the PDF. Lines starting with "## " are headings
+	// and blank lines separate paragraphs; see pdfView for how entered text
+	// is kept from starting one.
+	textTemplate = texttemplate.Must(texttemplate.ParseFS(templateFiles, "templates/summary.txt"))
+)
+
+type Service struct {
+	repo                *Repository
+	admissionService    *admission.Service
+	encounterService    *encounter.Service
+	problemService      *problem.Service
+	prescriptionService *prescription.Service
+	allergyService      *allergy.Service
+	labService          *lab.Service
+	userService         *user.Service
+	location            *time.Location
+	clinicName          string
+}
+
+func NewService(repo *Repository, admissionService *admission.Service, encounterService *encounter.Service, problemService *problem.Service, prescriptionService *prescription.Service, allergyService *allergy.Service, labService *lab.Service, userService *user.Service, cfg *config.Config) *Service {
+	return &Service{
+		repo:                repo,
+		admissionService:    admissionService,
+		encounterService:    encounterService,
+		problemService:      problemService,
+		prescriptionService: prescriptionService,
+		allergyService:      allergyService,
+		labService:          labService,
+		userService:         userService,
+		location:            cfg.Location,
+		clinicName:          cfg.ClinicName,
+	}
+}
+
+// Draft starts the discharge summary of an admission, written by authorID,
+// who must be an active doctor. It is filled in from the patient record:
+// the coded diagnoses of encounters signed during the stay, or their
+// assessments where they have none, the patient's other active problems,
+// lab tests resulted during the stay, current medications, known allergies
+// and the plan of the last encounter as follow-up.
+func (s *Service) Draft(admissionID, authorID uint) (*models.DischargeSummary, error) {
+	if _, err := s.userService.GetActiveDoctor(authorID); err != nil {
+		return nil, err
+	}
+	stay, err := s.admissionService.GetAdmission(admissionID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &models.DischargeSummary{
+		AdmissionID: stay.ID,
+		PatientID:   stay.PatientID,
+		AuthorID:    authorID,
+		Status:      models.DischargeSummaryDraft,
+	}
+	if err := s.assemble(summary, stay, authorID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(summary); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(summary.ID)
+}
+
+// Get returns a discharge summary. Drafts are only visible to their author
+// and the admission's attending doctor.
+func (s *Service) Get(id, viewerID uint) (*models.DischargeSummary, error) {
+	summary, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return visible(summary, viewerID)
+}
+
+// GetForAdmission returns the discharge summary of an admission.
+func (s *Service) GetForAdmission(admissionID, viewerID uint) (*models.DischargeSummary, error) {
+	summary, err := s.repo.GetByAdmission(admissionID)
+	if err != nil {
+		return nil, err
+	}
+	return visible(summary, viewerID)
+}
+
+// Update edits a draft summary on behalf of its author.
+func (s *Service) Update(id uint, req models.UpdateDischargeSummaryRequest, authorID uint) (*models.DischargeSummary, error) {
+	if _, err := s.getDraft(id, authorID); err != nil {
+		return nil, err
+	}
+
+	changes := map[string]interface{}{}
+	if req.Diagnoses != nil {
+		changes["diagnoses"] = *req.Diagnoses
+	}
+	if req.HospitalCourse != nil {
+		changes["hospital_course"] = *req.HospitalCourse
+	}
+	if req.Procedures != nil {
+		changes["procedures"] = *req.Procedures
+	}
+	if req.Medications != nil {
+		changes["medications"] = *req.Medications
+	}
+	if req.Allergies != nil {
+		changes["allergies"] = *req.Allergies
+	}
+	if req.FollowUp != nil {
+		changes["follow_up"] = *req.FollowUp
+	}
+
+	if len(changes) > 0 {
+		if err := s.repo.Update(id, changes); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.GetByID(id)
+}
+
+// Sign freezes a draft summary. It needs diagnoses.
+func (s *Service) Sign(id, authorID uint) (*models.DischargeSummary, error) {
+	summary, err := s.getDraft(id, authorID)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(summary.Diagnoses) == "" {
+		return nil, ErrNoDiagnoses
+	}
+
+	if err := s.repo.Sign(summary, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(id)
+}
+
+// Reassign hands a draft over to another doctor, for example when its
+// author is away before the patient leaves.
+func (s *Service) Reassign(id uint, req models.ReassignDischargeSummaryRequest, permissions []string, userID uint) (*models.DischargeSummary, error) {
+	if _, err := s.getManaged(id, permissions, userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.userService.GetActiveDoctor(req.AuthorID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(id, map[string]interface{}{"author_id": req.AuthorID}); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(id)
+}
+
+// Discard deletes a draft so that the admission can be summarized again.
+func (s *Service) Discard(id uint, permissions []string, userID uint) (*models.DischargeSummary, error) {
+	summary, err := s.getManaged(id, permissions, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.DeleteDraft(summary.ID); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// RenderHTML renders the summary as a printable HTML page.
+func (s *Service) RenderHTML(id, viewerID uint) (*models.DischargeSummary, []byte, error) {
+	summary, err := s.Get(id, viewerID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, s.view(summary)); err != nil {
+		return nil, nil, err
+	}
+	return summary, buf.Bytes(), nil
+}
+
+// RenderPDF renders the summary as a PDF document.
+func (s *Service) RenderPDF(id, viewerID uint) (*models.DischargeSummary, []byte, error) {
+	summary, err := s.Get(id, viewerID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := textTemplate.Execute(&buf, s.pdfView(summary)); err != nil {
+		return nil, nil, err
+	}
+
+	doc := pdf.New(fmt.Sprintf("%s - Discharge summary", s.clinicName))
+	var paragraph []string
+	flush := func() {
+		if len(paragraph) > 0 {
+			doc.Paragraph(strings.Join(paragraph, "\n"))
+			doc.Space(4)
+			paragraph = nil
+		}
+	}
+	for _, line := range strings.Split(buf.String(), "\n") {
+		switch {
+		case strings.HasPrefix(line, "## "):
+			flush()
+			doc.Heading(strings.TrimPrefix(line, "## "))
+		case strings.TrimSpace(line) == "":
+			flush()
+		default:
+			paragraph = append(paragraph, line)
+		}
+	}
+	flush()
+
+	return summary, doc.Bytes(), nil
+}
+
+// view is the data the summary templates are executed with.
+func (s *Service) view(summary *models.DischargeSummary) map[string]interface{} {
+	view := map[string]interface{}{
+		"Clinic":     s.clinicName,
+		"Summary":    summary,
+		"Admission":  summary.Admission,
+		"Patient":    summary.Admission.Patient,
+		"AdmittedAt": summary.Admission.AdmittedAt.In(s.location),
+		"PrintedAt":  time.Now().In(s.location),
+	}
+	if summary.Admission.DischargedAt != nil {
+		view["DischargedAt"] = summary.Admission.DischargedAt.In(s.location)
+	}
+	return view
+}
+
+// pdfView is view for the PDF layout, with every line of entered text that
+// starts with "#" indented so that it cannot be taken for a heading.
+func (s *Service) pdfView(summary *models.DischargeSummary) map[string]interface{} {
+	escaped := *summary
+	for _, text := range []*string{
+		&escaped.Diagnoses, &escaped.HospitalCourse, &escaped.Procedures,
+		&escaped.Medications, &escaped.Allergies, &escaped.FollowUp,
+		&escaped.Admission.Reason, &escaped.Admission.DischargeDisposition,
+		&escaped.Admission.Patient.FirstName, &escaped.Admission.Patient.LastName,
+		&escaped.Admission.AttendingDoctor.FirstName, &escaped.Admission.AttendingDoctor.LastName,
+		&escaped.Author.FirstName, &escaped.Author.LastName,
+	} {
+		lines := strings.Split(strings.ReplaceAll(*text, "\r", ""), "\n")
+		for i, line := range lines {
+			if strings.HasPrefix(line, "#") {
+				lines[i] = " " + line
+			}
+		}
+		*text = strings.Join(lines, "\n")
+	}
+	return s.view(&escaped)
+}
+
+// assemble fills the summary's sections from the patient record.
+func (s *Service) assemble(summary *models.DischargeSummary, stay *models.Admission, authorID uint) error {
+	end := time.Now()
+	if stay.DischargedAt != nil {
+		end = *stay.DischargedAt
+	}
+	during := func(t time.Time) bool {
+		return !t.Before(stay.AdmittedAt) && !t.After(end)
+	}
+
+	encounters, err := s.encounterService.Timeline(stay.PatientID, authorID)
+	if err != nil {
+		return err
+	}
+	var diagnoses []string
+	coded := map[string]bool{}
+	for _, e := range encounters {
+		if e.Status != models.EncounterSigned || !during(e.EncounterAt) {
+			continue
+		}
+		for _, d := range e.Diagnoses {
+			if coded[d.Code] {
+				continue
+			}
+			coded[d.Code] = true
+			line := fmt.Sprintf("%s %s", d.Code, d.Description)
+			if d.IsPrimary {
+				line += " (principal)"
+			}
+			diagnoses = append(diagnoses, line)
+		}
+		if assessment := strings.TrimSpace(e.Assessment); assessment != "" && len(e.Diagnoses) == 0 {
+			diagnoses = append(diagnoses, fmt.Sprintf("%s: %s", s.date(e.EncounterAt), assessment))
+		}
+		if plan := strings.TrimSpace(e.Plan); plan != "" {
+			summary.FollowUp = plan
+		}
+	}
+
+	problems, err := s.problemService.List(stay.PatientID, models.ProblemFilter{Status: models.ProblemActive})
+	if err != nil {
+		return err
+	}
+	for _, p := range problems {
+		if !coded[p.Code] {
+			diagnoses = append(diagnoses, fmt.Sprintf("%s %s (active problem)", p.Code, p.Description))
+		}
+	}
+	summary.Diagnoses = strings.Join(diagnoses, "\n")
+
+	results, err := s.labService.PatientResults(stay.PatientID)
+	if err != nil {
+		return err
+	}
+	var procedures []string
+	for i := len(results) - 1; i >= 0; i-- {
+		r := results[i]
+		if r.ResultedAt == nil || !during(*r.ResultedAt) {
+			continue
+		}
+		value := r.ValueText
+		if r.Value != nil {
+			value = strings.TrimSpace(fmt.Sprintf("%g %s", *r.Value, r.Unit))
+		}
+		line := fmt.Sprintf("%s: %s %s", s.date(*r.ResultedAt), r.LabTest.Name, value)
+		if r.Flag != "" && r.Flag != models.LabFlagNormal {
+			line += fmt.Sprintf(" (%s)", strings.ReplaceAll(r.Flag, "_", " "))
+		}
+		procedures = append(procedures, line)
+	}
+	summary.Procedures = strings.Join(procedures, "\n")
+
+	medications, err := s.prescriptionService.ActiveMedications(stay.PatientID)
+	if err != nil {
+		return err
+	}
+	var meds []string
+	for _, p := range medications {
+		line := fmt.Sprintf("%s %s %s %s", p.Drug, p.Dose, p.Route, p.Frequency)
+		if p.Instructions != "" {
+			line += " - " + p.Instructions
+		}
+		meds = append(meds, strings.Join(strings.Fields(line), " "))
+	}
+	summary.Medications = strings.Join(meds, "\n")
+
+	allergies, err := s.allergyService.List(stay.PatientID, false)
+	if err != nil {
+		return err
+	}
+	var known []string
+	for _, a := range allergies {
+		if a.Status != models.AllergyActive || a.VerificationStatus == models.AllergyRefuted {
+			continue
+		}
+		line := a.Substance
+		if a.Reaction != "" {
+			line += " - " + a.Reaction
+		}
+		if a.Severity != "" {
+			line += fmt.Sprintf(" (%s)", a.Severity)
+		}
+		known = append(known, line)
+	}
+	if len(known) == 0 {
+		known = append(known, "No known allergies")
+	}
+	summary.Allergies = strings.Join(known, "\n")
+
+	return nil
+}
+
+func (s *Service) getDraft(id, authorID uint) (*models.DischargeSummary, error) {
+	summary, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if summary.AuthorID != authorID {
+		return nil, ErrNotAuthor
+	}
+	if summary.Status != models.DischargeSummaryDraft {
+		return nil, ErrAlreadySigned
+	}
+	return summary, nil
+}
+
+// getManaged returns a draft that userID may reassign or discard: as its
+// author, as the admission's attending doctor or with discharge:manage.
+func (s *Service) getManaged(id uint, permissions []string, userID uint) (*models.DischargeSummary, error) {
+	summary, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if summary.Status != models.DischargeSummaryDraft {
+		return nil, ErrAlreadySigned
+	}
+	if summary.AuthorID != userID && summary.Admission.AttendingDoctorID != userID && !manages(permissions) {
+		return nil, ErrNotManager
+	}
+	return summary, nil
+}
+
+// date formats t as a calendar date in the clinic's time zone.
+func (s *Service) date(t time.Time) string {
+	return t.In(s.location).Format("2006-01-02")
+}
+
+func visible(summary *models.DischargeSummary, viewerID uint) (*models.DischargeSummary, error) {
+	if summary.Status == models.DischargeSummaryDraft && summary.AuthorID != viewerID &&
+		summary.Admission.AttendingDoctorID != viewerID {
+		return nil, ErrNotAuthor
+	}
+	return summary, nil
+}
+
+// manages reports whether permissions include managing other doctors'
+// drafts.
+func manages(permissions []string) bool {
+	for _, p := range permissions {
+		if p == models.PermissionDischargeManage {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,234 @@
+package encounter
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"hospital-management/internal/audit"
+	"hospital-management/internal/models"
+	"hospital-management/internal/patient"
+	"hospital-management/internal/user"
+	"hospital-management/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type Handler struct {
+	service *Service
+	audit   *audit.Service
+}
+
+func NewHandler(service *Service, auditService *audit.Service) *Handler {
+	return &Handler{service: service, audit: auditService}
+}
+
+// encounterDiffIgnored lists fields left out of audit diffs because they
+// change on every write or are loaded from other tables.
+var encounterDiffIgnored = []string{"updated_at", "patient", "doctor", "addenda", "diagnoses"}
+
+func (h *Handler) CreateEncounter(c *gin.Context) {
+	var req models.CreateEncounterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	encounter, err := h.service.Create(req, utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to create encounter", err)
+		return
+	}
+
+	changes, _ := utils.DiffFields(nil, encounter, encounterDiffIgnored...)
+	h.audit.Log(c, audit.Entry{
+		Action:       "encounter.create",
+		ResourceType: "encounter",
+		ResourceID:   encounter.ID,
+		PatientID:    &encounter.PatientID,
+		Changes:      changes,
+	})
+
+	utils.SuccessResponse(c, "Encounter created successfully", encounter)
+}
+
+func (h *Handler) GetEncounter(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid encounter ID", err)
+		return
+	}
+
+	encounter, err := h.service.Get(uint(id), utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Encounter not found", err)
+		return
+	}
+
+	h.audit.Log(c, audit.Entry{
+		Action:       "encounter.read",
+		ResourceType: "encounter",
+		ResourceID:   encounter.ID,
+		PatientID:    &encounter.PatientID,
+	})
+	utils.SuccessResponse(c, "Encounter retrieved successfully", encounter)
+}
+
+func (h *Handler) UpdateEncounter(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid encounter ID", err)
+		return
+	}
+
+	var req models.UpdateEncounterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	before, err := h.service.Get(uint(id), utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Encounter not found", err)
+		return
+	}
+
+	encounter, err := h.service.Update(uint(id), req, utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to update encounter", err)
+		return
+	}
+
+	changes, _ := utils.DiffFields(before, encounter, encounterDiffIgnored...)
+	h.audit.Log(c, audit.Entry{
+		Action:       "encounter.update",
+		ResourceType: "encounter",
+		ResourceID:   encounter.ID,
+		PatientID:    &encounter.PatientID,
+		Changes:      changes,
+	})
+
+	utils.SuccessResponse(c, "Encounter updated successfully", encounter)
+}
+
+func (h *Handler) SignEncounter(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid encounter ID", err)
+		return
+	}
+
+	encounter, err := h.service.Sign(uint(id), utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to sign encounter", err)
+		return
+	}
+
+	h.audit.Log(c, audit.Entry{
+		Action:       "encounter.sign",
+		ResourceType: "encounter",
+		ResourceID:   encounter.ID,
+		PatientID:    &encounter.PatientID,
+	})
+
+	utils.SuccessResponse(c, "Encounter signed successfully", encounter)
+}
+
+func (h *Handler) DeleteEncounter(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid encounter ID", err)
+		return
+	}
+
+	encounter, err := h.service.DeleteDraft(uint(id), utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to delete encounter", err)
+		return
+	}
+
+	changes, _ := utils.DiffFields(encounter, nil, encounterDiffIgnored...)
+	h.audit.Log(c, audit.Entry{
+		Action:       "encounter.delete",
+		ResourceType: "encounter",
+		ResourceID:   encounter.ID,
+		PatientID:    &encounter.PatientID,
+		Changes:      changes,
+	})
+
+	utils.SuccessResponse(c, "Encounter deleted successfully", nil)
+}
+
+func (h *Handler) AddAddendum(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid encounter ID", err)
+		return
+	}
+
+	var req models.CreateAddendumRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	addendum, err := h.service.AddAddendum(uint(id), req, utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to add addendum", err)
+		return
+	}
+
+	h.audit.Log(c, audit.Entry{
+		Action:       "encounter.addendum",
+		ResourceType: "encounter",
+		ResourceID:   addendum.EncounterID,
+		PatientID:    &addendum.PatientID,
+		Details:      gin.H{"addendum_id": addendum.ID},
+	})
+
+	utils.SuccessResponse(c, "Addendum added successfully", addendum)
+}
+
+// GetPatientTimeline returns the patient's encounters in chronological order.
+func (h *Handler) GetPatientTimeline(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	encounters, err := h.service.Timeline(uint(id), utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to get encounters", err)
+		return
+	}
+
+	patientID := uint(id)
+	ids := make([]uint, 0, len(encounters))
+	for _, e := range encounters {
+		ids = append(ids, e.ID)
+	}
+	h.audit.Log(c, audit.Entry{
+		Action:       "encounter.timeline",
+		ResourceType: "encounter",
+		PatientID:    &patientID,
+		Details:      gin.H{"encounter_ids": ids},
+	})
+
+	utils.SuccessResponse(c, "Encounters retrieved successfully", encounters)
+}
+
+// statusFor maps service errors to HTTP status codes.
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrNotAuthor), errors.Is(err, user.ErrNotDoctor):
+		return http.StatusForbidden
+	case errors.Is(err, ErrAlreadySigned), errors.Is(err, ErrNotSigned), errors.Is(err, ErrEncounterChanged):
+		return http.StatusConflict
+	case errors.Is(err, ErrEmptyNote), errors.Is(err, ErrVisitMismatch), errors.Is(err, ErrInFuture),
+		errors.Is(err, patient.ErrPatientMerged):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,103 @@
+package encounter
+
+import (
+	"time"
+	"hospital-management/internal/models"
+	"hospital-management/pkg/utils"
+	"gorm.io/gorm"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// withDetails preloads what an encounter view shows.
+func withDetails(db *gorm.DB) *gorm.DB {
+	return db.Preload("Patient", utils.WithDeleted).
+		Preload("Doctor", utils.WithDeleted).
+		Preload("Addenda", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at, id")
+		}).
+		Preload("Addenda.Author", utils.WithDeleted).
+		Preload("Diagnoses", func(db *gorm.DB) *gorm.DB {
+			return db.Order("is_primary DESC, id")
+		})
+}
+
+func (r *Repository) Create(encounter *models.Encounter) error {
+	return r.db.Omit("Patient", "Doctor", "Addenda", "Diagnoses").Create(encounter).Error
+}
+
+// Update writes changes to an encounter that is still a draft. It returns
+// ErrEncounterChanged when the encounter was signed or deleted first.
+func (r *Repository) Update(id uint, changes map[string]interface{}) error {
+	return r.updateDraft(r.db.Where("id = ? AND status = ?", id, models.EncounterDraft), changes)
+}
+
+// Sign signs a draft encounter as long as its note is unchanged since it was
+// read, so that what was checked before signing is what gets signed. It
+// returns ErrEncounterChanged otherwise.
+func (r *Repository) Sign(encounter *models.Encounter, signedBy uint) error {
+	return r.updateDraft(r.db.Where("id = ? AND status = ? AND updated_at = ?",
+		encounter.ID, models.EncounterDraft, encounter.UpdatedAt), map[string]interface{}{
+		"status":       models.EncounterSigned,
+		"signed_at":    time.Now(),
+		"signed_by_id": signedBy,
+	})
+}
+
+func (r *Repository) updateDraft(query *gorm.DB, changes map[string]interface{}) error {
+	result := query.Model(&models.Encounter{}).Updates(changes)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrEncounterChanged
+	}
+	return nil
+}
+
+// DeleteDraft deletes an encounter that has not been signed, together with
+// its diagnoses. The problems they point to stay on the problem list.
+func (r *Repository) DeleteDraft(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("encounter_id IN (?)",
+			tx.Model(&models.Encounter{}).Select("id").Where("id = ? AND status = ?", id, models.EncounterDraft)).
+			Delete(&models.EncounterDiagnosis{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("status = ?", models.EncounterDraft).Delete(&models.Encounter{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func (r *Repository) GetByID(id uint) (*models.Encounter, error) {
+	var encounter models.Encounter
+	err := withDetails(r.db).First(&encounter, id).Error
+	return &encounter, err
+}
+
+func (r *Repository) CreateAddendum(addendum *models.EncounterAddendum) error {
+	return r.db.Omit("Author").Create(addendum).Error
+}
+
+// ListForPatient returns a patient's signed encounters and the drafts
+// written by authorID, oldest first.
+func (r *Repository) ListForPatient(patientID, authorID uint) ([]models.Encounter, error) {
+	var encounters []models.Encounter
+	err := withDetails(r.db).
+		Where("patient_id = ? AND (status = ? OR doctor_id = ?)", patientID, models.EncounterSigned, authorID).
+		Order("encounter_at, id").
+		Find(&encounters).Error
+	return encounters, err
+}
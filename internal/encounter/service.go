@@ -0,0 +1,204 @@
+package encounter
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"hospital-management/internal/models"
+	"hospital-management/internal/patient"
+	"hospital-management/internal/queue"
+	"hospital-management/internal/user"
+)
+
+var (
+	ErrNotAuthor        = errors.New("only the author can change or view a draft encounter")
+	ErrAlreadySigned    = errors.New("encounter is signed; add an addendum instead")
+	ErrNotSigned        = errors.New("addenda can only be added to signed encounters")
+	ErrEmptyNote        = errors.New("an encounter needs an assessment or plan before it can be signed")
+	ErrVisitMismatch    = errors.New("visit belongs to another patient")
+	ErrInFuture         = errors.New("encounter_at cannot be in the future")
+	ErrEncounterChanged = errors.New("encounter was updated by someone else; reload it and try again")
+)
+
+type Service struct {
+	repo           *Repository
+	patientService *patient.Service
+	userService    *user.Service
+	queueService   *queue.Service
+}
+
+func NewService(repo *Repository, patientService *patient.Service, userService *user.Service, queueService *queue.Service) *Service {
+	return &Service{
+		repo:           repo,
+		patientService: patientService,
+		userService:    userService,
+		queueService:   queueService,
+	}
+}
+
+// Create opens a draft encounter written by doctorID.
+func (s *Service) Create(req models.CreateEncounterRequest, doctorID uint) (*models.Encounter, error) {
+	if _, err := s.userService.GetActiveDoctor(doctorID); err != nil {
+		return nil, err
+	}
+	if _, err := s.patientService.GetActivePatient(req.PatientID); err != nil {
+		return nil, err
+	}
+
+	encounter := &models.Encounter{
+		PatientID:   req.PatientID,
+		DoctorID:    doctorID,
+		EncounterAt: time.Now(),
+		Reason:      strings.TrimSpace(req.Reason),
+		Status:      models.EncounterDraft,
+		Subjective:  req.Subjective,
+		Objective:   req.Objective,
+		Assessment:  req.Assessment,
+		Plan:        req.Plan,
+	}
+	if req.EncounterAt != nil {
+		if req.EncounterAt.After(time.Now()) {
+			return nil, ErrInFuture
+		}
+		encounter.EncounterAt = req.EncounterAt.UTC()
+	}
+
+	if req.VisitID != 0 {
+		visit, err := s.queueService.GetVisit(req.VisitID)
+		if err != nil {
+			return nil, err
+		}
+		if visit.PatientID != req.PatientID {
+			return nil, ErrVisitMismatch
+		}
+		encounter.VisitID = &visit.ID
+	}
+
+	if err := s.repo.Create(encounter); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(encounter.ID)
+}
+
+// Get returns an encounter. Drafts are only visible to their author.
+func (s *Service) Get(id, viewerID uint) (*models.Encounter, error) {
+	encounter, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if encounter.Status == models.EncounterDraft && encounter.DoctorID != viewerID {
+		return nil, ErrNotAuthor
+	}
+	return encounter, nil
+}
+
+// Update edits the note of a draft encounter.
+func (s *Service) Update(id uint, req models.UpdateEncounterRequest, authorID uint) (*models.Encounter, error) {
+	if _, err := s.getDraft(id, authorID); err != nil {
+		return nil, err
+	}
+
+	changes := map[string]interface{}{}
+	if req.EncounterAt != nil {
+		if req.EncounterAt.After(time.Now()) {
+			return nil, ErrInFuture
+		}
+		changes["encounter_at"] = req.EncounterAt.UTC()
+	}
+	if req.Reason != nil {
+		changes["reason"] = strings.TrimSpace(*req.Reason)
+	}
+	if req.Subjective != nil {
+		changes["subjective"] = *req.Subjective
+	}
+	if req.Objective != nil {
+		changes["objective"] = *req.Objective
+	}
+	if req.Assessment != nil {
+		changes["assessment"] = *req.Assessment
+	}
+	if req.Plan != nil {
+		changes["plan"] = *req.Plan
+	}
+
+	if len(changes) > 0 {
+		if err := s.repo.Update(id, changes); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.GetByID(id)
+}
+
+// Sign freezes a draft encounter's note.
+func (s *Service) Sign(id, authorID uint) (*models.Encounter, error) {
+	encounter, err := s.getDraft(id, authorID)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(encounter.Assessment) == "" && strings.TrimSpace(encounter.Plan) == "" {
+		return nil, ErrEmptyNote
+	}
+
+	if err := s.repo.Sign(encounter, authorID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(id)
+}
+
+// DeleteDraft discards a draft encounter. Signed encounters are part of the
+// record and cannot be deleted.
+func (s *Service) DeleteDraft(id, authorID uint) (*models.Encounter, error) {
+	encounter, err := s.getDraft(id, authorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.DeleteDraft(id); err != nil {
+		return nil, err
+	}
+	return encounter, nil
+}
+
+// AddAddendum appends to a signed encounter.
+func (s *Service) AddAddendum(id uint, req models.CreateAddendumRequest, authorID uint) (*models.EncounterAddendum, error) {
+	encounter, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if encounter.Status != models.EncounterSigned {
+		return nil, ErrNotSigned
+	}
+
+	addendum := &models.EncounterAddendum{
+		EncounterID: encounter.ID,
+		PatientID:   encounter.PatientID,
+		AuthorID:    authorID,
+		Content:     req.Content,
+	}
+	if err := s.repo.CreateAddendum(addendum); err != nil {
+		return nil, err
+	}
+	return addendum, nil
+}
+
+// Timeline returns a patient's encounters in chronological order: all signed
+// encounters and the viewer's own drafts.
+func (s *Service) Timeline(patientID, viewerID uint) ([]models.Encounter, error) {
+	if _, err := s.patientService.GetPatientByID(patientID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListForPatient(patientID, viewerID)
+}
+
+func (s *Service) getDraft(id, authorID uint) (*models.Encounter, error) {
+	encounter, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if encounter.DoctorID != authorID {
+		return nil, ErrNotAuthor
+	}
+	if encounter.Status != models.EncounterDraft {
+		return nil, ErrAlreadySigned
+	}
+	return encounter, nil
+}
@@ -0,0 +1,131 @@
+// Package icd10 looks up and searches ICD-10 diagnosis codes. The code table
+// is a local file, by default the one bundled with the server; nothing is
+// looked up over the network.
+package icd10
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+//go:embed data/codes.tsv
+var defaultCodes []byte
+
+// codePattern matches a normalized ICD-10 code: a category of a letter and
+// two characters, optionally followed by a dot and up to four more.
+var codePattern = regexp.MustCompile(`^[A-Z][0-9][0-9A-Z](\.[0-9A-Z]{1,4})?$`)
+
+// Code is an entry of the code table.
+type Code struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
+type Catalog struct {
+	codes  []Code
+	byCode map[string]Code
+}
+
+// Load returns a catalog of the codes in the file at path, or of the
+// bundled code table when path is empty. Each line holds a code and its
+// description separated by a tab; blank lines and lines starting with #
+// are skipped.
+func Load(path string) (*Catalog, error) {
+	raw := defaultCodes
+	if path != "" {
+		var err error
+		if raw, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("icd10: reading code table: %w", err)
+		}
+	}
+
+	c := &Catalog{byCode: make(map[string]Code)}
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		code, description, ok := strings.Cut(text, "\t")
+		code, description = Normalize(code), strings.TrimSpace(description)
+		if !ok || !codePattern.MatchString(code) || description == "" {
+			return nil, fmt.Errorf("icd10: line %d is invalid", line)
+		}
+		if _, dup := c.byCode[code]; dup {
+			return nil, fmt.Errorf("icd10: line %d repeats code %s", line, code)
+		}
+		entry := Code{Code: code, Description: description}
+		c.codes = append(c.codes, entry)
+		c.byCode[code] = entry
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("icd10: reading code table: %w", err)
+	}
+
+	sort.Slice(c.codes, func(i, j int) bool { return c.codes[i].Code < c.codes[j].Code })
+	return c, nil
+}
+
+// Normalize writes code the way the catalog stores it: upper case, with a
+// dot after the category whether or not one was typed.
+func Normalize(code string) string {
+	code = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), ".", ""))
+	if len(code) > 3 {
+		code = code[:3] + "." + code[3:]
+	}
+	return code
+}
+
+// Lookup returns the catalog entry for code, which is normalized first.
+func (c *Catalog) Lookup(code string) (Code, bool) {
+	entry, ok := c.byCode[Normalize(code)]
+	return entry, ok
+}
+
+// Search returns up to limit codes matching query, which is either the
+// start of a code or words that must all appear in the description. Codes
+// matching by code come first, then in code order.
+func (c *Catalog) Search(query string, limit int) []Code {
+	query = strings.TrimSpace(query)
+	if query == "" || limit <= 0 {
+		return []Code{}
+	}
+
+	prefix := Normalize(query)
+	words := strings.Fields(strings.ToLower(query))
+
+	var byCode, byDescription []Code
+	for _, entry := range c.codes {
+		if strings.HasPrefix(entry.Code, prefix) {
+			byCode = append(byCode, entry)
+			continue
+		}
+		if containsAll(strings.ToLower(entry.Description), words) {
+			byDescription = append(byDescription, entry)
+		}
+	}
+
+	matches := append(byCode, byDescription...)
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	if matches == nil {
+		return []Code{}
+	}
+	return matches
+}
+
+func containsAll(text string, words []string) bool {
+	for _, word := range words {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
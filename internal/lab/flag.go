@@ -0,0 +1,73 @@
+package lab
+
+import (
+	"time"
+	"hospital-management/internal/models"
+)
+
+// ageInDays returns how many whole days old a person born on dateOfBirth is
+// at the given time.
+func ageInDays(dateOfBirth, at time.Time) int {
+	born := time.Date(dateOfBirth.Year(), dateOfBirth.Month(), dateOfBirth.Day(), 0, 0, 0, 0, time.UTC)
+	day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+	return int(day.Sub(born).Hours() / 24)
+}
+
+// referenceRange picks the range of a test that applies to a patient of the
+// given sex and age. A range for the patient's sex is preferred over one for
+// any sex, and of those the one with the highest lower age bound. Patients
+// whose sex is neither male nor female only match ranges for any sex. It
+// returns nil when no range applies.
+func referenceRange(ranges []models.LabReferenceRange, sex string, ageDays int) *models.LabReferenceRange {
+	var best *models.LabReferenceRange
+	for i := range ranges {
+		r := &ranges[i]
+		if r.Sex != "" && r.Sex != sex {
+			continue
+		}
+		if ageDays < r.MinAgeDays || (r.MaxAgeDays != nil && ageDays >= *r.MaxAgeDays) {
+			continue
+		}
+		if best == nil ||
+			(r.Sex != "" && best.Sex == "") ||
+			(r.Sex == best.Sex && r.MinAgeDays > best.MinAgeDays) {
+			best = r
+		}
+	}
+	return best
+}
+
+// flag grades value against r. Critical limits take precedence over the
+// normal range; a bound that is not set is not checked.
+func flag(value float64, r *models.LabReferenceRange) string {
+	switch {
+	case r.CriticalLow != nil && value < *r.CriticalLow:
+		return models.LabFlagCriticalLow
+	case r.CriticalHigh != nil && value > *r.CriticalHigh:
+		return models.LabFlagCriticalHigh
+	case r.Low != nil && value < *r.Low:
+		return models.LabFlagLow
+	case r.High != nil && value > *r.High:
+		return models.LabFlagHigh
+	}
+	return models.LabFlagNormal
+}
+
+// applyRange copies onto result the range that applies to the patient and
+// flags its value. Qualitative results and results without an applicable
+// range are left unflagged.
+func applyRange(result *models.LabResult, ranges []models.LabReferenceRange, patient *models.Patient, at time.Time) {
+	result.ReferenceLow = nil
+	result.ReferenceHigh = nil
+	result.Flag = ""
+
+	r := referenceRange(ranges, patient.Gender, ageInDays(patient.DateOfBirth, at))
+	if r == nil {
+		return
+	}
+	result.ReferenceLow = r.Low
+	result.ReferenceHigh = r.High
+	if result.Value != nil {
+		result.Flag = flag(*result.Value, r)
+	}
+}
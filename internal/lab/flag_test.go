@@ -0,0 +1,159 @@
+package lab
+
+import (
+	"testing"
+	"time"
+	"hospital-management/internal/models"
+)
+
+// adultAgeDays matches the age from which the seeded catalogue applies adult
+// ranges.
+const adultAgeDays = 18*365 + 4
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestAgeInDays(t *testing.T) {
+	tests := []struct {
+		name     string
+		born, at time.Time
+		want     int
+	}{
+		{"day of birth", date(2026, time.March, 1), time.Date(2026, time.March, 1, 23, 59, 0, 0, time.UTC), 0},
+		{"next day", date(2026, time.March, 1), time.Date(2026, time.March, 2, 0, 1, 0, 0, time.UTC), 1},
+		{"18th birthday over four leap days", date(2008, time.March, 1), date(2026, time.March, 1), adultAgeDays},
+		{"18th birthday over five leap days", date(2008, time.February, 28), date(2026, time.February, 28), adultAgeDays + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ageInDays(tt.born, tt.at); got != tt.want {
+				t.Errorf("ageInDays = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReferenceRange(t *testing.T) {
+	elderly := 65*365 + 16
+	ranges := []models.LabReferenceRange{
+		{ID: 1},
+		{ID: 2, MinAgeDays: 28, MaxAgeDays: intPtr(adultAgeDays)},
+		{ID: 3, Sex: "male", MinAgeDays: adultAgeDays},
+		{ID: 4, Sex: "female", MinAgeDays: adultAgeDays},
+		{ID: 5, MinAgeDays: elderly},
+		{ID: 6, Sex: "female", MaxAgeDays: intPtr(28)},
+	}
+
+	tests := []struct {
+		name    string
+		sex     string
+		ageDays int
+		want    uint
+	}{
+		{"newborn boy", "male", 0, 1},
+		{"newborn girl", "female", 0, 6},
+		{"girl at the end of the newborn range", "female", 28, 2},
+		{"child", "male", 10 * 365, 2},
+		{"day before the adult range", "female", adultAgeDays - 1, 2},
+		{"first day of the adult range, male", "male", adultAgeDays, 3},
+		{"first day of the adult range, female", "female", adultAgeDays, 4},
+		{"adult of another gender", "other", 30 * 365, 1},
+		{"adult with no gender recorded", "", 30 * 365, 1},
+		{"elderly of another gender", "other", elderly, 5},
+		{"sex preferred over a later age range", "male", elderly, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := referenceRange(ranges, tt.sex, tt.ageDays)
+			if got == nil {
+				t.Fatalf("referenceRange = nil, want range %d", tt.want)
+			}
+			if got.ID != tt.want {
+				t.Errorf("referenceRange = range %d, want range %d", got.ID, tt.want)
+			}
+		})
+	}
+
+	if got := referenceRange(ranges[2:4], "other", 30*365); got != nil {
+		t.Errorf("referenceRange with only sex-specific ranges = range %d, want nil", got.ID)
+	}
+}
+
+func TestApplyRange(t *testing.T) {
+	// Haemoglobin in g/dL, as seeded.
+	ranges := []models.LabReferenceRange{
+		{MaxAgeDays: intPtr(adultAgeDays), Low: floatPtr(11.0), High: floatPtr(14.5), CriticalLow: floatPtr(7.0), CriticalHigh: floatPtr(20.0)},
+		{Sex: "male", MinAgeDays: adultAgeDays, Low: floatPtr(13.5), High: floatPtr(17.5), CriticalLow: floatPtr(7.0), CriticalHigh: floatPtr(20.0)},
+		{Sex: "female", MinAgeDays: adultAgeDays, Low: floatPtr(12.0), High: floatPtr(15.5), CriticalLow: floatPtr(7.0), CriticalHigh: floatPtr(20.0)},
+	}
+	born := date(2008, time.March, 1)
+	adult := date(2026, time.March, 1)
+	child := date(2026, time.February, 28)
+
+	tests := []struct {
+		name     string
+		gender   string
+		at       time.Time
+		value    *float64
+		ranges   []models.LabReferenceRange
+		wantLow  *float64
+		wantHigh *float64
+		wantFlag string
+	}{
+		{"man below his range", "male", adult, floatPtr(13.0), ranges, floatPtr(13.5), floatPtr(17.5), models.LabFlagLow},
+		{"woman inside her range", "female", adult, floatPtr(13.0), ranges, floatPtr(12.0), floatPtr(15.5), models.LabFlagNormal},
+		{"day before 18th birthday", "male", child, floatPtr(13.0), ranges, floatPtr(11.0), floatPtr(14.5), models.LabFlagNormal},
+		{"above the high bound", "female", child, floatPtr(14.6), ranges, floatPtr(11.0), floatPtr(14.5), models.LabFlagHigh},
+		{"on the high bound", "female", child, floatPtr(14.5), ranges, floatPtr(11.0), floatPtr(14.5), models.LabFlagNormal},
+		{"critically low", "male", adult, floatPtr(6.9), ranges, floatPtr(13.5), floatPtr(17.5), models.LabFlagCriticalLow},
+		{"critically high", "female", adult, floatPtr(20.1), ranges, floatPtr(12.0), floatPtr(15.5), models.LabFlagCriticalHigh},
+		{"qualitative result", "male", adult, nil, ranges, floatPtr(13.5), floatPtr(17.5), ""},
+		{"adult of another gender", "other", adult, floatPtr(13.0), ranges, nil, nil, ""},
+		{"unset bounds are not checked", "other", adult, floatPtr(0.1),
+			[]models.LabReferenceRange{{High: floatPtr(5.0)}}, nil, floatPtr(5.0), models.LabFlagNormal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &models.LabResult{
+				Value:         tt.value,
+				ReferenceLow:  floatPtr(1),
+				ReferenceHigh: floatPtr(2),
+				Flag:          models.LabFlagHigh,
+			}
+			patient := &models.Patient{Gender: tt.gender, DateOfBirth: born}
+
+			applyRange(result, tt.ranges, patient, tt.at)
+
+			if !equalBound(result.ReferenceLow, tt.wantLow) || !equalBound(result.ReferenceHigh, tt.wantHigh) {
+				t.Errorf("reference range = %v–%v, want %v–%v",
+					bound(result.ReferenceLow), bound(result.ReferenceHigh), bound(tt.wantLow), bound(tt.wantHigh))
+			}
+			if result.Flag != tt.wantFlag {
+				t.Errorf("flag = %q, want %q", result.Flag, tt.wantFlag)
+			}
+		})
+	}
+}
+
+func equalBound(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func bound(v *float64) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
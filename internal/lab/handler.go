@@ -0,0 +1,392 @@
+package lab
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"hospital-management/internal/audit"
+	"hospital-management/internal/encounter"
+	"hospital-management/internal/models"
+	"hospital-management/internal/patient"
+	"hospital-management/internal/user"
+	"hospital-management/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type Handler struct {
+	service *Service
+	audit   *audit.Service
+}
+
+func NewHandler(service *Service, auditService *audit.Service) *Handler {
+	return &Handler{service: service, audit: auditService}
+}
+
+// testDiffIgnored and resultDiffIgnored list fields left out of audit diffs
+// because they change on every write or are loaded from other tables.
+var (
+	testDiffIgnored   = []string{"updated_at"}
+	resultDiffIgnored = []string{"updated_at", "lab_test"}
+)
+
+// ListTests returns the lab catalog. Pass include_inactive=true to include
+// retired tests.
+func (h *Handler) ListTests(c *gin.Context) {
+	includeInactive := c.Query("include_inactive") == "true"
+
+	tests, err := h.service.ListTests(includeInactive)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get lab tests", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Lab tests retrieved successfully", tests)
+}
+
+func (h *Handler) CreateTest(c *gin.Context) {
+	var req models.CreateLabTestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	test, err := h.service.CreateTest(req)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to create lab test", err)
+		return
+	}
+
+	changes, _ := utils.DiffFields(nil, test, testDiffIgnored...)
+	h.audit.Log(c, audit.Entry{
+		Action:       "lab_test.create",
+		ResourceType: "lab_test",
+		ResourceID:   test.ID,
+		Changes:      changes,
+	})
+
+	utils.SuccessResponse(c, "Lab test created successfully", test)
+}
+
+func (h *Handler) UpdateTest(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid lab test ID", err)
+		return
+	}
+
+	var req models.UpdateLabTestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	before, err := h.service.GetTest(uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Lab test not found", err)
+		return
+	}
+
+	test, err := h.service.UpdateTest(uint(id), req)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to update lab test", err)
+		return
+	}
+
+	changes, _ := utils.DiffFields(before, test, testDiffIgnored...)
+	h.audit.Log(c, audit.Entry{
+		Action:       "lab_test.update",
+		ResourceType: "lab_test",
+		ResourceID:   test.ID,
+		Changes:      changes,
+	})
+
+	utils.SuccessResponse(c, "Lab test updated successfully", test)
+}
+
+func (h *Handler) CreateOrder(c *gin.Context) {
+	var req models.CreateLabOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	order, err := h.service.Order(req, utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to order lab tests", err)
+		return
+	}
+
+	h.audit.Log(c, audit.Entry{
+		Action:       "lab_order.create",
+		ResourceType: "lab_order",
+		ResourceID:   order.ID,
+		PatientID:    &order.PatientID,
+		Details:      gin.H{"test_ids": testIDs(order), "priority": order.Priority},
+	})
+
+	utils.SuccessResponse(c, "Lab tests ordered successfully", order)
+}
+
+// GetOrders lists lab orders. Filtered by status, it is the lab's worklist.
+func (h *Handler) GetOrders(c *gin.Context) {
+	var filter models.LabOrderFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query", err)
+		return
+	}
+
+	orders, meta, err := h.service.ListOrders(filter)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get lab orders", err)
+		return
+	}
+
+	ids := make([]uint, 0, len(orders))
+	for _, o := range orders {
+		ids = append(ids, o.ID)
+	}
+	h.audit.Log(c, audit.Entry{
+		Action:       "lab_order.list",
+		ResourceType: "lab_order",
+		Details:      gin.H{"lab_order_ids": ids},
+	})
+	utils.PaginatedResponse(c, "Lab orders retrieved successfully", orders, meta)
+}
+
+func (h *Handler) GetOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid lab order ID", err)
+		return
+	}
+
+	order, err := h.service.GetOrder(uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Lab order not found", err)
+		return
+	}
+
+	h.audit.Log(c, audit.Entry{
+		Action:       "lab_order.read",
+		ResourceType: "lab_order",
+		ResourceID:   order.ID,
+		PatientID:    &order.PatientID,
+	})
+	utils.SuccessResponse(c, "Lab order retrieved successfully", order)
+}
+
+func (h *Handler) CancelOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid lab order ID", err)
+		return
+	}
+
+	var req models.CancelLabOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	order, err := h.service.Cancel(uint(id), req.Reason)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to cancel lab order", err)
+		return
+	}
+
+	h.audit.Log(c, audit.Entry{
+		Action:       "lab_order.cancel",
+		ResourceType: "lab_order",
+		ResourceID:   order.ID,
+		PatientID:    &order.PatientID,
+		Details:      gin.H{"reason": req.Reason},
+	})
+
+	utils.SuccessResponse(c, "Lab order cancelled successfully", order)
+}
+
+// EnterResult records or corrects the result of one test of an order.
+func (h *Handler) EnterResult(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid lab order ID", err)
+		return
+	}
+	resultID, err := strconv.ParseUint(c.Param("resultId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid result ID", err)
+		return
+	}
+
+	var req models.EnterLabResultRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	before, err := h.service.GetOrder(uint(orderID))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Lab order not found", err)
+		return
+	}
+
+	order, result, err := h.service.EnterResult(uint(orderID), uint(resultID), req, utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to enter lab result", err)
+		return
+	}
+
+	var previous *models.LabResult
+	for i := range before.Results {
+		if before.Results[i].ID == result.ID {
+			previous = &before.Results[i]
+		}
+	}
+	action := "lab_result.enter"
+	if result.Status == models.LabResultCorrected {
+		action = "lab_result.correct"
+	}
+	changes, _ := utils.DiffFields(previous, result, resultDiffIgnored...)
+	h.audit.Log(c, audit.Entry{
+		Action:       action,
+		ResourceType: "lab_result",
+		ResourceID:   result.ID,
+		PatientID:    &result.PatientID,
+		Changes:      changes,
+	})
+
+	utils.SuccessResponse(c, "Lab result saved successfully", order)
+}
+
+// AcknowledgeOrder records that the doctor has reviewed the order's results.
+func (h *Handler) AcknowledgeOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid lab order ID", err)
+		return
+	}
+
+	order, err := h.service.Acknowledge(uint(id), utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to acknowledge lab results", err)
+		return
+	}
+
+	h.audit.Log(c, audit.Entry{
+		Action:       "lab_order.acknowledge",
+		ResourceType: "lab_order",
+		ResourceID:   order.ID,
+		PatientID:    &order.PatientID,
+	})
+
+	utils.SuccessResponse(c, "Lab results acknowledged successfully", order)
+}
+
+// GetPatientResults returns the patient's lab results, newest first.
+func (h *Handler) GetPatientResults(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	results, err := h.service.PatientResults(uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to get lab results", err)
+		return
+	}
+
+	patientID := uint(id)
+	h.audit.Log(c, audit.Entry{
+		Action:       "lab_result.list",
+		ResourceType: "lab_result",
+		PatientID:    &patientID,
+		Details:      gin.H{"lab_result_ids": resultIDs(results)},
+	})
+	utils.SuccessResponse(c, "Lab results retrieved successfully", results)
+}
+
+// GetPatientTrend returns the patient's results for the test given by
+// test_id over time.
+func (h *Handler) GetPatientTrend(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+	testID, err := strconv.ParseUint(c.Query("test_id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid lab test ID", err)
+		return
+	}
+
+	trend, err := h.service.Trend(uint(id), uint(testID))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to get lab trend", err)
+		return
+	}
+
+	ids := make([]uint, 0, len(trend.Points))
+	for _, p := range trend.Points {
+		ids = append(ids, p.ResultID)
+	}
+	patientID := uint(id)
+	h.audit.Log(c, audit.Entry{
+		Action:       "lab_result.trend",
+		ResourceType: "lab_result",
+		PatientID:    &patientID,
+		Details:      gin.H{"lab_test_id": testID, "lab_result_ids": ids},
+	})
+	utils.SuccessResponse(c, "Lab trend retrieved successfully", trend)
+}
+
+// GetDoctorResults returns the results of the doctor's orders that are
+// waiting for acknowledgement.
+func (h *Handler) GetDoctorResults(c *gin.Context) {
+	results, err := h.service.Unacknowledged(utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get lab results", err)
+		return
+	}
+
+	h.audit.Log(c, audit.Entry{
+		Action:       "lab_result.list",
+		ResourceType: "lab_result",
+		Details:      gin.H{"lab_result_ids": resultIDs(results)},
+	})
+	utils.SuccessResponse(c, "Lab results retrieved successfully", results)
+}
+
+func testIDs(order *models.LabOrder) []uint {
+	ids := make([]uint, 0, len(order.Results))
+	for _, r := range order.Results {
+		ids = append(ids, r.LabTestID)
+	}
+	return ids
+}
+
+func resultIDs(results []models.LabResult) []uint {
+	ids := make([]uint, 0, len(results))
+	for _, r := range results {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
+
+// statusFor maps service errors to HTTP status codes.
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, ErrResultNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, user.ErrNotDoctor), errors.Is(err, encounter.ErrNotAuthor):
+		return http.StatusForbidden
+	case errors.Is(err, ErrDuplicateCode), errors.Is(err, ErrOrderCancelled), errors.Is(err, ErrOrderStarted),
+		errors.Is(err, ErrCorrectionReason), errors.Is(err, ErrNothingToAcknowledge):
+		return http.StatusConflict
+	case errors.Is(err, ErrInvalidRange), errors.Is(err, ErrTestUnavailable), errors.Is(err, ErrEmptyResult),
+		errors.Is(err, ErrEncounterMismatch), errors.Is(err, patient.ErrPatientMerged):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,238 @@
+package lab
+
+import (
+	"time"
+	"hospital-management/internal/models"
+	"hospital-management/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// withDetails preloads what a lab order view shows.
+func withDetails(db *gorm.DB) *gorm.DB {
+	return db.Preload("Patient", utils.WithDeleted).
+		Preload("OrderedBy", utils.WithDeleted).
+		Preload("Results", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id")
+		}).
+		Preload("Results.LabTest")
+}
+
+// conflictErrors maps the lab test code index to ErrDuplicateCode.
+var conflictErrors = map[string]error{
+	"idx_lab_tests_code": ErrDuplicateCode,
+}
+
+func (r *Repository) ListTests(includeInactive bool) ([]models.LabTest, error) {
+	query := r.db.Preload("ReferenceRanges", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sex, min_age_days")
+	})
+	if !includeInactive {
+		query = query.Where("is_active = ?", true)
+	}
+
+	var tests []models.LabTest
+	err := query.Order("name").Find(&tests).Error
+	return tests, err
+}
+
+func (r *Repository) GetTest(id uint) (*models.LabTest, error) {
+	var test models.LabTest
+	err := r.db.Preload("ReferenceRanges", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sex, min_age_days")
+	}).First(&test, id).Error
+	return &test, err
+}
+
+// FindTests returns the tests with the given IDs.
+func (r *Repository) FindTests(ids []uint) ([]models.LabTest, error) {
+	var tests []models.LabTest
+	err := r.db.Where("id IN ?", ids).Find(&tests).Error
+	return tests, err
+}
+
+// CreateTest saves a catalog test together with its reference ranges.
+func (r *Repository) CreateTest(test *models.LabTest) error {
+	return utils.TranslateConflict(r.db.Create(test).Error, conflictErrors)
+}
+
+// UpdateTest saves a catalog test. When ranges is not nil it replaces the
+// test's reference ranges.
+func (r *Repository) UpdateTest(test *models.LabTest, ranges []models.LabReferenceRange) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("ReferenceRanges").Save(test).Error; err != nil {
+			return utils.TranslateConflict(err, conflictErrors)
+		}
+		if ranges == nil {
+			return nil
+		}
+
+		if err := tx.Where("lab_test_id = ?", test.ID).Delete(&models.LabReferenceRange{}).Error; err != nil {
+			return err
+		}
+		for i := range ranges {
+			ranges[i].LabTestID = test.ID
+		}
+		if len(ranges) == 0 {
+			return nil
+		}
+		return tx.Create(&ranges).Error
+	})
+}
+
+// CreateOrder saves an order together with its pending results.
+func (r *Repository) CreateOrder(order *models.LabOrder) error {
+	return r.db.Omit("Patient", "OrderedBy", "Results.LabTest").Create(order).Error
+}
+
+// CancelOrder cancels the order with the given ID. The order is locked so
+// that a result entered at the same time sees the cancellation or blocks it.
+// check is called with the locked order before it is cancelled.
+func (r *Repository) CancelOrder(id uint, reason string, check func(*models.LabOrder) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var order models.LabOrder
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, id).Error; err != nil {
+			return err
+		}
+		if err := check(&order); err != nil {
+			return err
+		}
+		return tx.Model(&order).Updates(map[string]interface{}{
+			"status":        models.LabOrderCancelled,
+			"cancel_reason": reason,
+		}).Error
+	})
+}
+
+func (r *Repository) GetOrder(id uint) (*models.LabOrder, error) {
+	var order models.LabOrder
+	err := withDetails(r.db).First(&order, id).Error
+	return &order, err
+}
+
+// ListOrders returns one page of orders. Stat and urgent orders come first,
+// then the oldest, so that the list works as the lab's worklist.
+func (r *Repository) ListOrders(filter models.LabOrderFilter) ([]models.LabOrder, *utils.Meta, error) {
+	page, pageSize := utils.NormalizePage(filter.Page, filter.PageSize)
+
+	query := r.db.Model(&models.LabOrder{})
+	if filter.PatientID != 0 {
+		query = query.Where("patient_id = ?", filter.PatientID)
+	}
+	if filter.OrderedByID != 0 {
+		query = query.Where("ordered_by_id = ?", filter.OrderedByID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var orders []models.LabOrder
+	err := withDetails(query).
+		Order("priority = 'stat' DESC, priority = 'urgent' DESC, created_at, id").
+		Limit(pageSize).
+		Offset((page - 1) * pageSize).
+		Find(&orders).Error
+
+	meta := &utils.Meta{
+		Total:    total,
+		PageSize: pageSize,
+		Page:     page,
+		HasMore:  int64(page*pageSize) < total,
+	}
+	return orders, meta, err
+}
+
+// SaveResult stores a result of the order with the given ID and moves the
+// order to partial or resulted. The order is locked so that concurrent
+// results and cancellation see each other. check is called with the locked
+// order and the stored result before it is overwritten.
+func (r *Repository) SaveResult(orderID uint, result *models.LabResult, check func(*models.LabOrder, *models.LabResult) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var order models.LabOrder
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, orderID).Error; err != nil {
+			return err
+		}
+		var current models.LabResult
+		if err := tx.Where("order_id = ?", orderID).First(&current, result.ID).Error; err != nil {
+			return err
+		}
+		if err := check(&order, &current); err != nil {
+			return err
+		}
+
+		if err := tx.Omit("LabTest").Save(result).Error; err != nil {
+			return err
+		}
+
+		var pending int64
+		if err := tx.Model(&models.LabResult{}).
+			Where("order_id = ? AND status = ?", orderID, models.LabResultPending).
+			Count(&pending).Error; err != nil {
+			return err
+		}
+		status := models.LabOrderResulted
+		if pending > 0 {
+			status = models.LabOrderPartial
+		}
+		return tx.Model(&order).Update("status", status).Error
+	})
+}
+
+// Acknowledge marks the order's entered results that are not acknowledged
+// yet as acknowledged by doctorID and returns how many it marked.
+func (r *Repository) Acknowledge(orderID, doctorID uint, at time.Time) (int64, error) {
+	result := r.db.Model(&models.LabResult{}).
+		Where("order_id = ? AND status <> ? AND acknowledged_at IS NULL", orderID, models.LabResultPending).
+		Updates(map[string]interface{}{
+			"acknowledged_by_id": doctorID,
+			"acknowledged_at":    at,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// ListResults returns the patient's entered results, newest first.
+func (r *Repository) ListResults(patientID uint) ([]models.LabResult, error) {
+	var results []models.LabResult
+	err := r.db.Preload("LabTest").
+		Where("patient_id = ? AND status <> ?", patientID, models.LabResultPending).
+		Order("resulted_at DESC, id DESC").
+		Find(&results).Error
+	return results, err
+}
+
+// ListNumericResults returns the patient's entered numeric results for one
+// test, oldest first.
+func (r *Repository) ListNumericResults(patientID, testID uint) ([]models.LabResult, error) {
+	var results []models.LabResult
+	err := r.db.Where("patient_id = ? AND lab_test_id = ? AND status <> ? AND value IS NOT NULL",
+		patientID, testID, models.LabResultPending).
+		Order("resulted_at, id").
+		Find(&results).Error
+	return results, err
+}
+
+// ListUnacknowledged returns the entered results of orders placed by
+// doctorID that no doctor has acknowledged yet, critical results first.
+func (r *Repository) ListUnacknowledged(doctorID uint) ([]models.LabResult, error) {
+	var results []models.LabResult
+	err := r.db.Preload("LabTest").
+		Joins("JOIN lab_orders ON lab_orders.id = lab_results.order_id").
+		Where("lab_orders.ordered_by_id = ? AND lab_results.status <> ? AND lab_results.acknowledged_at IS NULL",
+			doctorID, models.LabResultPending).
+		Order("lab_results.flag IN ('critical_low','critical_high') DESC, lab_results.resulted_at, lab_results.id").
+		Find(&results).Error
+	return results, err
+}
@@ -0,0 +1,383 @@
+package lab
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"hospital-management/internal/encounter"
+	"hospital-management/internal/models"
+	"hospital-management/internal/patient"
+	"hospital-management/internal/user"
+	"hospital-management/pkg/utils"
+)
+
+var (
+	ErrDuplicateCode        = errors.New("a lab test with this code already exists")
+	ErrResultNotFound       = errors.New("result does not belong to this lab order")
+	ErrInvalidRange         = errors.New("reference range bounds are out of order")
+	ErrTestUnavailable      = errors.New("lab test does not exist or is no longer offered")
+	ErrOrderCancelled       = errors.New("lab order has been cancelled")
+	ErrOrderStarted         = errors.New("lab order already has results and cannot be cancelled")
+	ErrEmptyResult          = errors.New("a result needs a value or value_text")
+	ErrCorrectionReason     = errors.New("changing a final result needs a correction_reason")
+	ErrNothingToAcknowledge = errors.New("lab order has no results awaiting acknowledgement")
+	ErrEncounterMismatch    = errors.New("encounter belongs to another patient")
+)
+
+type Service struct {
+	repo             *Repository
+	patientService   *patient.Service
+	userService      *user.Service
+	encounterService *encounter.Service
+}
+
+func NewService(repo *Repository, patientService *patient.Service, userService *user.Service, encounterService *encounter.Service) *Service {
+	return &Service{
+		repo:             repo,
+		patientService:   patientService,
+		userService:      userService,
+		encounterService: encounterService,
+	}
+}
+
+// ListTests returns the lab catalog. Retired tests are only included when
+// asked for.
+func (s *Service) ListTests(includeInactive bool) ([]models.LabTest, error) {
+	return s.repo.ListTests(includeInactive)
+}
+
+func (s *Service) GetTest(id uint) (*models.LabTest, error) {
+	return s.repo.GetTest(id)
+}
+
+func (s *Service) CreateTest(req models.CreateLabTestRequest) (*models.LabTest, error) {
+	ranges, err := buildRanges(req.ReferenceRanges)
+	if err != nil {
+		return nil, err
+	}
+
+	test := &models.LabTest{
+		Code:            strings.ToUpper(strings.TrimSpace(req.Code)),
+		Name:            strings.TrimSpace(req.Name),
+		Unit:            strings.TrimSpace(req.Unit),
+		Specimen:        strings.TrimSpace(req.Specimen),
+		IsActive:        true,
+		ReferenceRanges: ranges,
+	}
+	if err := s.repo.CreateTest(test); err != nil {
+		return nil, err
+	}
+	return s.repo.GetTest(test.ID)
+}
+
+// UpdateTest changes a catalog test. Results already entered keep the range
+// they were flagged against.
+func (s *Service) UpdateTest(id uint, req models.UpdateLabTestRequest) (*models.LabTest, error) {
+	test, err := s.repo.GetTest(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var ranges []models.LabReferenceRange
+	if req.ReferenceRanges != nil {
+		if ranges, err = buildRanges(req.ReferenceRanges); err != nil {
+			return nil, err
+		}
+	}
+	if req.Name != nil {
+		test.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Unit != nil {
+		test.Unit = strings.TrimSpace(*req.Unit)
+	}
+	if req.Specimen != nil {
+		test.Specimen = strings.TrimSpace(*req.Specimen)
+	}
+	if req.IsActive != nil {
+		test.IsActive = *req.IsActive
+	}
+
+	if err := s.repo.UpdateTest(test, ranges); err != nil {
+		return nil, err
+	}
+	return s.repo.GetTest(id)
+}
+
+// Order places an order for the requested tests on a patient, signed by
+// doctorID, who must be an active doctor. Each test gets a pending result
+// for the lab to fill in.
+func (s *Service) Order(req models.CreateLabOrderRequest, doctorID uint) (*models.LabOrder, error) {
+	if _, err := s.userService.GetActiveDoctor(doctorID); err != nil {
+		return nil, err
+	}
+	if _, err := s.patientService.GetActivePatient(req.PatientID); err != nil {
+		return nil, err
+	}
+
+	ids := uniqueIDs(req.TestIDs)
+	tests, err := s.repo.FindTests(ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(tests) != len(ids) {
+		return nil, ErrTestUnavailable
+	}
+
+	priority := req.Priority
+	if priority == "" {
+		priority = models.LabPriorityRoutine
+	}
+	order := &models.LabOrder{
+		PatientID:     req.PatientID,
+		OrderedByID:   doctorID,
+		Priority:      priority,
+		Status:        models.LabOrderOrdered,
+		ClinicalNotes: req.ClinicalNotes,
+	}
+	for _, test := range tests {
+		if !test.IsActive {
+			return nil, ErrTestUnavailable
+		}
+		order.Results = append(order.Results, models.LabResult{
+			PatientID: req.PatientID,
+			LabTestID: test.ID,
+			Status:    models.LabResultPending,
+			Unit:      test.Unit,
+		})
+	}
+
+	if req.EncounterID != 0 {
+		e, err := s.encounterService.Get(req.EncounterID, doctorID)
+		if err != nil {
+			return nil, err
+		}
+		if e.PatientID != req.PatientID {
+			return nil, ErrEncounterMismatch
+		}
+		order.EncounterID = &e.ID
+	}
+
+	if err := s.repo.CreateOrder(order); err != nil {
+		return nil, err
+	}
+	return s.repo.GetOrder(order.ID)
+}
+
+func (s *Service) GetOrder(id uint) (*models.LabOrder, error) {
+	return s.repo.GetOrder(id)
+}
+
+// ListOrders returns lab orders matching the filter, most pressing first.
+func (s *Service) ListOrders(filter models.LabOrderFilter) ([]models.LabOrder, *utils.Meta, error) {
+	return s.repo.ListOrders(filter)
+}
+
+// Cancel withdraws an order the lab has not started resulting.
+func (s *Service) Cancel(id uint, reason string) (*models.LabOrder, error) {
+	err := s.repo.CancelOrder(id, reason, func(order *models.LabOrder) error {
+		switch order.Status {
+		case models.LabOrderCancelled:
+			return ErrOrderCancelled
+		case models.LabOrderPartial, models.LabOrderResulted:
+			return ErrOrderStarted
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetOrder(id)
+}
+
+// EnterResult records the result of one test of an order, entered by
+// technicianID. Numeric values are flagged against the reference range for
+// the patient's sex and age on the day of the result. Changing a result
+// that was already entered is a correction: it needs a reason and must be
+// acknowledged again.
+func (s *Service) EnterResult(orderID, resultID uint, req models.EnterLabResultRequest, technicianID uint) (*models.LabOrder, *models.LabResult, error) {
+	valueText := strings.TrimSpace(req.ValueText)
+	if req.Value == nil && valueText == "" {
+		return nil, nil, ErrEmptyResult
+	}
+
+	order, err := s.repo.GetOrder(orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var result *models.LabResult
+	for i := range order.Results {
+		if order.Results[i].ID == resultID {
+			result = &order.Results[i]
+		}
+	}
+	if result == nil {
+		return nil, nil, ErrResultNotFound
+	}
+	p, err := s.patientService.GetPatientByID(order.PatientID)
+	if err != nil {
+		return nil, nil, err
+	}
+	test, err := s.repo.GetTest(result.LabTestID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	result.Value = req.Value
+	result.ValueText = valueText
+	result.Comment = req.Comment
+	result.Unit = test.Unit
+	result.ResultedByID = &technicianID
+	result.ResultedAt = &now
+	applyRange(result, test.ReferenceRanges, p, now)
+
+	reason := strings.TrimSpace(req.CorrectionReason)
+	err = s.repo.SaveResult(orderID, result, func(locked *models.LabOrder, current *models.LabResult) error {
+		if locked.Status == models.LabOrderCancelled {
+			return ErrOrderCancelled
+		}
+		if current.Status == models.LabResultPending {
+			result.Status = models.LabResultFinal
+			return nil
+		}
+		if reason == "" {
+			return ErrCorrectionReason
+		}
+		result.Status = models.LabResultCorrected
+		result.CorrectionReason = reason
+		result.AcknowledgedByID = nil
+		result.AcknowledgedAt = nil
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	order, err = s.repo.GetOrder(orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range order.Results {
+		if order.Results[i].ID == resultID {
+			result = &order.Results[i]
+		}
+	}
+	return order, result, nil
+}
+
+// Acknowledge records that doctorID, who must be an active doctor, has
+// reviewed the order's results.
+func (s *Service) Acknowledge(orderID, doctorID uint) (*models.LabOrder, error) {
+	if _, err := s.userService.GetActiveDoctor(doctorID); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetOrder(orderID); err != nil {
+		return nil, err
+	}
+
+	marked, err := s.repo.Acknowledge(orderID, doctorID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if marked == 0 {
+		return nil, ErrNothingToAcknowledge
+	}
+	return s.repo.GetOrder(orderID)
+}
+
+// PatientResults returns the patient's entered results, newest first.
+func (s *Service) PatientResults(patientID uint) ([]models.LabResult, error) {
+	if _, err := s.patientService.GetPatientByID(patientID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListResults(patientID)
+}
+
+// Trend returns the patient's numeric results for one test over time.
+func (s *Service) Trend(patientID, testID uint) (*models.LabTrend, error) {
+	if _, err := s.patientService.GetPatientByID(patientID); err != nil {
+		return nil, err
+	}
+	test, err := s.repo.GetTest(testID)
+	if err != nil {
+		return nil, err
+	}
+	results, err := s.repo.ListNumericResults(patientID, testID)
+	if err != nil {
+		return nil, err
+	}
+
+	trend := &models.LabTrend{Test: *test, Points: make([]models.LabTrendPoint, 0, len(results))}
+	for _, r := range results {
+		trend.Points = append(trend.Points, models.LabTrendPoint{
+			ResultID:      r.ID,
+			OrderID:       r.OrderID,
+			ResultedAt:    *r.ResultedAt,
+			Value:         *r.Value,
+			Flag:          r.Flag,
+			ReferenceLow:  r.ReferenceLow,
+			ReferenceHigh: r.ReferenceHigh,
+		})
+	}
+	if n := len(trend.Points); n >= 2 {
+		change := trend.Points[n-1].Value - trend.Points[n-2].Value
+		trend.Change = &change
+	}
+	return trend, nil
+}
+
+// Unacknowledged returns the results of the doctor's orders that are
+// waiting for review, critical results first.
+func (s *Service) Unacknowledged(doctorID uint) ([]models.LabResult, error) {
+	return s.repo.ListUnacknowledged(doctorID)
+}
+
+// buildRanges checks reference range inputs and converts them to ranges.
+func buildRanges(inputs []models.LabReferenceRangeInput) ([]models.LabReferenceRange, error) {
+	ranges := make([]models.LabReferenceRange, 0, len(inputs))
+	for _, in := range inputs {
+		if in.MaxAgeDays != nil && *in.MaxAgeDays <= in.MinAgeDays {
+			return nil, ErrInvalidRange
+		}
+		if !ordered(in.CriticalLow, in.Low, in.High, in.CriticalHigh) {
+			return nil, ErrInvalidRange
+		}
+		ranges = append(ranges, models.LabReferenceRange{
+			Sex:          in.Sex,
+			MinAgeDays:   in.MinAgeDays,
+			MaxAgeDays:   in.MaxAgeDays,
+			Low:          in.Low,
+			High:         in.High,
+			CriticalLow:  in.CriticalLow,
+			CriticalHigh: in.CriticalHigh,
+		})
+	}
+	return ranges, nil
+}
+
+// ordered reports whether the bounds that are set are in ascending order.
+func ordered(bounds ...*float64) bool {
+	var last *float64
+	for _, b := range bounds {
+		if b == nil {
+			continue
+		}
+		if last != nil && *b < *last {
+			return false
+		}
+		last = b
+	}
+	return true
+}
+
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	out := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
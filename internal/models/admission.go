@@ -0,0 +1,186 @@
+package models
+
+import "time"
+
+// Bed housekeeping states. Whether a bed is occupied follows from its
+// current admission; a bed freed by a discharge or transfer needs cleaning
+// before it can be assigned again.
+const (
+	BedAvailable    = "available"
+	BedCleaning     = "cleaning"
+	BedOutOfService = "out_of_service"
+)
+
+const (
+	AdmissionAdmitted   = "admitted"
+	AdmissionDischarged = "discharged"
+)
+
+// Discharge dispositions.
+const (
+	DischargeHome          = "home"
+	DischargeTransferOut   = "transfer_out"
+	DischargeAgainstAdvice = "against_medical_advice"
+	DischargeDeceased      = "deceased"
+)
+
+// Ward is an inpatient unit made up of rooms.
+type Ward struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"uniqueIndex;not null"`
+	Description string    `json:"description"`
+	IsActive    bool      `json:"is_active" gorm:"not null;default:true"`
+	Rooms       []Room    `json:"rooms,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type Room struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	WardID    uint      `json:"ward_id" gorm:"not null;uniqueIndex:idx_rooms_ward_number"`
+	Number    string    `json:"number" gorm:"not null;uniqueIndex:idx_rooms_ward_number"`
+	Beds      []Bed     `json:"beds,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Bed struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	RoomID    uint      `json:"room_id" gorm:"not null;uniqueIndex:idx_beds_room_label"`
+	Room      *Room     `json:"room,omitempty"`
+	Label     string    `json:"label" gorm:"not null;uniqueIndex:idx_beds_room_label"`
+	Status    string    `json:"status" gorm:"not null;default:available;check:status IN ('available','cleaning','out_of_service')"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Admission is a patient's inpatient stay, from admission to discharge, in
+// the care of an attending doctor. An admitted patient occupies one bed.
+type Admission struct {
+	ID                   uint                `json:"id" gorm:"primaryKey"`
+	PatientID            uint                `json:"patient_id" gorm:"not null;index"`
+	Patient              Patient             `json:"patient" gorm:"foreignKey:PatientID"`
+	BedID                uint                `json:"bed_id" gorm:"not null;index"`
+	Bed                  Bed                 `json:"bed"`
+	AttendingDoctorID    uint                `json:"attending_doctor_id" gorm:"not null;index"`
+	AttendingDoctor      User                `json:"attending_doctor" gorm:"foreignKey:AttendingDoctorID"`
+	Status               string              `json:"status" gorm:"not null;default:admitted;index;check:status IN ('admitted','discharged')"`
+	Reason               string              `json:"reason" gorm:"type:text;not null"`
+	AdmittedByID         uint                `json:"admitted_by_id" gorm:"not null"`
+	AdmittedAt           time.Time           `json:"admitted_at" gorm:"type:timestamptz;not null"`
+	DischargedAt         *time.Time          `json:"discharged_at,omitempty" gorm:"type:timestamptz"`
+	DischargedByID       *uint               `json:"discharged_by_id,omitempty"`
+	DischargeDisposition string              `json:"discharge_disposition,omitempty"`
+	DischargeNote        string              `json:"discharge_note,omitempty" gorm:"type:text"`
+	Transfers            []AdmissionTransfer `json:"transfers,omitempty"`
+	CreatedAt            time.Time           `json:"created_at"`
+	UpdatedAt            time.Time           `json:"updated_at"`
+}
+
+// AdmissionTransfer records a move of an admitted patient to another bed, to
+// another attending doctor, or both.
+type AdmissionTransfer struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	AdmissionID     uint      `json:"admission_id" gorm:"not null;index"`
+	PatientID       uint      `json:"patient_id" gorm:"not null;index"`
+	FromBedID       uint      `json:"from_bed_id" gorm:"not null"`
+	ToBedID         uint      `json:"to_bed_id" gorm:"not null"`
+	FromDoctorID    uint      `json:"from_doctor_id" gorm:"not null"`
+	ToDoctorID      uint      `json:"to_doctor_id" gorm:"not null"`
+	Reason          string    `json:"reason" gorm:"type:text"`
+	TransferredByID uint      `json:"transferred_by_id" gorm:"not null"`
+	TransferredAt   time.Time `json:"transferred_at" gorm:"type:timestamptz;not null"`
+}
+
+type CreateWardRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+type UpdateWardRequest struct {
+	Name        *string `json:"name" binding:"omitempty,min=1"`
+	Description *string `json:"description"`
+	IsActive    *bool   `json:"is_active"`
+}
+
+type CreateRoomRequest struct {
+	Number string `json:"number" binding:"required"`
+}
+
+type CreateBedRequest struct {
+	Label string `json:"label" binding:"required"`
+}
+
+type UpdateBedStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=available cleaning out_of_service"`
+}
+
+type AdmitRequest struct {
+	PatientID         uint   `json:"patient_id" binding:"required"`
+	BedID             uint   `json:"bed_id" binding:"required"`
+	AttendingDoctorID uint   `json:"attending_doctor_id" binding:"required"`
+	Reason            string `json:"reason" binding:"required"`
+}
+
+// TransferRequest moves an admission to another bed, another attending
+// doctor, or both.
+type TransferRequest struct {
+	BedID             uint   `json:"bed_id"`
+	AttendingDoctorID uint   `json:"attending_doctor_id"`
+	Reason            string `json:"reason" binding:"required"`
+}
+
+type DischargeRequest struct {
+	Disposition string `json:"disposition" binding:"required,oneof=home transfer_out against_medical_advice deceased"`
+	Note        string `json:"note"`
+}
+
+type AdmissionFilter struct {
+	PatientID         uint   `form:"patient_id"`
+	WardID            uint   `form:"ward_id"`
+	AttendingDoctorID uint   `form:"attending_doctor_id"`
+	Status            string `form:"status" binding:"omitempty,oneof=admitted discharged"`
+	Page              int    `form:"page" binding:"omitempty,min=1"`
+	PageSize          int    `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+// BedOccupancy is a bed on the occupancy board with the admission occupying
+// it, if any.
+type BedOccupancy struct {
+	Bed       Bed           `json:"bed"`
+	Room      string        `json:"room"`
+	Occupied  bool          `json:"occupied"`
+	Admission *BedAdmission `json:"admission,omitempty"`
+}
+
+// BedAdmission is the admission occupying a bed as the board shows it.
+type BedAdmission struct {
+	ID                uint           `json:"id"`
+	PatientID         uint           `json:"patient_id"`
+	Patient           PatientSummary `json:"patient" gorm:"foreignKey:PatientID"`
+	BedID             uint           `json:"bed_id"`
+	AttendingDoctorID uint           `json:"attending_doctor_id"`
+	AttendingDoctor   User           `json:"attending_doctor" gorm:"foreignKey:AttendingDoctorID"`
+	AdmittedAt        time.Time      `json:"admitted_at"`
+}
+
+func (BedAdmission) TableName() string {
+	return "admissions"
+}
+
+type WardOccupancy struct {
+	Ward      Ward           `json:"ward"`
+	Beds      []BedOccupancy `json:"beds"`
+	Total     int            `json:"total"`
+	Occupied  int            `json:"occupied"`
+	Available int            `json:"available"`
+}
+
+// OccupancyBoard is the live state of every bed in the active wards.
+type OccupancyBoard struct {
+	Wards       []WardOccupancy `json:"wards"`
+	Total       int             `json:"total"`
+	Occupied    int             `json:"occupied"`
+	Available   int             `json:"available"`
+	GeneratedAt time.Time       `json:"generated_at"`
+}
@@ -0,0 +1,75 @@
+package models
+
+import "time"
+
+// Clinical status of an allergy or intolerance.
+const (
+	AllergyActive   = "active"
+	AllergyInactive = "inactive"
+	AllergyResolved = "resolved"
+)
+
+// Verification status of an allergy or intolerance. Entries recorded at the
+// front desk or migrated from free text start unverified until a clinician
+// confirms or refutes them. Entries recorded by mistake are marked
+// entered_in_error rather than deleted.
+const (
+	AllergyUnverified     = "unverified"
+	AllergyConfirmed      = "confirmed"
+	AllergyRefuted        = "refuted"
+	AllergyEnteredInError = "entered_in_error"
+)
+
+const (
+	SeverityMild     = "mild"
+	SeverityModerate = "moderate"
+	SeveritySevere   = "severe"
+)
+
+// AllergyIntolerance is a substance a patient reacts to. SubstanceCode
+// optionally codes the substance in CodeSystem, for example an RxNorm or
+// SNOMED CT code.
+type AllergyIntolerance struct {
+	ID                 uint       `json:"id" gorm:"primaryKey"`
+	PatientID          uint       `json:"patient_id" gorm:"not null;index"`
+	Substance          string     `json:"substance" gorm:"not null"`
+	SubstanceCode      string     `json:"substance_code,omitempty"`
+	CodeSystem         string     `json:"code_system,omitempty"`
+	Reaction           string     `json:"reaction" gorm:"type:text"`
+	Severity           string     `json:"severity,omitempty" gorm:"check:severity IN ('','mild','moderate','severe')"`
+	Status             string     `json:"status" gorm:"not null;default:active;check:status IN ('active','inactive','resolved')"`
+	VerificationStatus string     `json:"verification_status" gorm:"not null;default:unverified;check:verification_status IN ('unverified','confirmed','refuted','entered_in_error')"`
+	Onset              *time.Time `json:"onset,omitempty" gorm:"type:date"`
+	Note               string     `json:"note,omitempty" gorm:"type:text"`
+	RecordedByID       uint       `json:"recorded_by_id" gorm:"not null"`
+	RecordedBy         User       `json:"recorded_by" gorm:"foreignKey:RecordedByID"`
+	VerifiedByID       *uint      `json:"verified_by_id,omitempty"`
+	VerifiedAt         *time.Time `json:"verified_at,omitempty" gorm:"type:timestamptz"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// CreateAllergyRequest records an allergy. Onset is a date, YYYY-MM-DD.
+type CreateAllergyRequest struct {
+	Substance          string `json:"substance" binding:"required"`
+	SubstanceCode      string `json:"substance_code"`
+	CodeSystem         string `json:"code_system"`
+	Reaction           string `json:"reaction"`
+	Severity           string `json:"severity" binding:"omitempty,oneof=mild moderate severe"`
+	Status             string `json:"status" binding:"omitempty,oneof=active inactive resolved"`
+	VerificationStatus string `json:"verification_status" binding:"omitempty,oneof=unverified confirmed refuted"`
+	Onset              string `json:"onset"`
+	Note               string `json:"note"`
+}
+
+type UpdateAllergyRequest struct {
+	Substance          *string `json:"substance" binding:"omitempty,min=1"`
+	SubstanceCode      *string `json:"substance_code"`
+	CodeSystem         *string `json:"code_system"`
+	Reaction           *string `json:"reaction"`
+	Severity           *string `json:"severity" binding:"omitempty,oneof=mild moderate severe"`
+	Status             *string `json:"status" binding:"omitempty,oneof=active inactive resolved"`
+	VerificationStatus *string `json:"verification_status" binding:"omitempty,oneof=unverified confirmed refuted"`
+	Onset              *string `json:"onset"`
+	Note               *string `json:"note"`
+}
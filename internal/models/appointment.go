@@ -0,0 +1,87 @@
+package models
+
+import "time"
+
+const (
+	AppointmentScheduled = "scheduled"
+	AppointmentCancelled = "cancelled"
+	AppointmentCompleted = "completed"
+	AppointmentNoShow    = "no_show"
+)
+
+// AppointmentType is a kind of visit, such as a consultation, and how long
+// it takes.
+type AppointmentType struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	Name            string    `json:"name" gorm:"uniqueIndex;not null"`
+	Description     string    `json:"description"`
+	DurationMinutes int       `json:"duration_minutes" gorm:"not null;check:duration_minutes > 0"`
+	IsActive        bool      `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Appointment books a patient to see a doctor for [StartsAt, EndsAt). An
+// exclusion constraint keeps a doctor's or a patient's scheduled
+// appointments from overlapping.
+type Appointment struct {
+	ID                uint            `json:"id" gorm:"primaryKey"`
+	PatientID         uint            `json:"patient_id" gorm:"not null;index"`
+	Patient           Patient         `json:"patient,omitempty" gorm:"foreignKey:PatientID"`
+	DoctorID          uint            `json:"doctor_id" gorm:"not null;index"`
+	Doctor            User            `json:"doctor,omitempty" gorm:"foreignKey:DoctorID"`
+	AppointmentTypeID uint            `json:"appointment_type_id" gorm:"not null"`
+	AppointmentType   AppointmentType `json:"appointment_type,omitempty"`
+	StartsAt          time.Time       `json:"starts_at" gorm:"type:timestamptz;not null;index"`
+	EndsAt            time.Time       `json:"ends_at" gorm:"type:timestamptz;not null"`
+	Status            string          `json:"status" gorm:"not null;default:scheduled;index"`
+	Reason            string          `json:"reason" gorm:"type:text"`
+	BookedByID        uint            `json:"booked_by_id" gorm:"not null"`
+	CancelledByID     *uint           `json:"cancelled_by_id,omitempty"`
+	CancelledAt       *time.Time      `json:"cancelled_at,omitempty"`
+	CancelReason      string          `json:"cancel_reason,omitempty" gorm:"type:text"`
+	CreatedAt         time.Time       `json:"created_at"`
+	UpdatedAt         time.Time       `json:"updated_at"`
+}
+
+type CreateAppointmentTypeRequest struct {
+	Name            string `json:"name" binding:"required"`
+	Description     string `json:"description"`
+	DurationMinutes int    `json:"duration_minutes" binding:"required,min=5,max=480"`
+}
+
+type UpdateAppointmentTypeRequest struct {
+	Description     *string `json:"description"`
+	DurationMinutes *int    `json:"duration_minutes" binding:"omitempty,min=5,max=480"`
+	IsActive        *bool   `json:"is_active"`
+}
+
+type CreateAppointmentRequest struct {
+	PatientID         uint      `json:"patient_id" binding:"required"`
+	DoctorID          uint      `json:"doctor_id" binding:"required"`
+	AppointmentTypeID uint      `json:"appointment_type_id" binding:"required"`
+	StartsAt          time.Time `json:"starts_at" binding:"required"`
+	Reason            string    `json:"reason"`
+}
+
+// RescheduleAppointmentRequest moves an appointment. DoctorID is optional and
+// hands the appointment over to another doctor.
+type RescheduleAppointmentRequest struct {
+	StartsAt time.Time `json:"starts_at" binding:"required"`
+	DoctorID uint      `json:"doctor_id"`
+}
+
+type CancelAppointmentRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// AppointmentFilter selects appointments overlapping [From, To).
+type AppointmentFilter struct {
+	PatientID uint      `form:"patient_id"`
+	DoctorID  uint      `form:"doctor_id"`
+	Status    string    `form:"status" binding:"omitempty,oneof=scheduled cancelled completed no_show"`
+	From      time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To        time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Page      int       `form:"page" binding:"omitempty,min=1"`
+	PageSize  int       `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+const (
+	DischargeSummaryDraft  = "draft"
+	DischargeSummarySigned = "signed"
+)
+
+// DischargeSummary is the doctor's account of an admission, handed to the
+// patient and their regular doctor on discharge. A draft is assembled from
+// the patient record for its author to edit; once signed it can no longer
+// change.
+type DischargeSummary struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	AdmissionID    uint       `json:"admission_id" gorm:"not null;uniqueIndex"`
+	Admission      Admission  `json:"admission"`
+	PatientID      uint       `json:"patient_id" gorm:"not null;index"`
+	AuthorID       uint       `json:"author_id" gorm:"not null;index"`
+	Author         User       `json:"author" gorm:"foreignKey:AuthorID"`
+	Status         string     `json:"status" gorm:"not null;default:draft;check:status IN ('draft','signed')"`
+	Diagnoses      string     `json:"diagnoses" gorm:"type:text"`
+	HospitalCourse string     `json:"hospital_course" gorm:"type:text"`
+	Procedures     string     `json:"procedures" gorm:"type:text"`
+	Medications    string     `json:"medications" gorm:"type:text"`
+	Allergies      string     `json:"allergies" gorm:"type:text"`
+	FollowUp       string     `json:"follow_up" gorm:"type:text"`
+	SignedAt       *time.Time `json:"signed_at,omitempty" gorm:"type:timestamptz"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// UpdateDischargeSummaryRequest edits a draft. Fields left out are kept.
+type UpdateDischargeSummaryRequest struct {
+	Diagnoses      *string `json:"diagnoses"`
+	HospitalCourse *string `json:"hospital_course"`
+	Procedures     *string `json:"procedures"`
+	Medications    *string `json:"medications"`
+	Allergies      *string `json:"allergies"`
+	FollowUp       *string `json:"follow_up"`
+}
+
+// ReassignDischargeSummaryRequest hands a draft over to another doctor.
+type ReassignDischargeSummaryRequest struct {
+	AuthorID uint `json:"author_id" binding:"required"`
+}
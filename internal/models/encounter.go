@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+const (
+	EncounterDraft  = "draft"
+	EncounterSigned = "signed"
+)
+
+// Encounter is a doctor seeing a patient, documented as a SOAP note. Drafts
+// can be edited by their author; once signed the note is frozen and further
+// information is added as addenda.
+type Encounter struct {
+	ID          uint                 `json:"id" gorm:"primaryKey"`
+	PatientID   uint                 `json:"patient_id" gorm:"not null;index"`
+	Patient     Patient              `json:"patient" gorm:"foreignKey:PatientID"`
+	DoctorID    uint                 `json:"doctor_id" gorm:"not null;index"`
+	Doctor      User                 `json:"doctor" gorm:"foreignKey:DoctorID"`
+	VisitID     *uint                `json:"visit_id,omitempty" gorm:"index"`
+	EncounterAt time.Time            `json:"encounter_at" gorm:"type:timestamptz;not null;index"`
+	Reason      string               `json:"reason" gorm:"not null"`
+	Status      string               `json:"status" gorm:"not null;default:draft;check:status IN ('draft','signed')"`
+	Subjective  string               `json:"subjective" gorm:"type:text"`
+	Objective   string               `json:"objective" gorm:"type:text"`
+	Assessment  string               `json:"assessment" gorm:"type:text"`
+	Plan        string               `json:"plan" gorm:"type:text"`
+	SignedAt    *time.Time           `json:"signed_at,omitempty" gorm:"type:timestamptz"`
+	SignedByID  *uint                `json:"signed_by_id,omitempty"`
+	Addenda     []EncounterAddendum  `json:"addenda" gorm:"foreignKey:EncounterID"`
+	Diagnoses   []EncounterDiagnosis `json:"diagnoses" gorm:"foreignKey:EncounterID"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+}
+
+// EncounterAddendum adds to a signed encounter without changing its note.
+// PatientID duplicates the encounter's so that merges move addenda along
+// with their encounters.
+type EncounterAddendum struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	EncounterID uint      `json:"encounter_id" gorm:"not null;index"`
+	PatientID   uint      `json:"patient_id" gorm:"not null;index"`
+	AuthorID    uint      `json:"author_id" gorm:"not null"`
+	Author      User      `json:"author" gorm:"foreignKey:AuthorID"`
+	Content     string    `json:"content" gorm:"type:text;not null"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (EncounterAddendum) TableName() string {
+	return "encounter_addenda"
+}
+
+// CreateEncounterRequest opens an encounter as a draft. EncounterAt defaults
+// to now; VisitID links the encounter to a visit from the waiting queue.
+type CreateEncounterRequest struct {
+	PatientID   uint       `json:"patient_id" binding:"required"`
+	VisitID     uint       `json:"visit_id"`
+	EncounterAt *time.Time `json:"encounter_at"`
+	Reason      string     `json:"reason" binding:"required"`
+	Subjective  string     `json:"subjective"`
+	Objective   string     `json:"objective"`
+	Assessment  string     `json:"assessment"`
+	Plan        string     `json:"plan"`
+}
+
+type UpdateEncounterRequest struct {
+	EncounterAt *time.Time `json:"encounter_at"`
+	Reason      *string    `json:"reason" binding:"omitempty,min=1"`
+	Subjective  *string    `json:"subjective"`
+	Objective   *string    `json:"objective"`
+	Assessment  *string    `json:"assessment"`
+	Plan        *string    `json:"plan"`
+}
+
+type CreateAddendumRequest struct {
+	Content string `json:"content" binding:"required"`
+}
@@ -0,0 +1,189 @@
+package models
+
+import "time"
+
+const (
+	LabPriorityRoutine = "routine"
+	LabPriorityUrgent  = "urgent"
+	LabPriorityStat    = "stat"
+)
+
+// Lab order states. An order is partially resulted once some but not all of
+// its tests have results.
+const (
+	LabOrderOrdered   = "ordered"
+	LabOrderPartial   = "partial"
+	LabOrderResulted  = "resulted"
+	LabOrderCancelled = "cancelled"
+)
+
+const (
+	LabResultPending   = "pending"
+	LabResultFinal     = "final"
+	LabResultCorrected = "corrected"
+)
+
+// Result flags. A numeric result without an applicable reference range is
+// left unflagged.
+const (
+	LabFlagNormal       = "normal"
+	LabFlagLow          = "low"
+	LabFlagHigh         = "high"
+	LabFlagCriticalLow  = "critical_low"
+	LabFlagCriticalHigh = "critical_high"
+)
+
+// LabTest is an orderable test in the lab catalog, measuring one analyte.
+type LabTest struct {
+	ID              uint                `json:"id" gorm:"primaryKey"`
+	Code            string              `json:"code" gorm:"uniqueIndex;not null"`
+	Name            string              `json:"name" gorm:"not null"`
+	Unit            string              `json:"unit"`
+	Specimen        string              `json:"specimen"`
+	IsActive        bool                `json:"is_active" gorm:"not null;default:true"`
+	ReferenceRanges []LabReferenceRange `json:"reference_ranges" gorm:"constraint:OnDelete:CASCADE"`
+	CreatedAt       time.Time           `json:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at"`
+}
+
+// LabReferenceRange is the normal range of a test for patients of a sex
+// (empty for any) aged from MinAgeDays up to, but excluding, MaxAgeDays (nil
+// for no upper limit). Results outside the critical limits are flagged
+// critical.
+type LabReferenceRange struct {
+	ID           uint     `json:"id" gorm:"primaryKey"`
+	LabTestID    uint     `json:"lab_test_id" gorm:"not null;index"`
+	Sex          string   `json:"sex" gorm:"not null;default:'';check:sex IN ('','male','female')"`
+	MinAgeDays   int      `json:"min_age_days" gorm:"not null;default:0"`
+	MaxAgeDays   *int     `json:"max_age_days,omitempty"`
+	Low          *float64 `json:"low,omitempty"`
+	High         *float64 `json:"high,omitempty"`
+	CriticalLow  *float64 `json:"critical_low,omitempty"`
+	CriticalHigh *float64 `json:"critical_high,omitempty"`
+}
+
+// LabOrder is a request by a doctor for one or more tests on a patient. Each
+// test has a result row, pending until the lab enters its value.
+type LabOrder struct {
+	ID            uint        `json:"id" gorm:"primaryKey"`
+	PatientID     uint        `json:"patient_id" gorm:"not null;index"`
+	Patient       Patient     `json:"patient" gorm:"foreignKey:PatientID"`
+	OrderedByID   uint        `json:"ordered_by_id" gorm:"not null;index"`
+	OrderedBy     User        `json:"ordered_by" gorm:"foreignKey:OrderedByID"`
+	EncounterID   *uint       `json:"encounter_id,omitempty" gorm:"index"`
+	Priority      string      `json:"priority" gorm:"not null;default:routine;check:priority IN ('routine','urgent','stat')"`
+	Status        string      `json:"status" gorm:"not null;default:ordered;index;check:status IN ('ordered','partial','resulted','cancelled')"`
+	ClinicalNotes string      `json:"clinical_notes" gorm:"type:text"`
+	CancelReason  string      `json:"cancel_reason,omitempty" gorm:"type:text"`
+	Results       []LabResult `json:"results" gorm:"foreignKey:OrderID"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+}
+
+// LabResult is the result of one test in an order. The reference range that
+// applied to the patient is copied onto the result so that later catalog
+// changes do not alter how it was flagged.
+type LabResult struct {
+	ID               uint       `json:"id" gorm:"primaryKey"`
+	OrderID          uint       `json:"order_id" gorm:"not null;index"`
+	PatientID        uint       `json:"patient_id" gorm:"not null;index:idx_lab_results_patient_test"`
+	LabTestID        uint       `json:"lab_test_id" gorm:"not null;index:idx_lab_results_patient_test"`
+	LabTest          LabTest    `json:"lab_test"`
+	Status           string     `json:"status" gorm:"not null;default:pending;check:status IN ('pending','final','corrected')"`
+	Value            *float64   `json:"value,omitempty"`
+	ValueText        string     `json:"value_text,omitempty"`
+	Unit             string     `json:"unit"`
+	ReferenceLow     *float64   `json:"reference_low,omitempty"`
+	ReferenceHigh    *float64   `json:"reference_high,omitempty"`
+	Flag             string     `json:"flag,omitempty"`
+	Comment          string     `json:"comment,omitempty" gorm:"type:text"`
+	CorrectionReason string     `json:"correction_reason,omitempty" gorm:"type:text"`
+	ResultedByID     *uint      `json:"resulted_by_id,omitempty"`
+	ResultedAt       *time.Time `json:"resulted_at,omitempty" gorm:"type:timestamptz"`
+	AcknowledgedByID *uint      `json:"acknowledged_by_id,omitempty"`
+	AcknowledgedAt   *time.Time `json:"acknowledged_at,omitempty" gorm:"type:timestamptz"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// Critical reports whether the result is outside its critical limits.
+func (r *LabResult) Critical() bool {
+	return r.Flag == LabFlagCriticalLow || r.Flag == LabFlagCriticalHigh
+}
+
+type LabReferenceRangeInput struct {
+	Sex          string   `json:"sex" binding:"omitempty,oneof=male female"`
+	MinAgeDays   int      `json:"min_age_days" binding:"min=0"`
+	MaxAgeDays   *int     `json:"max_age_days" binding:"omitempty,min=1"`
+	Low          *float64 `json:"low"`
+	High         *float64 `json:"high"`
+	CriticalLow  *float64 `json:"critical_low"`
+	CriticalHigh *float64 `json:"critical_high"`
+}
+
+type CreateLabTestRequest struct {
+	Code            string                   `json:"code" binding:"required"`
+	Name            string                   `json:"name" binding:"required"`
+	Unit            string                   `json:"unit"`
+	Specimen        string                   `json:"specimen"`
+	ReferenceRanges []LabReferenceRangeInput `json:"reference_ranges" binding:"dive"`
+}
+
+// UpdateLabTestRequest changes a catalog test. ReferenceRanges, when given,
+// replaces all of the test's ranges.
+type UpdateLabTestRequest struct {
+	Name            *string                  `json:"name" binding:"omitempty,min=1"`
+	Unit            *string                  `json:"unit"`
+	Specimen        *string                  `json:"specimen"`
+	IsActive        *bool                    `json:"is_active"`
+	ReferenceRanges []LabReferenceRangeInput `json:"reference_ranges" binding:"omitempty,dive"`
+}
+
+type CreateLabOrderRequest struct {
+	PatientID     uint   `json:"patient_id" binding:"required"`
+	EncounterID   uint   `json:"encounter_id"`
+	TestIDs       []uint `json:"test_ids" binding:"required,min=1,dive,required"`
+	Priority      string `json:"priority" binding:"omitempty,oneof=routine urgent stat"`
+	ClinicalNotes string `json:"clinical_notes"`
+}
+
+type CancelLabOrderRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// EnterLabResultRequest records a result. Numeric tests take Value and
+// qualitative ones ValueText. Changing a final result needs a
+// CorrectionReason.
+type EnterLabResultRequest struct {
+	Value            *float64 `json:"value"`
+	ValueText        string   `json:"value_text"`
+	Comment          string   `json:"comment"`
+	CorrectionReason string   `json:"correction_reason"`
+}
+
+type LabOrderFilter struct {
+	PatientID   uint   `form:"patient_id"`
+	OrderedByID uint   `form:"ordered_by"`
+	Status      string `form:"status" binding:"omitempty,oneof=ordered partial resulted cancelled"`
+	Page        int    `form:"page" binding:"omitempty,min=1"`
+	PageSize    int    `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+// LabTrendPoint is one result in an analyte's trend.
+type LabTrendPoint struct {
+	ResultID      uint      `json:"result_id"`
+	OrderID       uint      `json:"order_id"`
+	ResultedAt    time.Time `json:"resulted_at"`
+	Value         float64   `json:"value"`
+	Flag          string    `json:"flag,omitempty"`
+	ReferenceLow  *float64  `json:"reference_low,omitempty"`
+	ReferenceHigh *float64  `json:"reference_high,omitempty"`
+}
+
+// LabTrend is a patient's numeric results for one test over time, oldest
+// first. Change is the difference between the last two values.
+type LabTrend struct {
+	Test   LabTest         `json:"test"`
+	Points []LabTrendPoint `json:"points"`
+	Change *float64        `json:"change,omitempty"`
+}
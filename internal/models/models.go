@@ -1,43 +1,291 @@
 package models
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type User struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	Username  string    `json:"username" gorm:"unique;not null"`
-	Email     string    `json:"email" gorm:"unique;not null"`
-	Password  string    `json:"-" gorm:"not null"`
-	Role      string    `json:"role" gorm:"not null;check:role IN ('receptionist','doctor')"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
-	Phone     string    `json:"phone"`
-	IsActive  bool      `json:"is_active" gorm:"default:true"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID                 uint   `json:"id" gorm:"primaryKey"`
+	Username           string `json:"username" gorm:"unique;not null"`
+	Email              string `json:"email" gorm:"unique;not null"`
+	Password           string `json:"-" gorm:"not null"`
+	Role               string `json:"role" gorm:"not null"`
+	FirstName          string `json:"first_name"`
+	LastName           string `json:"last_name"`
+	Phone              string `json:"phone"`
+	IsActive           bool   `json:"is_active" gorm:"default:true"`
+	MustChangePassword bool   `json:"must_change_password" gorm:"not null;default:false"`
+	PendingApproval    bool   `json:"pending_approval" gorm:"not null;default:false"`
+	// TimeZone is the IANA zone a doctor's schedule is kept in. Empty means
+	// the clinic's time zone.
+	TimeZone string `json:"time_zone"`
+	Roles    []Role `json:"roles,omitempty" gorm:"many2many:user_roles"`
+	// Version is incremented on every update and served as the ETag.
+	Version   uint           `json:"version" gorm:"not null;default:1"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// RoleNames returns the names of the roles loaded on the user.
+func (u *User) RoleNames() []string {
+	names := make([]string, 0, len(u.Roles))
+	for _, role := range u.Roles {
+		names = append(names, role.Name)
+	}
+	return names
+}
+
+// PermissionNames returns the distinct permissions granted by the roles
+// loaded on the user.
+func (u *User) PermissionNames() []string {
+	seen := make(map[string]bool)
+	names := []string{}
+	for _, role := range u.Roles {
+		for _, permission := range role.Permissions {
+			if !seen[permission.Name] {
+				seen[permission.Name] = true
+				names = append(names, permission.Name)
+			}
+		}
+	}
+	return names
+}
+
+const (
+	RoleAdmin         = "admin"
+	RoleReceptionist  = "receptionist"
+	RoleDoctor        = "doctor"
+	RoleCompliance    = "compliance_officer"
+	RoleLabTechnician = "lab_technician"
+	RoleNurse         = "nurse"
+)
+
+const (
+	PermissionPatientRead       = "patient:read"
+	PermissionPatientWrite      = "patient:write"
+	PermissionPatientDelete     = "patient:delete"
+	PermissionPatientMerge      = "patient:merge"
+	PermissionPatientRestore    = "patient:restore"
+	PermissionMedicalWrite      = "medical:write"
+	PermissionUserRead          = "user:read"
+	PermissionUserWrite         = "user:write"
+	PermissionRoleManage        = "role:manage"
+	PermissionAuditRead         = "audit:read"
+	PermissionAppointmentRead   = "appointment:read"
+	PermissionAppointmentWrite  = "appointment:write"
+	PermissionScheduleManage    = "schedule:manage"
+	PermissionQueueRead         = "queue:read"
+	PermissionQueueWrite        = "queue:write"
+	PermissionEncounterRead     = "encounter:read"
+	PermissionEncounterWrite    = "encounter:write"
+	PermissionPrescriptionRead  = "prescription:read"
+	PermissionPrescriptionWrite = "prescription:write"
+	PermissionLabRead           = "lab:read"
+	PermissionLabOrder          = "lab:order"
+	PermissionLabResult         = "lab:result"
+	PermissionLabCatalog        = "lab:catalog"
+	PermissionVitalsRead        = "vitals:read"
+	PermissionVitalsWrite       = "vitals:write"
+	PermissionAdmissionRead     = "admission:read"
+	PermissionAdmissionWrite    = "admission:write"
+	PermissionWardManage        = "ward:manage"
+	PermissionProblemRead       = "problem:read"
+	PermissionProblemWrite      = "problem:write"
+	PermissionDischargeManage   = "discharge:manage"
+)
+
+type Permission struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"uniqueIndex;not null"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type Role struct {
+	ID          uint         `json:"id" gorm:"primaryKey"`
+	Name        string       `json:"name" gorm:"uniqueIndex;not null"`
+	Description string       `json:"description"`
+	Permissions []Permission `json:"permissions,omitempty" gorm:"many2many:role_permissions"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
 }
 
 type Patient struct {
-	ID             uint      `json:"id" gorm:"primaryKey"`
-	FirstName      string    `json:"first_name" gorm:"not null"`
-	LastName       string    `json:"last_name" gorm:"not null"`
-	Email          string    `json:"email" gorm:"unique"`
-	Phone          string    `json:"phone" gorm:"not null"`
-	DateOfBirth    time.Time `json:"date_of_birth"`
-	Gender         string    `json:"gender" gorm:"check:gender IN ('male','female','other')"`
-	Address        string    `json:"address"`
-	EmergencyContact string  `json:"emergency_contact"`
-	BloodGroup     string    `json:"blood_group"`
-	Allergies      string    `json:"allergies"`
-	MedicalHistory string    `json:"medical_history"`
-	CurrentMedications string `json:"current_medications"`
-	InsuranceNumber string   `json:"insurance_number"`
+	ID                 uint      `json:"id" gorm:"primaryKey"`
+	FirstName          string    `json:"first_name" gorm:"not null"`
+	LastName           string    `json:"last_name" gorm:"not null"`
+	Email              string    `json:"email" gorm:"unique"`
+	Phone              string    `json:"phone" gorm:"not null"`
+	DateOfBirth        time.Time `json:"date_of_birth"`
+	Gender             string    `json:"gender" gorm:"check:gender IN ('male','female','other')"`
+	Address            string    `json:"address"`
+	EmergencyContact   string    `json:"emergency_contact"`
+	BloodGroup         string    `json:"blood_group"`
+	MedicalHistory     string    `json:"medical_history"`
+	CurrentMedications string    `json:"current_medications"`
+	InsuranceNumber    string    `json:"insurance_number"`
+	// FirstNameKey and LastNameKey hold the names folded by
+	// matching.NormalizeName, so that candidate searches ignore case and
+	// accents.
+	FirstNameKey     string    `json:"-" gorm:"not null;default:'';index"`
+	LastNameKey      string    `json:"-" gorm:"not null;default:'';index"`
 	RegistrationDate time.Time `json:"registration_date" gorm:"default:CURRENT_TIMESTAMP"`
-	CreatedBy      uint      `json:"created_by"`
-	CreatedByUser  User      `json:"created_by_user" gorm:"foreignKey:CreatedBy"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	CreatedBy        uint      `json:"created_by"`
+	CreatedByUser    User      `json:"created_by_user" gorm:"foreignKey:CreatedBy"`
+	// MergedIntoID is set on a tombstone left behind when this record was
+	// merged into another patient.
+	MergedIntoID *uint      `json:"merged_into_id,omitempty" gorm:"index"`
+	MergedAt     *time.Time `json:"merged_at,omitempty"`
+	// Version is incremented on every change to the row and served as the
+	// ETag for optimistic concurrency control.
+	Version   uint           `json:"version" gorm:"not null;default:1"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// PatientSummary is what shared live views, such as the queues and the bed
+// board, show of a patient. The patient ID serves as the record number.
+type PatientSummary struct {
+	ID        uint   `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+func (PatientSummary) TableName() string {
+	return "patients"
+}
+
+// JSONText is a JSON document stored in a text column. It is emitted as
+// embedded JSON rather than as a quoted string.
+type JSONText string
+
+func (j JSONText) MarshalJSON() ([]byte, error) {
+	if j == "" {
+		return []byte("null"), nil
+	}
+	return []byte(j), nil
+}
+
+// PatientRevision is one version of a patient record. Changes holds the
+// fields that changed from the previous version and Snapshot the complete
+// record after the change.
+type PatientRevision struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	PatientID   uint      `json:"patient_id" gorm:"not null;uniqueIndex:idx_patient_revisions_version"`
+	Version     int       `json:"version" gorm:"not null;uniqueIndex:idx_patient_revisions_version"`
+	Action      string    `json:"action" gorm:"not null"`
+	ChangedByID uint      `json:"changed_by_id"`
+	ChangedAt   time.Time `json:"changed_at" gorm:"not null;index"`
+	Changes     JSONText  `json:"changes" gorm:"type:text"`
+	Snapshot    JSONText  `json:"snapshot,omitempty" gorm:"type:text"`
+}
+
+// PatientMerge records a merge of a duplicate (source) patient into the
+// surviving (target) patient. MovedRecords holds, per table, the IDs of the
+// rows moved to the target so the merge can be undone.
+type PatientMerge struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	SourceID     uint       `json:"source_id" gorm:"not null;index"`
+	TargetID     uint       `json:"target_id" gorm:"not null;index"`
+	Reason       string     `json:"reason" gorm:"type:text"`
+	MovedRecords string     `json:"moved_records" gorm:"type:text"`
+	MergedByID   uint       `json:"merged_by_id" gorm:"not null"`
+	MergedAt     time.Time  `json:"merged_at" gorm:"not null"`
+	UnmergedByID *uint      `json:"unmerged_by_id"`
+	UnmergedAt   *time.Time `json:"unmerged_at"`
+}
+
+type RefreshToken struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	UserID       uint       `json:"user_id" gorm:"not null;index"`
+	FamilyID     string     `json:"-" gorm:"not null;index"`
+	TokenHash    string     `json:"-" gorm:"uniqueIndex;not null"`
+	ExpiresAt    time.Time  `json:"expires_at" gorm:"not null"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+	ReplacedByID *uint      `json:"replaced_by_id"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// UsedStreamToken records the ID of a stream token that has opened a
+// stream, so that the token cannot open another.
+type UsedStreamToken struct {
+	ID     string    `gorm:"primaryKey"`
+	UsedAt time.Time `gorm:"not null;index"`
+}
+
+type Invitation struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	TokenHash   string     `json:"-" gorm:"uniqueIndex;not null"`
+	Email       string     `json:"email" gorm:"not null;index"`
+	Role        string     `json:"role" gorm:"not null"`
+	ExpiresAt   time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt      *time.Time `json:"used_at"`
+	UsedByID    *uint      `json:"used_by_id"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedByID uint       `json:"created_by_id" gorm:"not null"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// AuditLog is an append-only record of an action. Entries form a hash chain:
+// each Hash covers the entry's content and the Hash of the entry before it,
+// so editing or removing a row breaks every hash that follows.
+type AuditLog struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	ActorID      uint      `json:"actor_id" gorm:"index"`
+	ActorRole    string    `json:"actor_role"`
+	Action       string    `json:"action" gorm:"not null;index"`
+	ResourceType string    `json:"resource_type" gorm:"not null"`
+	ResourceID   uint      `json:"resource_id"`
+	PatientID    *uint     `json:"patient_id" gorm:"index"`
+	Changes      string    `json:"changes" gorm:"type:text"`
+	Details      string    `json:"details" gorm:"type:text"`
+	ClientIP     string    `json:"client_ip"`
+	PrevHash     string    `json:"prev_hash"`
+	Hash         string    `json:"hash" gorm:"not null;default:''"`
+	CreatedAt    time.Time `json:"created_at" gorm:"index"`
+}
+
+// ComputeHash returns the chain hash of the entry. CreatedAt must already be
+// truncated to the database's microsecond precision.
+func (a *AuditLog) ComputeHash() string {
+	var patientID uint
+	if a.PatientID != nil {
+		patientID = *a.PatientID
+	}
+
+	content, _ := json.Marshal([]interface{}{
+		a.PrevHash,
+		a.CreatedAt.UTC().Format(time.RFC3339Nano),
+		a.ActorID,
+		a.ActorRole,
+		a.Action,
+		a.ResourceType,
+		a.ResourceID,
+		patientID,
+		a.Changes,
+		a.Details,
+		a.ClientIP,
+	})
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+type AuditFilter struct {
+	ActorID      uint      `form:"actor_id"`
+	PatientID    uint      `form:"patient_id"`
+	Action       string    `form:"action"`
+	ResourceType string    `form:"resource_type"`
+	From         time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To           time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit        int       `form:"limit" binding:"omitempty,min=1,max=500"`
+	Offset       int       `form:"offset" binding:"omitempty,min=0"`
 }
 
 type LoginRequest struct {
@@ -45,46 +293,147 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
 type RegisterRequest struct {
-	Username  string `json:"username" binding:"required,min=3"`
-	Email     string `json:"email" binding:"required,email"`
-	Password  string `json:"password" binding:"required,min=6"`
-	Role      string `json:"role" binding:"required,oneof=receptionist doctor"`
-	FirstName string `json:"first_name" binding:"required"`
-	LastName  string `json:"last_name" binding:"required"`
-	Phone     string `json:"phone"`
+	InviteToken string `json:"invite_token" binding:"required"`
+	Username    string `json:"username" binding:"required,min=3"`
+	Email       string `json:"email" binding:"required,email"`
+	Password    string `json:"password" binding:"required,min=6"`
+	FirstName   string `json:"first_name" binding:"required"`
+	LastName    string `json:"last_name" binding:"required"`
+	Phone       string `json:"phone"`
 }
 
 type CreatePatientRequest struct {
-	FirstName      string    `json:"first_name" binding:"required"`
-	LastName       string    `json:"last_name" binding:"required"`
-	Email          string    `json:"email" binding:"omitempty,email"`
-	Phone          string    `json:"phone" binding:"required"`
-	DateOfBirth    time.Time `json:"date_of_birth" binding:"required"`
-	Gender         string    `json:"gender" binding:"required,oneof=male female other"`
-	Address        string    `json:"address"`
-	EmergencyContact string  `json:"emergency_contact"`
-	BloodGroup     string    `json:"blood_group"`
-	Allergies      string    `json:"allergies"`
-	InsuranceNumber string   `json:"insurance_number"`
+	FirstName        string    `json:"first_name" binding:"required"`
+	LastName         string    `json:"last_name" binding:"required"`
+	Email            string    `json:"email" binding:"omitempty,email"`
+	Phone            string    `json:"phone" binding:"required"`
+	DateOfBirth      time.Time `json:"date_of_birth" binding:"required"`
+	Gender           string    `json:"gender" binding:"required,oneof=male female other"`
+	Address          string    `json:"address"`
+	EmergencyContact string    `json:"emergency_contact"`
+	BloodGroup       string    `json:"blood_group"`
+	InsuranceNumber  string    `json:"insurance_number"`
+	// OverrideDuplicates registers the patient even when a likely duplicate
+	// exists; OverrideReason must then explain why.
+	OverrideDuplicates bool   `json:"override_duplicates"`
+	OverrideReason     string `json:"override_reason"`
+}
+
+// DuplicateCandidate is an existing patient that may be the same person as a
+// patient being registered.
+type DuplicateCandidate struct {
+	Patient     Patient            `json:"patient"`
+	Probability float64            `json:"probability"`
+	Decision    string             `json:"decision"`
+	Fields      map[string]float64 `json:"fields"`
 }
 
 type UpdatePatientRequest struct {
-	FirstName      string    `json:"first_name"`
-	LastName       string    `json:"last_name"`
-	Email          string    `json:"email" binding:"omitempty,email"`
-	Phone          string    `json:"phone"`
-	DateOfBirth    time.Time `json:"date_of_birth"`
-	Gender         string    `json:"gender" binding:"omitempty,oneof=male female other"`
-	Address        string    `json:"address"`
-	EmergencyContact string  `json:"emergency_contact"`
-	BloodGroup     string    `json:"blood_group"`
-	Allergies      string    `json:"allergies"`
-	InsuranceNumber string   `json:"insurance_number"`
+	FirstName        string    `json:"first_name"`
+	LastName         string    `json:"last_name"`
+	Email            string    `json:"email" binding:"omitempty,email"`
+	Phone            string    `json:"phone"`
+	DateOfBirth      time.Time `json:"date_of_birth"`
+	Gender           string    `json:"gender" binding:"omitempty,oneof=male female other"`
+	Address          string    `json:"address"`
+	EmergencyContact string    `json:"emergency_contact"`
+	BloodGroup       string    `json:"blood_group"`
+	InsuranceNumber  string    `json:"insurance_number"`
+}
+
+// PatientFilter selects, orders and pages the patient list. Pagination is by
+// offset (Page) unless Cursor is set, in which case Page is ignored.
+type PatientFilter struct {
+	Page           int       `form:"page" binding:"omitempty,min=1"`
+	PageSize       int       `form:"page_size" binding:"omitempty,min=1,max=100"`
+	Cursor         string    `form:"cursor"`
+	Sort           string    `form:"sort" binding:"omitempty,oneof=id first_name last_name date_of_birth registration_date created_at"`
+	Order          string    `form:"order" binding:"omitempty,oneof=asc desc"`
+	Gender         string    `form:"gender" binding:"omitempty,oneof=male female other"`
+	BloodGroup     string    `form:"blood_group"`
+	CreatedBy      uint      `form:"created_by"`
+	RegisteredFrom time.Time `form:"registered_from" time_format:"2006-01-02"`
+	RegisteredTo   time.Time `form:"registered_to" time_format:"2006-01-02"`
+	BornFrom       time.Time `form:"dob_from" time_format:"2006-01-02"`
+	BornTo         time.Time `form:"dob_to" time_format:"2006-01-02"`
+}
+
+type PatientSearchRequest struct {
+	Query       string    `form:"q"`
+	Phone       string    `form:"phone"`
+	DateOfBirth time.Time `form:"dob" time_format:"2006-01-02"`
+	Limit       int       `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type PatientMatch struct {
+	Patient   Patient  `json:"patient"`
+	Score     float64  `json:"score"`
+	MatchedOn []string `json:"matched_on"`
+}
+
+type MergePatientRequest struct {
+	SourceID uint   `json:"source_id" binding:"required"`
+	Reason   string `json:"reason" binding:"required"`
 }
 
 type UpdateMedicalInfoRequest struct {
 	MedicalHistory     string `json:"medical_history"`
 	CurrentMedications string `json:"current_medications"`
-	Allergies          string `json:"allergies"`
-}
\ No newline at end of file
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+type UserFilter struct {
+	Role            string `form:"role"`
+	IsActive        *bool  `form:"is_active"`
+	PendingApproval *bool  `form:"pending"`
+	Search          string `form:"q"`
+}
+
+type CreateUserRequest struct {
+	Username  string   `json:"username" binding:"required,min=3"`
+	Email     string   `json:"email" binding:"required,email"`
+	Password  string   `json:"password" binding:"required,min=6"`
+	Roles     []string `json:"roles" binding:"required,min=1"`
+	FirstName string   `json:"first_name" binding:"required"`
+	LastName  string   `json:"last_name" binding:"required"`
+	Phone     string   `json:"phone"`
+}
+
+type CreateInvitationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required"`
+	// ExpiresInHours overrides the default invitation lifetime.
+	ExpiresInHours int `json:"expires_in_hours" binding:"omitempty,min=1,max=720"`
+}
+
+type UpdateUserStatusRequest struct {
+	IsActive *bool `json:"is_active" binding:"required"`
+}
+
+type UpdateUserRolesRequest struct {
+	Roles []string `json:"roles" binding:"required,min=1"`
+}
+
+type ResetPasswordRequest struct {
+	// Password is optional; a temporary password is generated when empty.
+	Password string `json:"password" binding:"omitempty,min=6"`
+}
+
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type UpdateRolePermissionsRequest struct {
+	Permissions []string `json:"permissions" binding:"required"`
+}
@@ -0,0 +1,142 @@
+package models
+
+import "time"
+
+const (
+	PrescriptionActive       = "active"
+	PrescriptionDiscontinued = "discontinued"
+)
+
+// Kinds of clinical decision support alert.
+const (
+	AlertDrugAllergy = "drug_allergy"
+	AlertDrugDrug    = "drug_drug"
+	AlertDuplicate   = "duplicate_therapy"
+)
+
+// Alert severities, most severe first. Contraindicated and major alerts
+// block prescribing unless the prescriber gives an override reason.
+const (
+	AlertContraindicated = "contraindicated"
+	AlertMajor           = "major"
+	AlertModerate        = "moderate"
+	AlertMinor           = "minor"
+)
+
+// Prescription is a medication ordered for a patient by a doctor. It is
+// never edited once written: changing a dose means discontinuing the
+// prescription and writing a new one. StartDate and EndDate are calendar
+// days in the clinic's time zone; EndDate moves forward with each refill.
+type Prescription struct {
+	ID                uint                 `json:"id" gorm:"primaryKey"`
+	PatientID         uint                 `json:"patient_id" gorm:"not null;index"`
+	Patient           Patient              `json:"patient" gorm:"foreignKey:PatientID"`
+	PrescriberID      uint                 `json:"prescriber_id" gorm:"not null;index"`
+	Prescriber        User                 `json:"prescriber" gorm:"foreignKey:PrescriberID"`
+	EncounterID       *uint                `json:"encounter_id,omitempty" gorm:"index"`
+	Drug              string               `json:"drug" gorm:"not null"`
+	DrugCode          string               `json:"drug_code,omitempty"`
+	Dose              string               `json:"dose" gorm:"not null"`
+	Route             string               `json:"route" gorm:"not null"`
+	Frequency         string               `json:"frequency" gorm:"not null"`
+	DurationDays      int                  `json:"duration_days" gorm:"not null;check:duration_days > 0"`
+	Quantity          int                  `json:"quantity" gorm:"not null;check:quantity > 0"`
+	QuantityUnit      string               `json:"quantity_unit"`
+	Refills           int                  `json:"refills" gorm:"not null;default:0;check:refills >= 0"`
+	RefillsUsed       int                  `json:"refills_used" gorm:"not null;default:0;check:refills_used <= refills"`
+	Instructions      string               `json:"instructions" gorm:"type:text"`
+	Status            string               `json:"status" gorm:"not null;default:active;index;check:status IN ('active','discontinued')"`
+	StartDate         time.Time            `json:"start_date" gorm:"type:date;not null"`
+	EndDate           time.Time            `json:"end_date" gorm:"type:date;not null"`
+	DiscontinuedAt    *time.Time           `json:"discontinued_at,omitempty" gorm:"type:timestamptz"`
+	DiscontinuedByID  *uint                `json:"discontinued_by_id,omitempty"`
+	DiscontinueReason string               `json:"discontinue_reason,omitempty" gorm:"type:text"`
+	RefillHistory     []PrescriptionRefill `json:"refill_history" gorm:"foreignKey:PrescriptionID"`
+	Alerts            []PrescriptionAlert  `json:"alerts" gorm:"foreignKey:PrescriptionID"`
+	CreatedAt         time.Time            `json:"created_at"`
+	UpdatedAt         time.Time            `json:"updated_at"`
+}
+
+// RefillsRemaining returns how many authorized refills are left.
+func (p *Prescription) RefillsRemaining() int {
+	return p.Refills - p.RefillsUsed
+}
+
+// PrescriptionRefill records one refill of a prescription. PatientID
+// duplicates the prescription's so that merges move refills along with
+// their prescriptions.
+type PrescriptionRefill struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	PrescriptionID uint      `json:"prescription_id" gorm:"not null;index"`
+	PatientID      uint      `json:"patient_id" gorm:"not null;index"`
+	AuthorizedByID uint      `json:"authorized_by_id" gorm:"not null"`
+	AuthorizedBy   User      `json:"authorized_by" gorm:"foreignKey:AuthorizedByID"`
+	Quantity       int       `json:"quantity" gorm:"not null"`
+	Note           string    `json:"note,omitempty" gorm:"type:text"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// PrescriptionAlert is a conflict found by the clinical decision support
+// engine when a prescription was written. Blocking alerts record the reason
+// the prescriber gave for overriding them. Alerts are also returned, unsaved,
+// when checking a drug before prescribing it.
+type PrescriptionAlert struct {
+	ID                    uint      `json:"id,omitempty" gorm:"primaryKey"`
+	PrescriptionID        uint      `json:"prescription_id,omitempty" gorm:"not null;index"`
+	PatientID             uint      `json:"patient_id" gorm:"not null;index"`
+	Type                  string    `json:"type" gorm:"not null"`
+	Severity              string    `json:"severity" gorm:"not null;check:severity IN ('contraindicated','major','moderate','minor')"`
+	Message               string    `json:"message" gorm:"type:text;not null"`
+	RelatedPrescriptionID *uint     `json:"related_prescription_id,omitempty"`
+	AllergyID             *uint     `json:"allergy_id,omitempty"`
+	OverrideReason        string    `json:"override_reason,omitempty" gorm:"type:text"`
+	OverriddenByID        *uint     `json:"overridden_by_id,omitempty"`
+	CreatedAt             time.Time `json:"created_at"`
+}
+
+// Blocking reports whether the alert must be overridden before prescribing.
+func (a *PrescriptionAlert) Blocking() bool {
+	return a.Severity == AlertContraindicated || a.Severity == AlertMajor
+}
+
+// CreatePrescriptionRequest writes a prescription. StartDate is a date,
+// YYYY-MM-DD, and defaults to today.
+type CreatePrescriptionRequest struct {
+	PatientID    uint   `json:"patient_id" binding:"required"`
+	EncounterID  uint   `json:"encounter_id"`
+	Drug         string `json:"drug" binding:"required"`
+	DrugCode     string `json:"drug_code"`
+	Dose         string `json:"dose" binding:"required"`
+	Route        string `json:"route" binding:"required,oneof=oral sublingual buccal topical transdermal inhaled nasal ophthalmic otic rectal vaginal intravenous intramuscular subcutaneous"`
+	Frequency    string `json:"frequency" binding:"required"`
+	DurationDays int    `json:"duration_days" binding:"required,min=1,max=365"`
+	Quantity     int    `json:"quantity" binding:"required,min=1"`
+	QuantityUnit string `json:"quantity_unit"`
+	Refills      int    `json:"refills" binding:"min=0,max=12"`
+	Instructions string `json:"instructions"`
+	StartDate    string `json:"start_date"`
+	// OverrideReason explains why the prescription is written despite
+	// contraindicated or major alerts.
+	OverrideReason string `json:"override_reason"`
+}
+
+// CheckPrescriptionRequest asks which alerts prescribing a drug to a patient
+// would raise.
+type CheckPrescriptionRequest struct {
+	PatientID uint   `json:"patient_id" binding:"required"`
+	Drug      string `json:"drug" binding:"required"`
+}
+
+type DiscontinuePrescriptionRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+type RefillPrescriptionRequest struct {
+	Note string `json:"note"`
+}
+
+type PrescriptionFilter struct {
+	Status   string `form:"status" binding:"omitempty,oneof=active discontinued"`
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	PageSize int    `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
@@ -0,0 +1,87 @@
+package models
+
+import "time"
+
+// Status of a problem on a patient's problem list.
+const (
+	ProblemActive   = "active"
+	ProblemInactive = "inactive"
+	ProblemResolved = "resolved"
+)
+
+// Problem is a condition on a patient's problem list, coded in ICD-10.
+// Description is copied from the code table when the problem is recorded so
+// that the list reads the same if the table changes later.
+type Problem struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	PatientID    uint       `json:"patient_id" gorm:"not null;index"`
+	Code         string     `json:"code" gorm:"not null;index"`
+	Description  string     `json:"description" gorm:"not null"`
+	Status       string     `json:"status" gorm:"not null;default:active;check:status IN ('active','inactive','resolved')"`
+	OnsetDate    *time.Time `json:"onset_date,omitempty" gorm:"type:date"`
+	ResolvedDate *time.Time `json:"resolved_date,omitempty" gorm:"type:date"`
+	Note         string     `json:"note,omitempty" gorm:"type:text"`
+	RecordedByID uint       `json:"recorded_by_id" gorm:"not null"`
+	RecordedBy   User       `json:"recorded_by" gorm:"foreignKey:RecordedByID"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// EncounterDiagnosis links a problem to an encounter it was diagnosed or
+// treated in. Code and Description repeat the problem's so that the
+// encounter keeps the diagnosis it was documented with. PatientID
+// duplicates the encounter's so that merges move diagnoses along with their
+// encounters.
+type EncounterDiagnosis struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	EncounterID uint      `json:"encounter_id" gorm:"not null;uniqueIndex:idx_encounter_diagnoses_problem"`
+	PatientID   uint      `json:"patient_id" gorm:"not null;index"`
+	ProblemID   uint      `json:"problem_id" gorm:"not null;index;uniqueIndex:idx_encounter_diagnoses_problem"`
+	Problem     *Problem  `json:"problem,omitempty" gorm:"foreignKey:ProblemID"`
+	Code        string    `json:"code" gorm:"not null"`
+	Description string    `json:"description" gorm:"not null"`
+	IsPrimary   bool      `json:"is_primary" gorm:"not null;default:false"`
+	CreatedByID uint      `json:"created_by_id" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (EncounterDiagnosis) TableName() string {
+	return "encounter_diagnoses"
+}
+
+// CreateProblemRequest adds a problem to a patient's list. OnsetDate and
+// ResolvedDate are dates, YYYY-MM-DD; resolving a problem without a
+// ResolvedDate resolves it today.
+type CreateProblemRequest struct {
+	Code         string `json:"code" binding:"required"`
+	Status       string `json:"status" binding:"omitempty,oneof=active inactive resolved"`
+	OnsetDate    string `json:"onset_date"`
+	ResolvedDate string `json:"resolved_date"`
+	Note         string `json:"note"`
+}
+
+type UpdateProblemRequest struct {
+	Status       *string `json:"status" binding:"omitempty,oneof=active inactive resolved"`
+	OnsetDate    *string `json:"onset_date"`
+	ResolvedDate *string `json:"resolved_date"`
+	Note         *string `json:"note"`
+}
+
+type ProblemFilter struct {
+	Status string `form:"status" binding:"omitempty,oneof=active inactive resolved"`
+}
+
+// AddDiagnosisRequest records a diagnosis on an encounter, either an
+// existing problem of the patient or an ICD-10 code. A code that is not
+// already an open problem is added to the problem list. Primary marks the
+// main diagnosis of the encounter.
+type AddDiagnosisRequest struct {
+	ProblemID uint   `json:"problem_id" binding:"required_without=Code"`
+	Code      string `json:"code" binding:"required_without=ProblemID"`
+	Primary   bool   `json:"primary"`
+}
+
+type CodeSearchQuery struct {
+	Query string `form:"q" binding:"required"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=50"`
+}
@@ -0,0 +1,108 @@
+package models
+
+import "time"
+
+const (
+	HoursWork  = "work"
+	HoursBreak = "break"
+)
+
+// WeeklyHours is a recurring block in a doctor's week: working hours, or a
+// break taken out of them. Times are wall-clock "HH:MM" in the doctor's time
+// zone, so a block keeps its local time across DST changes.
+type WeeklyHours struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	DoctorID  uint      `json:"doctor_id" gorm:"not null;index"`
+	Kind      string    `json:"kind" gorm:"not null;check:kind IN ('work','break')"`
+	Weekday   int       `json:"weekday" gorm:"not null;check:weekday BETWEEN 0 AND 6"`
+	StartTime string    `json:"start_time" gorm:"type:varchar(5);not null"`
+	EndTime   string    `json:"end_time" gorm:"type:varchar(5);not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DoctorLeave is a period during which a doctor takes no appointments.
+type DoctorLeave struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	DoctorID    uint      `json:"doctor_id" gorm:"not null;index"`
+	StartsAt    time.Time `json:"starts_at" gorm:"type:timestamptz;not null"`
+	EndsAt      time.Time `json:"ends_at" gorm:"type:timestamptz;not null"`
+	Reason      string    `json:"reason" gorm:"type:text"`
+	CreatedByID uint      `json:"created_by_id" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ScheduleException changes a doctor's hours on one calendar date. Available
+// exceptions replace the weekly hours for that date; unavailable ones remove
+// time from it, or the whole day when no times are given.
+type ScheduleException struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	DoctorID    uint      `json:"doctor_id" gorm:"not null;index:idx_schedule_exceptions_date"`
+	Date        time.Time `json:"date" gorm:"type:date;not null;index:idx_schedule_exceptions_date"`
+	Available   bool      `json:"available" gorm:"not null"`
+	StartTime   string    `json:"start_time,omitempty" gorm:"type:varchar(5)"`
+	EndTime     string    `json:"end_time,omitempty" gorm:"type:varchar(5)"`
+	Reason      string    `json:"reason" gorm:"type:text"`
+	CreatedByID uint      `json:"created_by_id" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// DoctorSchedule is the complete availability setup of one doctor.
+type DoctorSchedule struct {
+	DoctorID   uint                `json:"doctor_id"`
+	TimeZone   string              `json:"time_zone"`
+	Weekly     []WeeklyHours       `json:"weekly"`
+	Leave      []DoctorLeave       `json:"leave"`
+	Exceptions []ScheduleException `json:"exceptions"`
+}
+
+type WeeklyHoursInput struct {
+	Kind      string `json:"kind" binding:"required,oneof=work break"`
+	Weekday   int    `json:"weekday" binding:"min=0,max=6"`
+	StartTime string `json:"start_time" binding:"required"`
+	EndTime   string `json:"end_time" binding:"required"`
+}
+
+// SetWeeklyHoursRequest replaces a doctor's weekly hours. TimeZone, when
+// set, changes the doctor's time zone.
+type SetWeeklyHoursRequest struct {
+	TimeZone string             `json:"time_zone"`
+	Hours    []WeeklyHoursInput `json:"hours" binding:"dive"`
+}
+
+type CreateLeaveRequest struct {
+	StartsAt time.Time `json:"starts_at" binding:"required"`
+	EndsAt   time.Time `json:"ends_at" binding:"required"`
+	Reason   string    `json:"reason"`
+}
+
+type CreateScheduleExceptionRequest struct {
+	Date      string `json:"date" binding:"required"`
+	Available bool   `json:"available"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+	Reason    string `json:"reason"`
+}
+
+// SlotQuery asks for open slots of one or more doctors between two calendar
+// dates, inclusive. The slot length comes from AppointmentTypeID or
+// DurationMinutes. Slots are reported in TimeZone, or in each doctor's own
+// time zone when it is empty.
+type SlotQuery struct {
+	DoctorIDs         []uint `form:"doctor_id" binding:"required,min=1,max=20"`
+	From              string `form:"from" binding:"required"`
+	To                string `form:"to"`
+	AppointmentTypeID uint   `form:"appointment_type_id"`
+	DurationMinutes   int    `form:"duration" binding:"omitempty,min=5,max=480"`
+	TimeZone          string `form:"tz"`
+}
+
+type Slot struct {
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+}
+
+type DoctorSlots struct {
+	DoctorID uint   `json:"doctor_id"`
+	TimeZone string `json:"time_zone"`
+	Slots    []Slot `json:"slots"`
+}
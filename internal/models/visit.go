@@ -0,0 +1,85 @@
+package models
+
+import "time"
+
+// Visit states. A visit moves forward through arrived, triaged and
+// in_consultation to done; arrived and triaged visits can also end as
+// no_show when the patient leaves before being seen.
+const (
+	VisitArrived        = "arrived"
+	VisitTriaged        = "triaged"
+	VisitInConsultation = "in_consultation"
+	VisitDone           = "done"
+	VisitNoShow         = "no_show"
+)
+
+// Visit priorities. Higher priorities are seen first.
+const (
+	PriorityRoutine   = 0
+	PriorityUrgent    = 1
+	PriorityEmergency = 2
+)
+
+// Visit is a patient's presence at the clinic from check-in until they have
+// been seen or have left. Active visits form each doctor's waiting queue.
+type Visit struct {
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	PatientID      uint           `json:"patient_id" gorm:"not null;index"`
+	Patient        PatientSummary `json:"patient" gorm:"foreignKey:PatientID"`
+	DoctorID       uint           `json:"doctor_id" gorm:"not null;index"`
+	Doctor         User           `json:"doctor" gorm:"foreignKey:DoctorID"`
+	AppointmentID  *uint          `json:"appointment_id,omitempty" gorm:"index"`
+	Appointment    *Appointment   `json:"appointment,omitempty"`
+	Status         string         `json:"status" gorm:"not null;index;check:status IN ('arrived','triaged','in_consultation','done','no_show')"`
+	Priority       int            `json:"priority" gorm:"not null;default:0"`
+	PriorityReason string         `json:"priority_reason,omitempty"`
+	Notes          string         `json:"notes" gorm:"type:text"`
+	CheckedInByID  uint           `json:"checked_in_by_id" gorm:"not null"`
+	ArrivedAt      time.Time      `json:"arrived_at" gorm:"type:timestamptz;not null"`
+	TriagedAt      *time.Time     `json:"triaged_at,omitempty" gorm:"type:timestamptz"`
+	StartedAt      *time.Time     `json:"started_at,omitempty" gorm:"type:timestamptz"`
+	CompletedAt    *time.Time     `json:"completed_at,omitempty" gorm:"type:timestamptz"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// Active reports whether the visit is still in a queue.
+func (v *Visit) Active() bool {
+	return v.Status == VisitArrived || v.Status == VisitTriaged || v.Status == VisitInConsultation
+}
+
+// QueueEntry is a visit together with its place in the doctor's queue.
+// Position is 0 for the patient in consultation and counts up from 1 for
+// those waiting.
+type QueueEntry struct {
+	Visit                Visit `json:"visit"`
+	Position             int   `json:"position"`
+	EstimatedWaitMinutes int   `json:"estimated_wait_minutes"`
+}
+
+// Queue is a doctor's live waiting queue.
+type Queue struct {
+	DoctorID                   uint         `json:"doctor_id"`
+	Entries                    []QueueEntry `json:"entries"`
+	AverageConsultationMinutes float64      `json:"average_consultation_minutes"`
+	AverageWaitMinutes         float64      `json:"average_wait_minutes"`
+	GeneratedAt                time.Time    `json:"generated_at"`
+}
+
+type CheckInRequest struct {
+	PatientID     uint   `json:"patient_id"`
+	DoctorID      uint   `json:"doctor_id"`
+	AppointmentID uint   `json:"appointment_id"`
+	Priority      int    `json:"priority" binding:"min=0,max=2"`
+	Notes         string `json:"notes"`
+}
+
+type UpdateVisitStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=triaged in_consultation done no_show"`
+	Notes  string `json:"notes"`
+}
+
+type UpdateVisitPriorityRequest struct {
+	Priority int    `json:"priority" binding:"min=0,max=2"`
+	Reason   string `json:"reason" binding:"required"`
+}
@@ -0,0 +1,132 @@
+package models
+
+import "time"
+
+// Units accepted when recording vitals. Temperatures are stored in degrees
+// Celsius, weights in kilograms and heights in centimetres.
+const (
+	UnitCelsius    = "C"
+	UnitFahrenheit = "F"
+	UnitKilogram   = "kg"
+	UnitPound      = "lb"
+	UnitCentimetre = "cm"
+	UnitInch       = "in"
+)
+
+// Levels of consciousness on the ACVPU scale.
+const (
+	ConsciousnessAlert        = "alert"
+	ConsciousnessConfusion    = "confusion"
+	ConsciousnessVoice        = "voice"
+	ConsciousnessPain         = "pain"
+	ConsciousnessUnresponsive = "unresponsive"
+)
+
+// NEWS2 clinical risk bands. LowMedium is a low aggregate score with a
+// single parameter scoring 3.
+const (
+	News2RiskLow       = "low"
+	News2RiskLowMedium = "low_medium"
+	News2RiskMedium    = "medium"
+	News2RiskHigh      = "high"
+)
+
+// VitalSigns is one set of observations taken from a patient. Any
+// measurement may be missing; the NEWS2 score is only calculated when all of
+// its parameters were observed.
+type VitalSigns struct {
+	ID                 uint       `json:"id" gorm:"primaryKey"`
+	PatientID          uint       `json:"patient_id" gorm:"not null;index:idx_vital_signs_patient_time"`
+	VisitID            *uint      `json:"visit_id,omitempty" gorm:"index"`
+	AdmissionID        *uint      `json:"admission_id,omitempty" gorm:"index"`
+	RecordedByID       uint       `json:"recorded_by_id" gorm:"not null"`
+	RecordedBy         User       `json:"recorded_by" gorm:"foreignKey:RecordedByID"`
+	RecordedAt         time.Time  `json:"recorded_at" gorm:"type:timestamptz;not null;index:idx_vital_signs_patient_time"`
+	SystolicBP         *int       `json:"systolic_bp,omitempty"`
+	DiastolicBP        *int       `json:"diastolic_bp,omitempty"`
+	HeartRate          *int       `json:"heart_rate,omitempty"`
+	RespiratoryRate    *int       `json:"respiratory_rate,omitempty"`
+	TemperatureC       *float64   `json:"temperature_c,omitempty"`
+	SpO2               *int       `json:"spo2,omitempty"`
+	SupplementalOxygen bool       `json:"supplemental_oxygen" gorm:"not null;default:false"`
+	Consciousness      string     `json:"consciousness,omitempty" gorm:"not null;default:'';check:consciousness IN ('','alert','confusion','voice','pain','unresponsive')"`
+	WeightKg           *float64   `json:"weight_kg,omitempty"`
+	HeightCm           *float64   `json:"height_cm,omitempty"`
+	BMI                *float64   `json:"bmi,omitempty"`
+	News2Score         *int       `json:"news2_score,omitempty"`
+	News2Risk          string     `json:"news2_risk,omitempty"`
+	Note               string     `json:"note" gorm:"type:text"`
+	AssignedDoctorID   *uint      `json:"assigned_doctor_id,omitempty" gorm:"index"`
+	Escalated          bool       `json:"escalated" gorm:"not null;default:false"`
+	AcknowledgedByID   *uint      `json:"acknowledged_by_id,omitempty"`
+	AcknowledgedAt     *time.Time `json:"acknowledged_at,omitempty" gorm:"type:timestamptz"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+func (VitalSigns) TableName() string {
+	return "vital_signs"
+}
+
+// RecordVitalsRequest records a set of observations. Temperature, weight and
+// height may be given in either unit; Celsius, kilograms and centimetres are
+// assumed when no unit is given. When height is left out, the patient's
+// last recorded height is used for the BMI.
+type RecordVitalsRequest struct {
+	PatientID          uint       `json:"patient_id" binding:"required"`
+	VisitID            uint       `json:"visit_id"`
+	RecordedAt         *time.Time `json:"recorded_at"`
+	SystolicBP         *int       `json:"systolic_bp" binding:"omitempty,min=30,max=300"`
+	DiastolicBP        *int       `json:"diastolic_bp" binding:"omitempty,min=10,max=200"`
+	HeartRate          *int       `json:"heart_rate" binding:"omitempty,min=10,max=300"`
+	RespiratoryRate    *int       `json:"respiratory_rate" binding:"omitempty,min=1,max=80"`
+	Temperature        *float64   `json:"temperature"`
+	TemperatureUnit    string     `json:"temperature_unit" binding:"omitempty,oneof=C F"`
+	SpO2               *int       `json:"spo2" binding:"omitempty,min=50,max=100"`
+	SupplementalOxygen bool       `json:"supplemental_oxygen"`
+	Consciousness      string     `json:"consciousness" binding:"omitempty,oneof=alert confusion voice pain unresponsive"`
+	Weight             *float64   `json:"weight"`
+	WeightUnit         string     `json:"weight_unit" binding:"omitempty,oneof=kg lb"`
+	Height             *float64   `json:"height"`
+	HeightUnit         string     `json:"height_unit" binding:"omitempty,oneof=cm in"`
+	Note               string     `json:"note"`
+}
+
+type VitalsFilter struct {
+	From time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To   time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// VitalSeriesFilter selects one measure of a patient's vitals over time.
+// Unit converts temperatures to F or weights to lb.
+type VitalSeriesFilter struct {
+	Measure string    `form:"measure" binding:"required,oneof=systolic_bp diastolic_bp heart_rate respiratory_rate temperature spo2 weight height bmi news2_score"`
+	Unit    string    `form:"unit" binding:"omitempty,oneof=C F kg lb cm in"`
+	From    time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To      time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+type VitalPoint struct {
+	VitalSignsID uint      `json:"vital_signs_id"`
+	RecordedAt   time.Time `json:"recorded_at"`
+	Value        float64   `json:"value"`
+}
+
+// VitalSeries is a patient's values of one measure, oldest first, ready to
+// be charted.
+type VitalSeries struct {
+	PatientID uint         `json:"patient_id"`
+	Measure   string       `json:"measure"`
+	Unit      string       `json:"unit"`
+	Points    []VitalPoint `json:"points"`
+	Min       *float64     `json:"min,omitempty"`
+	Max       *float64     `json:"max,omitempty"`
+	Latest    *float64     `json:"latest,omitempty"`
+}
+
+// VitalsAlert is the event sent to a doctor when a patient assigned to them
+// scores a NEWS2 risk above low.
+type VitalsAlert struct {
+	Vitals        VitalSigns `json:"vitals"`
+	Patient       Patient    `json:"patient"`
+	PreviousScore *int       `json:"previous_score,omitempty"`
+}
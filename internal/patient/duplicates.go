@@ -0,0 +1,146 @@
+package patient
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"hospital-management/internal/models"
+	"hospital-management/pkg/matching"
+)
+
+// Duplicate decisions, from most to least likely to be the same person.
+const (
+	DuplicateBlock = "block"
+	DuplicateWarn  = "warn"
+)
+
+// Probability thresholds for the duplicate decisions.
+const (
+	duplicateBlockThreshold = 0.9
+	duplicateWarnThreshold  = 0.5
+)
+
+// duplicatePriorWeight is log2 of the prior odds that two records picked by
+// the candidate query belong to the same person.
+const duplicatePriorWeight = -12.0
+
+// fieldWeights holds, per field, the probability that the field agrees when
+// two records are the same person (m) and when they are not (u), as in the
+// Fellegi-Sunter record linkage model.
+var fieldWeights = map[string]struct{ m, u float64 }{
+	"last_name":        {0.95, 0.01},
+	"first_name":       {0.90, 0.02},
+	"date_of_birth":    {0.97, 0.0003},
+	"phone":            {0.80, 0.0001},
+	"insurance_number": {0.95, 0.00001},
+	"email":            {0.90, 0.00001},
+	"address":          {0.70, 0.001},
+}
+
+// ScoreDuplicate estimates the probability that candidate is the same person
+// as req. Each field present on both sides contributes its agreement weight
+// log2(m/u), scaled by how closely it matches, or its disagreement weight
+// log2((1-m)/(1-u)). Fields missing on either side are neutral. The per-field
+// agreement levels in [0, 1] are returned alongside.
+func ScoreDuplicate(req models.CreatePatientRequest, candidate *models.Patient) (float64, map[string]float64) {
+	agreements := map[string]float64{}
+
+	if req.LastName != "" && candidate.LastName != "" {
+		agreements["last_name"] = matching.NameScore(req.LastName, candidate.LastName)
+	}
+	if req.FirstName != "" && candidate.FirstName != "" {
+		agreements["first_name"] = matching.NameScore(req.FirstName, candidate.FirstName)
+	}
+	if !req.DateOfBirth.IsZero() && !candidate.DateOfBirth.IsZero() {
+		agreements["date_of_birth"] = dateAgreement(req.DateOfBirth, candidate.DateOfBirth)
+	}
+	if req.Phone != "" && candidate.Phone != "" {
+		agreements["phone"] = boolAgreement(matching.PhoneMatches(req.Phone, candidate.Phone))
+	}
+	if a, b := normalizeIdentifier(req.InsuranceNumber), normalizeIdentifier(candidate.InsuranceNumber); a != "" && b != "" {
+		agreements["insurance_number"] = boolAgreement(a == b)
+	}
+	if req.Email != "" && candidate.Email != "" {
+		agreements["email"] = boolAgreement(strings.EqualFold(req.Email, candidate.Email))
+	}
+	if req.Address != "" && candidate.Address != "" {
+		agreements["address"] = matching.Similarity(req.Address, candidate.Address)
+	}
+
+	total := duplicatePriorWeight
+	for field, agreement := range agreements {
+		w := fieldWeights[field]
+		agree := math.Log2(w.m / w.u)
+		disagree := math.Log2((1 - w.m) / (1 - w.u))
+		// Partial agreement interpolates between the two weights; anything
+		// below one half counts as disagreement.
+		if agreement >= 0.5 {
+			total += disagree + (agree-disagree)*agreement
+		} else {
+			total += disagree
+		}
+	}
+
+	return 1 / (1 + math.Exp2(-total)), agreements
+}
+
+// FindDuplicates scores candidates against req and returns those at or above
+// the warning threshold, most likely first.
+func FindDuplicates(req models.CreatePatientRequest, candidates []models.Patient) []models.DuplicateCandidate {
+	duplicates := []models.DuplicateCandidate{}
+	for i := range candidates {
+		probability, fields := ScoreDuplicate(req, &candidates[i])
+		if probability < duplicateWarnThreshold {
+			continue
+		}
+
+		decision := DuplicateWarn
+		if probability >= duplicateBlockThreshold {
+			decision = DuplicateBlock
+		}
+
+		duplicates = append(duplicates, models.DuplicateCandidate{
+			Patient:     candidates[i],
+			Probability: probability,
+			Decision:    decision,
+			Fields:      fields,
+		})
+	}
+
+	sort.SliceStable(duplicates, func(i, j int) bool {
+		return duplicates[i].Probability > duplicates[j].Probability
+	})
+	return duplicates
+}
+
+// dateAgreement is 1 for the same date and 0.6 when only day and month are
+// swapped, a common data entry error.
+func dateAgreement(a, b time.Time) float64 {
+	ay, am, ad := a.UTC().Date()
+	by, bm, bd := b.UTC().Date()
+	switch {
+	case ay == by && am == bm && ad == bd:
+		return 1
+	case ay == by && int(am) == bd && ad == int(bm):
+		return 0.6
+	}
+	return 0
+}
+
+func boolAgreement(equal bool) float64 {
+	if equal {
+		return 1
+	}
+	return 0
+}
+
+func normalizeIdentifier(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(s) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
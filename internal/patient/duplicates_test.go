@@ -0,0 +1,91 @@
+package patient
+
+import (
+	"testing"
+	"hospital-management/internal/models"
+)
+
+func TestScoreDuplicate(t *testing.T) {
+	req := models.CreatePatientRequest{
+		FirstName:       "Émile",
+		LastName:        "Durand",
+		Email:           "emile.durand@example.com",
+		Phone:           "555-123-4567",
+		DateOfBirth:     date("1980-04-12"),
+		InsuranceNumber: "AB-123-456",
+	}
+
+	tests := []struct {
+		name      string
+		candidate models.Patient
+		min, max  float64
+	}{
+		{"same person, accents dropped", models.Patient{
+			FirstName: "Emile", LastName: "DURAND", Phone: "(555) 123 4567", DateOfBirth: date("1980-04-12"),
+		}, duplicateBlockThreshold, 1},
+		{"same insurance number written differently", models.Patient{
+			FirstName: "E", LastName: "Durand", InsuranceNumber: "ab123456",
+		}, duplicateBlockThreshold, 1},
+		{"day and month swapped", models.Patient{
+			FirstName: "Emile", LastName: "Durand", Phone: "555 123 4567", DateOfBirth: date("1980-12-04"),
+		}, duplicateWarnThreshold, 1},
+		{"namesake born another year", models.Patient{
+			FirstName: "Emile", LastName: "Durand", Phone: "555 999 0000", DateOfBirth: date("1952-07-30"),
+		}, 0, duplicateWarnThreshold},
+		{"different person sharing a birthday", models.Patient{
+			FirstName: "Sarah", LastName: "Jones", DateOfBirth: date("1980-04-12"),
+		}, 0, 0.01},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probability, fields := ScoreDuplicate(req, &tt.candidate)
+			if probability < tt.min || probability > tt.max {
+				t.Errorf("probability = %v, want within [%v, %v]; fields %v", probability, tt.min, tt.max, fields)
+			}
+		})
+	}
+}
+
+func TestScoreDuplicateIgnoresMissingFields(t *testing.T) {
+	req := models.CreatePatientRequest{FirstName: "Ana", LastName: "Silva"}
+	_, fields := ScoreDuplicate(req, &models.Patient{FirstName: "Ana", LastName: "Silva", Email: "ana@example.com"})
+
+	if _, ok := fields["email"]; ok {
+		t.Errorf("email was compared although the request has none: %v", fields)
+	}
+	if fields["last_name"] != 1 || fields["first_name"] != 1 {
+		t.Errorf("names should agree fully, got %v", fields)
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	req := models.CreatePatientRequest{
+		FirstName:   "John",
+		LastName:    "Smith",
+		Phone:       "555-123-4567",
+		DateOfBirth: date("1975-01-20"),
+	}
+	candidates := []models.Patient{
+		{ID: 1, FirstName: "Mary", LastName: "Smith", DateOfBirth: date("1990-03-03")},
+		{ID: 2, FirstName: "Jon", LastName: "Smith", DateOfBirth: date("1975-01-20")},
+		{ID: 3, FirstName: "John", LastName: "Smith", Phone: "5551234567", DateOfBirth: date("1975-01-20")},
+	}
+
+	duplicates := FindDuplicates(req, candidates)
+	if len(duplicates) != 2 {
+		t.Fatalf("got %d duplicates, want 2: %+v", len(duplicates), duplicates)
+	}
+	if duplicates[0].Patient.ID != 3 || duplicates[0].Decision != DuplicateBlock {
+		t.Errorf("first duplicate = patient %d (%s), want patient 3 (block)", duplicates[0].Patient.ID, duplicates[0].Decision)
+	}
+	if duplicates[1].Patient.ID != 2 {
+		t.Errorf("second duplicate = patient %d, want patient 2", duplicates[1].Patient.ID)
+	}
+	if duplicates[0].Probability < duplicates[1].Probability {
+		t.Errorf("duplicates not sorted by probability")
+	}
+
+	if none := FindDuplicates(req, nil); len(none) != 0 {
+		t.Errorf("got %d duplicates without candidates", len(none))
+	}
+}
@@ -1,21 +1,32 @@
 package patient
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
+	"hospital-management/internal/audit"
 	"hospital-management/internal/models"
 	"hospital-management/pkg/utils"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type Handler struct {
 	service *Service
+	audit   *audit.Service
 }
 
-func NewHandler(service *Service) *Handler {
-	return &Handler{service: service}
+func NewHandler(service *Service, auditService *audit.Service) *Handler {
+	return &Handler{service: service, audit: auditService}
 }
 
+// patientDiffIgnored lists fields left out of audit diffs because they change
+// on every write or are not part of the patient record itself.
+var patientDiffIgnored = []string{"updated_at", "version", "created_by_user"}
+
 func (h *Handler) CreatePatient(c *gin.Context) {
 	var req models.CreatePatientRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -23,27 +34,143 @@ func (h *Handler) CreatePatient(c *gin.Context) {
 		return
 	}
 
-	userInterface := c.MustGet("user")
-	user := userInterface.(map[string]interface{})
-	createdBy := uint(user["id"].(float64))
+	createdBy := utils.CurrentUserID(c)
 
-	patient, err := h.service.CreatePatient(req, createdBy)
+	patient, duplicates, err := h.service.CreatePatient(req, createdBy)
+	if err == ErrPossibleDuplicate {
+		utils.ErrorResponseWithData(c, http.StatusConflict, "Possible duplicate patient", err, duplicates)
+		return
+	}
+	if err == ErrOverrideNeedsReason {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create patient", err)
 		return
 	}
 
+	changes, _ := utils.DiffFields(nil, patient, patientDiffIgnored...)
+	h.audit.RecordPatient(c, "patient.create", patient.ID, changes)
+
+	if req.OverrideDuplicates && len(duplicates) > 0 {
+		overridden := make([]gin.H, 0, len(duplicates))
+		for _, d := range duplicates {
+			overridden = append(overridden, gin.H{
+				"patient_id":  d.Patient.ID,
+				"probability": d.Probability,
+				"decision":    d.Decision,
+			})
+		}
+		h.audit.Log(c, audit.Entry{
+			Action:       "patient.duplicate_override",
+			ResourceType: "patient",
+			ResourceID:   patient.ID,
+			PatientID:    &patient.ID,
+			Details: gin.H{
+				"reason":     req.OverrideReason,
+				"candidates": overridden,
+			},
+		})
+	}
+
+	// The body stays the patient; candidates that did not block the
+	// registration are named in a header for clients that want to review them.
+	if len(duplicates) > 0 {
+		ids := make([]string, 0, len(duplicates))
+		for _, d := range duplicates {
+			ids = append(ids, strconv.FormatUint(uint64(d.Patient.ID), 10))
+		}
+		c.Header("X-Possible-Duplicates", strings.Join(ids, ","))
+	}
+
 	utils.SuccessResponse(c, "Patient created successfully", patient)
 }
 
+func (h *Handler) CheckDuplicates(c *gin.Context) {
+	var req models.CreatePatientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	duplicates, err := h.service.CheckDuplicates(req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to check duplicates", err)
+		return
+	}
+
+	ids := make([]uint, 0, len(duplicates))
+	for _, d := range duplicates {
+		ids = append(ids, d.Patient.ID)
+	}
+	h.audit.Log(c, audit.Entry{
+		Action:       "patient.duplicate_check",
+		ResourceType: "patient",
+		Details:      gin.H{"patient_ids": ids},
+	})
+
+	utils.SuccessResponse(c, "Duplicate check completed", duplicates)
+}
+
 func (h *Handler) GetPatients(c *gin.Context) {
-	patients, err := h.service.GetAllPatients()
+	var filter models.PatientFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query", err)
+		return
+	}
+
+	patients, meta, err := h.service.ListPatients(filter)
+	if err == utils.ErrInvalidCursor {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid cursor", err)
+		return
+	}
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get patients", err)
 		return
 	}
 
-	utils.SuccessResponse(c, "Patients retrieved successfully", patients)
+	ids := make([]uint, 0, len(patients))
+	for _, p := range patients {
+		ids = append(ids, p.ID)
+	}
+	h.audit.Log(c, audit.Entry{
+		Action:       "patient.list",
+		ResourceType: "patient",
+		Details:      gin.H{"patient_ids": ids},
+	})
+
+	utils.PaginatedResponse(c, "Patients retrieved successfully", patients, meta)
+}
+
+func (h *Handler) SearchPatients(c *gin.Context) {
+	var req models.PatientSearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query", err)
+		return
+	}
+
+	matches, err := h.service.SearchPatients(req)
+	if err == ErrEmptySearch {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query", err)
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to search patients", err)
+		return
+	}
+
+	ids := make([]uint, 0, len(matches))
+	for _, m := range matches {
+		ids = append(ids, m.Patient.ID)
+	}
+	h.audit.Log(c, audit.Entry{
+		Action:       "patient.search",
+		ResourceType: "patient",
+		Details:      gin.H{"query": req, "patient_ids": ids},
+	})
+
+	utils.SuccessResponse(c, "Patients found", matches)
 }
 
 func (h *Handler) GetPatient(c *gin.Context) {
@@ -53,12 +180,20 @@ func (h *Handler) GetPatient(c *gin.Context) {
 		return
 	}
 
-	patient, err := h.service.GetPatientByID(uint(id))
+	patient, redirected, err := h.service.ResolvePatient(uint(id))
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusNotFound, "Patient not found", err)
 		return
 	}
 
+	if redirected {
+		c.Header("X-Patient-Merged-From", c.Param("id"))
+	}
+
+	h.audit.RecordPatient(c, "patient.read", patient.ID, nil)
+	if utils.NotModified(c, patient.Version) {
+		return
+	}
 	utils.SuccessResponse(c, "Patient retrieved successfully", patient)
 }
 
@@ -69,18 +204,83 @@ func (h *Handler) UpdatePatient(c *gin.Context) {
 		return
 	}
 
+	match, ok := utils.IfMatch(c)
+	if !ok {
+		return
+	}
+
 	var req models.UpdatePatientRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
 		return
 	}
 
-	patient, err := h.service.UpdatePatient(uint(id), req)
+	before, err := h.service.GetPatientByID(uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Patient not found", err)
+		return
+	}
+
+	patient, err := h.service.UpdatePatient(uint(id), match, req, utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to update patient", err)
+		return
+	}
+
+	changes, _ := utils.DiffFields(before, patient, patientDiffIgnored...)
+	h.audit.RecordPatient(c, "patient.update", patient.ID, changes)
+
+	utils.SetETag(c, patient.Version)
+	utils.SuccessResponse(c, "Patient updated successfully", patient)
+}
+
+// PatchPatient applies an RFC 7396 JSON Merge Patch to a patient.
+func (h *Handler) PatchPatient(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update patient", err)
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	match, ok := utils.IfMatch(c)
+	if !ok {
+		return
+	}
+
+	switch c.ContentType() {
+	case "application/merge-patch+json", "application/json":
+	default:
+		utils.ErrorResponse(c, http.StatusUnsupportedMediaType, "Content-Type must be application/merge-patch+json", nil)
 		return
 	}
 
+	var patch map[string]json.RawMessage
+	if err := json.NewDecoder(c.Request.Body).Decode(&patch); err != nil || patch == nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Request body must be a JSON object", err)
+		return
+	}
+
+	before, err := h.service.GetPatientByID(uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Patient not found", err)
+		return
+	}
+
+	patient, err := h.service.PatchPatient(uint(id), match, patch, utils.CurrentPermissions(c), utils.CurrentUserID(c))
+	if err != nil {
+		var patchErr *PatchError
+		if errors.As(err, &patchErr) {
+			utils.ErrorResponseWithData(c, statusFor(err), "Invalid patch", err, patchErr)
+			return
+		}
+		utils.ErrorResponse(c, statusFor(err), "Failed to update patient", err)
+		return
+	}
+
+	changes, _ := utils.DiffFields(before, patient, patientDiffIgnored...)
+	h.audit.RecordPatient(c, "patient.patch", patient.ID, changes)
+
+	utils.SetETag(c, patient.Version)
 	utils.SuccessResponse(c, "Patient updated successfully", patient)
 }
 
@@ -91,11 +291,25 @@ func (h *Handler) DeletePatient(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.DeletePatient(uint(id)); err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete patient", err)
+	match, ok := utils.IfMatch(c)
+	if !ok {
+		return
+	}
+
+	before, err := h.service.GetPatientByID(uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Patient not found", err)
+		return
+	}
+
+	if err := h.service.DeletePatient(uint(id), match, utils.CurrentUserID(c)); err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to delete patient", err)
 		return
 	}
 
+	changes, _ := utils.DiffFields(before, nil, patientDiffIgnored...)
+	h.audit.RecordPatient(c, "patient.delete", before.ID, changes)
+
 	utils.SuccessResponse(c, "Patient deleted successfully", nil)
 }
 
@@ -106,17 +320,273 @@ func (h *Handler) UpdateMedicalInfo(c *gin.Context) {
 		return
 	}
 
+	match, ok := utils.IfMatch(c)
+	if !ok {
+		return
+	}
+
 	var req models.UpdateMedicalInfoRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
 		return
 	}
 
-	patient, err := h.service.UpdateMedicalInfo(uint(id), req)
+	before, err := h.service.GetPatientByID(uint(id))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update medical info", err)
+		utils.ErrorResponse(c, http.StatusNotFound, "Patient not found", err)
 		return
 	}
 
+	patient, err := h.service.UpdateMedicalInfo(uint(id), match, req, utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to update medical info", err)
+		return
+	}
+
+	changes, _ := utils.DiffFields(before, patient, patientDiffIgnored...)
+	h.audit.RecordPatient(c, "patient.medical_info.update", patient.ID, changes)
+
+	utils.SetETag(c, patient.Version)
 	utils.SuccessResponse(c, "Medical information updated successfully", patient)
-}
\ No newline at end of file
+}
+
+func (h *Handler) MergePatient(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	var req models.MergePatientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	merge, err := h.service.MergePatients(uint(id), req, utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to merge patients", err)
+		return
+	}
+
+	details := gin.H{"merge_id": merge.ID, "source_id": merge.SourceID, "target_id": merge.TargetID, "reason": merge.Reason}
+	h.audit.Log(c, audit.Entry{Action: "patient.merge", ResourceType: "patient", ResourceID: merge.TargetID, PatientID: &merge.TargetID, Details: details})
+	h.audit.Log(c, audit.Entry{Action: "patient.merge", ResourceType: "patient", ResourceID: merge.SourceID, PatientID: &merge.SourceID, Details: details})
+
+	utils.SuccessResponse(c, "Patients merged successfully", merge)
+}
+
+func (h *Handler) UnmergePatient(c *gin.Context) {
+	mergeID, err := strconv.ParseUint(c.Param("mergeId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid merge ID", err)
+		return
+	}
+
+	merge, err := h.service.UnmergePatients(uint(mergeID), utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to unmerge patients", err)
+		return
+	}
+
+	details := gin.H{"merge_id": merge.ID, "source_id": merge.SourceID, "target_id": merge.TargetID}
+	h.audit.Log(c, audit.Entry{Action: "patient.unmerge", ResourceType: "patient", ResourceID: merge.TargetID, PatientID: &merge.TargetID, Details: details})
+	h.audit.Log(c, audit.Entry{Action: "patient.unmerge", ResourceType: "patient", ResourceID: merge.SourceID, PatientID: &merge.SourceID, Details: details})
+
+	utils.SuccessResponse(c, "Patients unmerged successfully", merge)
+}
+
+func (h *Handler) GetMergeHistory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	merges, err := h.service.ListMerges(uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get merge history", err)
+		return
+	}
+
+	h.audit.RecordPatient(c, "patient.merges.read", uint(id), nil)
+	utils.SuccessResponse(c, "Merge history retrieved successfully", merges)
+}
+
+func (h *Handler) GetRevisions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	revisions, err := h.service.ListRevisions(uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get revisions", err)
+		return
+	}
+
+	h.audit.RecordPatient(c, "patient.revisions.list", uint(id), nil)
+	utils.SuccessResponse(c, "Revisions retrieved successfully", revisions)
+}
+
+func (h *Handler) GetRevision(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid version", err)
+		return
+	}
+
+	revision, err := h.service.GetRevision(uint(id), version)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to get revision", err)
+		return
+	}
+
+	h.audit.Log(c, audit.Entry{
+		Action:       "patient.revision.read",
+		ResourceType: "patient",
+		ResourceID:   uint(id),
+		PatientID:    &revision.PatientID,
+		Details:      gin.H{"version": version},
+	})
+	utils.SuccessResponse(c, "Revision retrieved successfully", revision)
+}
+
+func (h *Handler) GetPatientAsOf(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	at, err := time.Parse(time.RFC3339, c.Query("at"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid timestamp, expected RFC3339", err)
+		return
+	}
+
+	revision, err := h.service.GetPatientAsOf(uint(id), at)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to get patient history", err)
+		return
+	}
+
+	h.audit.Log(c, audit.Entry{
+		Action:       "patient.revision.read",
+		ResourceType: "patient",
+		ResourceID:   uint(id),
+		PatientID:    &revision.PatientID,
+		Details:      gin.H{"as_of": at, "version": revision.Version},
+	})
+	utils.SuccessResponse(c, "Patient history retrieved successfully", revision)
+}
+
+func (h *Handler) DiffRevisions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	from, errFrom := strconv.Atoi(c.Query("from"))
+	to, errTo := strconv.Atoi(c.Query("to"))
+	if errFrom != nil || errTo != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "from and to must be version numbers", nil)
+		return
+	}
+
+	patientID := uint(id)
+	changes, err := h.service.DiffRevisions(patientID, from, to)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to compare revisions", err)
+		return
+	}
+
+	h.audit.Log(c, audit.Entry{
+		Action:       "patient.revision.diff",
+		ResourceType: "patient",
+		ResourceID:   patientID,
+		PatientID:    &patientID,
+		Details:      gin.H{"from": from, "to": to},
+	})
+	utils.SuccessResponse(c, "Revisions compared successfully", gin.H{
+		"from":    from,
+		"to":      to,
+		"changes": changes,
+	})
+}
+
+// statusFor maps service errors to HTTP status codes.
+func statusFor(err error) int {
+	var patchErr *PatchError
+	if errors.As(err, &patchErr) {
+		if patchErr.Forbidden {
+			return http.StatusForbidden
+		}
+		return http.StatusBadRequest
+	}
+	var conflictErr *MergeConflictError
+	if errors.As(err, &conflictErr) {
+		return http.StatusConflict
+	}
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, ErrMergeNotFound), errors.Is(err, ErrRevisionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrPatientMerged), errors.Is(err, ErrAlreadyUnmerged),
+		errors.Is(err, ErrUnmergeWindowEnded), errors.Is(err, ErrTargetMergedSince):
+		return http.StatusConflict
+	case errors.Is(err, ErrSelfMerge):
+		return http.StatusBadRequest
+	case errors.Is(err, utils.ErrVersionConflict):
+		return http.StatusPreconditionFailed
+	}
+	return http.StatusInternalServerError
+}
+
+func (h *Handler) GetDeletedPatients(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(utils.DefaultPageSize)))
+
+	patients, meta, err := h.service.ListDeletedPatients(page, pageSize)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get deleted patients", err)
+		return
+	}
+
+	ids := make([]uint, 0, len(patients))
+	for _, p := range patients {
+		ids = append(ids, p.ID)
+	}
+	h.audit.Log(c, audit.Entry{
+		Action:       "patient.deleted.list",
+		ResourceType: "patient",
+		Details:      gin.H{"patient_ids": ids},
+	})
+
+	utils.PaginatedResponse(c, "Deleted patients retrieved successfully", patients, meta)
+}
+
+func (h *Handler) RestorePatient(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	patient, err := h.service.RestorePatient(uint(id), utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to restore patient", err)
+		return
+	}
+
+	h.audit.RecordPatient(c, "patient.restore", patient.ID, nil)
+	utils.SuccessResponse(c, "Patient restored successfully", patient)
+}
@@ -0,0 +1,166 @@
+package patient
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+	"hospital-management/internal/models"
+	"hospital-management/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Revision actions.
+const (
+	RevisionCreate  = "create"
+	RevisionUpdate  = "update"
+	RevisionDelete  = "delete"
+	RevisionRestore = "restore"
+	RevisionMerge   = "merge"
+	RevisionUnmerge = "unmerge"
+	// RevisionBaseline is the first revision of a patient stored before
+	// revisions were kept.
+	RevisionBaseline = "baseline"
+)
+
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// revisionIgnored lists fields that are not versioned: they change on every
+// write or are loaded from another table.
+var revisionIgnored = []string{"updated_at", "version", "created_by_user"}
+
+// snapshot returns the versioned fields of a patient keyed by JSON name.
+func snapshot(patient *models.Patient) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(patient)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return nil, err
+	}
+	delete(fields, "created_by_user")
+	return fields, nil
+}
+
+// RecordBaselines gives every patient without history, deleted ones
+// included, a first revision holding its current state, so that later
+// revisions have something to be compared with.
+func RecordBaselines(db *gorm.DB) error {
+	var patients []models.Patient
+	return db.Unscoped().
+		Where("NOT EXISTS (SELECT 1 FROM patient_revisions r WHERE r.patient_id = patients.id)").
+		FindInBatches(&patients, 500, func(tx *gorm.DB, batch int) error {
+			for i := range patients {
+				fields, err := snapshot(&patients[i])
+				if err != nil {
+					return err
+				}
+				encoded, err := json.Marshal(fields)
+				if err != nil {
+					return err
+				}
+				if err := db.Create(&models.PatientRevision{
+					PatientID:   patients[i].ID,
+					Version:     1,
+					Action:      RevisionBaseline,
+					ChangedByID: patients[i].CreatedBy,
+					ChangedAt:   patients[i].UpdatedAt,
+					Snapshot:    models.JSONText(encoded),
+				}).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
+
+// recordRevision stores the next version of a patient. before is nil for a
+// newly created record. It must run inside the transaction that made the
+// change, after the patient row has been locked or inserted.
+func recordRevision(tx *gorm.DB, before, after *models.Patient, action string, changedBy uint) error {
+	afterFields, err := snapshot(after)
+	if err != nil {
+		return err
+	}
+
+	var beforeFields map[string]interface{}
+	if before != nil {
+		if beforeFields, err = snapshot(before); err != nil {
+			return err
+		}
+	}
+
+	changes, err := utils.DiffFields(beforeFields, afterFields, revisionIgnored...)
+	if err != nil {
+		return err
+	}
+
+	encodedChanges, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+	encodedSnapshot, err := json.Marshal(afterFields)
+	if err != nil {
+		return err
+	}
+
+	var version int
+	if err := tx.Model(&models.PatientRevision{}).
+		Where("patient_id = ?", after.ID).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&version).Error; err != nil {
+		return err
+	}
+
+	return tx.Create(&models.PatientRevision{
+		PatientID:   after.ID,
+		Version:     version + 1,
+		Action:      action,
+		ChangedByID: changedBy,
+		ChangedAt:   time.Now(),
+		Changes:     models.JSONText(encodedChanges),
+		Snapshot:    models.JSONText(encodedSnapshot),
+	}).Error
+}
+
+// lockPatient loads a patient, including soft-deleted ones, and locks its row
+// until the transaction ends.
+func lockPatient(tx *gorm.DB, id uint) (*models.Patient, error) {
+	var patient models.Patient
+	err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).First(&patient, id).Error
+	return &patient, err
+}
+
+// ListRevisions returns a patient's revisions, newest first, without their
+// snapshots.
+func (r *Repository) ListRevisions(patientID uint) ([]models.PatientRevision, error) {
+	var revisions []models.PatientRevision
+	err := r.db.Omit("snapshot").
+		Where("patient_id = ?", patientID).
+		Order("version DESC").
+		Find(&revisions).Error
+	return revisions, err
+}
+
+func (r *Repository) GetRevision(patientID uint, version int) (*models.PatientRevision, error) {
+	var revision models.PatientRevision
+	err := r.db.Where("patient_id = ? AND version = ?", patientID, version).First(&revision).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRevisionNotFound
+	}
+	return &revision, err
+}
+
+// GetRevisionAsOf returns the revision that was current at the given time.
+func (r *Repository) GetRevisionAsOf(patientID uint, at time.Time) (*models.PatientRevision, error) {
+	var revision models.PatientRevision
+	err := r.db.Where("patient_id = ? AND changed_at <= ?", patientID, at).
+		Order("version DESC").
+		First(&revision).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRevisionNotFound
+	}
+	return &revision, err
+}
@@ -0,0 +1,227 @@
+package patient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"hospital-management/internal/models"
+	"hospital-management/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrSelfMerge          = errors.New("a patient cannot be merged into itself")
+	ErrPatientMerged      = errors.New("patient has been merged into another record")
+	ErrMergeNotFound      = errors.New("merge not found")
+	ErrAlreadyUnmerged    = errors.New("merge has already been undone")
+	ErrUnmergeWindowEnded = errors.New("merge can no longer be undone")
+	ErrTargetMergedSince  = errors.New("surviving patient has since been merged; undo that merge first")
+)
+
+// MergeConflictError reports that records of the two patients cannot be
+// combined, e.g. because both have an active visit, an active admission or
+// overlapping appointments. The conflicting records must be closed or
+// cancelled before merging.
+type MergeConflictError struct {
+	Table      string
+	Constraint string
+}
+
+func (e *MergeConflictError) Error() string {
+	return fmt.Sprintf("both patients have %s that cannot be combined (%s); close or cancel one of them first",
+		strings.ReplaceAll(e.Table, "_", " "), e.Constraint)
+}
+
+// moveConflict turns a constraint violation raised while moving rows of
+// table into a MergeConflictError.
+func moveConflict(table string, err error) error {
+	if constraint, ok := utils.Conflict(err); ok {
+		return &MergeConflictError{Table: table, Constraint: constraint}
+	}
+	return err
+}
+
+// maxMergeHops bounds how far GetPatient follows tombstone redirects.
+const maxMergeHops = 10
+
+// relatedTables lists the tables whose rows belong to a patient through a
+// patient_id column. Merging moves these rows from the source patient to the
+// target; unmerging moves the same rows back. Every table that references
+// patients must be listed here, after any listed table that references it,
+// since the retention purge deletes from them in this order.
+var relatedTables = []string{
+	"allergy_intolerances",
+	"prescription_alerts",
+	"prescription_refills",
+	"prescriptions",
+	"lab_results",
+	"lab_orders",
+	"discharge_summaries",
+	"vital_signs",
+	"admission_transfers",
+	"admissions",
+	"encounter_diagnoses",
+	"problems",
+	"encounter_addenda",
+	"encounters",
+	"visits",
+	"appointments",
+}
+
+// Merge moves every related record from source to target and turns source
+// into a tombstone that redirects to target.
+func (r *Repository) Merge(sourceID, targetID, mergedBy uint, reason string) (*models.PatientMerge, error) {
+	merge := &models.PatientMerge{
+		SourceID:   sourceID,
+		TargetID:   targetID,
+		Reason:     reason,
+		MergedByID: mergedBy,
+		MergedAt:   time.Now(),
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var patients []models.Patient
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", []uint{sourceID, targetID}).
+			Find(&patients).Error; err != nil {
+			return err
+		}
+		if len(patients) != 2 {
+			return gorm.ErrRecordNotFound
+		}
+		for _, p := range patients {
+			if p.MergedIntoID != nil {
+				return ErrPatientMerged
+			}
+		}
+
+		moved := map[string][]uint{}
+		for _, table := range relatedTables {
+			var ids []uint
+			if err := tx.Raw(fmt.Sprintf("UPDATE %s SET patient_id = ? WHERE patient_id = ? RETURNING id", table), targetID, sourceID).
+				Scan(&ids).Error; err != nil {
+				return moveConflict(table, err)
+			}
+			if len(ids) > 0 {
+				moved[table] = ids
+			}
+		}
+
+		encoded, err := json.Marshal(moved)
+		if err != nil {
+			return err
+		}
+		merge.MovedRecords = string(encoded)
+
+		before, err := lockPatient(tx, sourceID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Patient{}).Where("id = ?", sourceID).Updates(map[string]interface{}{
+			"merged_into_id": targetID,
+			"merged_at":      merge.MergedAt,
+			"version":        gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		after, err := lockPatient(tx, sourceID)
+		if err != nil {
+			return err
+		}
+		if err := recordRevision(tx, before, after, RevisionMerge, mergedBy); err != nil {
+			return err
+		}
+
+		return tx.Create(merge).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return merge, nil
+}
+
+// Unmerge moves the rows recorded by a merge back to the source patient and
+// restores it. Rows added to the target after the merge stay there.
+func (r *Repository) Unmerge(mergeID, unmergedBy uint, window time.Duration) (*models.PatientMerge, error) {
+	var merge models.PatientMerge
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&merge, mergeID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrMergeNotFound
+			}
+			return err
+		}
+		if merge.UnmergedAt != nil {
+			return ErrAlreadyUnmerged
+		}
+		if time.Since(merge.MergedAt) > window {
+			return ErrUnmergeWindowEnded
+		}
+
+		var target models.Patient
+		if err := tx.First(&target, merge.TargetID).Error; err != nil {
+			return err
+		}
+		if target.MergedIntoID != nil {
+			return ErrTargetMergedSince
+		}
+
+		moved := map[string][]uint{}
+		if err := json.Unmarshal([]byte(merge.MovedRecords), &moved); err != nil {
+			return err
+		}
+		for table, ids := range moved {
+			if err := tx.Exec(fmt.Sprintf("UPDATE %s SET patient_id = ? WHERE id IN ? AND patient_id = ?", table), merge.SourceID, ids, merge.TargetID).Error; err != nil {
+				return moveConflict(table, err)
+			}
+		}
+
+		before, err := lockPatient(tx, merge.SourceID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Patient{}).Where("id = ?", merge.SourceID).Updates(map[string]interface{}{
+			"merged_into_id": nil,
+			"merged_at":      nil,
+			"version":        gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		after, err := lockPatient(tx, merge.SourceID)
+		if err != nil {
+			return err
+		}
+		if err := recordRevision(tx, before, after, RevisionUnmerge, unmergedBy); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		merge.UnmergedAt = &now
+		merge.UnmergedByID = &unmergedBy
+		return tx.Save(&merge).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &merge, nil
+}
+
+func (r *Repository) ListMerges(patientID uint) ([]models.PatientMerge, error) {
+	var merges []models.PatientMerge
+	err := r.db.Where("source_id = ? OR target_id = ?", patientID, patientID).
+		Order("merged_at DESC").
+		Find(&merges).Error
+	return merges, err
+}
+
+func (r *Repository) GetMerge(id uint) (*models.PatientMerge, error) {
+	var merge models.PatientMerge
+	err := r.db.First(&merge, id).Error
+	return &merge, err
+}
@@ -0,0 +1,208 @@
+package patient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+	"hospital-management/internal/models"
+)
+
+// PatchError reports merge patch members that were rejected. Forbidden is
+// set when at least one member is not editable with the caller's
+// permissions; otherwise the patch failed validation.
+type PatchError struct {
+	Forbidden bool              `json:"-"`
+	Fields    map[string]string `json:"fields"`
+}
+
+func (e *PatchError) Error() string {
+	names := make([]string, 0, len(e.Fields))
+	for name := range e.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s: %s", name, e.Fields[name]))
+	}
+	return strings.Join(parts, "; ")
+}
+
+var bloodGroups = map[string]bool{
+	"A+": true, "A-": true, "B+": true, "B-": true,
+	"AB+": true, "AB-": true, "O+": true, "O-": true,
+}
+
+// patchField describes one patchable member. A field may be edited by a
+// caller holding any of the listed permissions. set receives nil when the
+// member is null and the field is nullable.
+type patchField struct {
+	permissions []string
+	nullable    bool
+	set         func(p *models.Patient, value *string) error
+}
+
+// patchFields is the whitelist of members accepted by PatchPatient.
+// Demographics need patient:write and clinical fields medical:write.
+var patchFields = map[string]patchField{
+	"first_name": {demographic, false, func(p *models.Patient, v *string) error {
+		return setRequired(&p.FirstName, v)
+	}},
+	"last_name": {demographic, false, func(p *models.Patient, v *string) error {
+		return setRequired(&p.LastName, v)
+	}},
+	"email": {demographic, true, func(p *models.Patient, v *string) error {
+		if v != nil {
+			if _, err := mail.ParseAddress(*v); err != nil {
+				return fmt.Errorf("must be a valid email address")
+			}
+		}
+		return setOptional(&p.Email, v)
+	}},
+	"phone": {demographic, false, func(p *models.Patient, v *string) error {
+		return setRequired(&p.Phone, v)
+	}},
+	"date_of_birth": {demographic, false, func(p *models.Patient, v *string) error {
+		dob, err := parseDate(*v)
+		if err != nil {
+			return err
+		}
+		if dob.After(time.Now()) {
+			return fmt.Errorf("must not be in the future")
+		}
+		p.DateOfBirth = dob
+		return nil
+	}},
+	"gender": {demographic, false, func(p *models.Patient, v *string) error {
+		switch *v {
+		case "male", "female", "other":
+			p.Gender = *v
+			return nil
+		}
+		return fmt.Errorf("must be one of male, female, other")
+	}},
+	"address": {demographic, true, func(p *models.Patient, v *string) error {
+		return setOptional(&p.Address, v)
+	}},
+	"emergency_contact": {demographic, true, func(p *models.Patient, v *string) error {
+		return setOptional(&p.EmergencyContact, v)
+	}},
+	"blood_group": {demographic, true, func(p *models.Patient, v *string) error {
+		if v != nil && !bloodGroups[strings.ToUpper(*v)] {
+			return fmt.Errorf("must be one of A+, A-, B+, B-, AB+, AB-, O+, O-")
+		}
+		if v != nil {
+			upper := strings.ToUpper(*v)
+			v = &upper
+		}
+		return setOptional(&p.BloodGroup, v)
+	}},
+	"insurance_number": {demographic, true, func(p *models.Patient, v *string) error {
+		return setOptional(&p.InsuranceNumber, v)
+	}},
+	"medical_history": {clinical, true, func(p *models.Patient, v *string) error {
+		return setOptional(&p.MedicalHistory, v)
+	}},
+	"current_medications": {clinical, true, func(p *models.Patient, v *string) error {
+		return setOptional(&p.CurrentMedications, v)
+	}},
+}
+
+var (
+	demographic = []string{models.PermissionPatientWrite}
+	clinical    = []string{models.PermissionMedicalWrite}
+)
+
+// applyMergePatch validates every member of patch before changing patient,
+// so a rejected patch leaves it untouched.
+func applyMergePatch(patient *models.Patient, patch map[string]json.RawMessage, permissions []string) error {
+	granted := make(map[string]bool, len(permissions))
+	for _, p := range permissions {
+		granted[p] = true
+	}
+
+	forbidden := &PatchError{Forbidden: true, Fields: map[string]string{}}
+	invalid := &PatchError{Fields: map[string]string{}}
+	updated := *patient
+
+	for name, raw := range patch {
+		field, ok := patchFields[name]
+		if !ok {
+			invalid.Fields[name] = "unknown or read-only field"
+			continue
+		}
+		if !allowed(field.permissions, granted) {
+			forbidden.Fields[name] = "not editable with your permissions"
+			continue
+		}
+
+		var value *string
+		if !bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			var s string
+			if err := json.Unmarshal(raw, &s); err != nil {
+				invalid.Fields[name] = "must be a string or null"
+				continue
+			}
+			s = strings.TrimSpace(s)
+			value = &s
+		} else if !field.nullable {
+			invalid.Fields[name] = "cannot be cleared"
+			continue
+		}
+
+		if err := field.set(&updated, value); err != nil {
+			invalid.Fields[name] = err.Error()
+		}
+	}
+
+	if len(forbidden.Fields) > 0 {
+		return forbidden
+	}
+	if len(invalid.Fields) > 0 {
+		return invalid
+	}
+
+	*patient = updated
+	return nil
+}
+
+func allowed(required []string, granted map[string]bool) bool {
+	for _, p := range required {
+		if granted[p] {
+			return true
+		}
+	}
+	return false
+}
+
+func setRequired(field *string, value *string) error {
+	if *value == "" {
+		return fmt.Errorf("must not be empty")
+	}
+	*field = *value
+	return nil
+}
+
+func setOptional(field *string, value *string) error {
+	if value == nil {
+		*field = ""
+		return nil
+	}
+	*field = *value
+	return nil
+}
+
+func parseDate(s string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("must be a date (YYYY-MM-DD) or RFC3339 timestamp")
+}
@@ -1,10 +1,32 @@
 package patient
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
 	"hospital-management/internal/models"
+	"hospital-management/pkg/matching"
+	"hospital-management/pkg/utils"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// sortColumns maps the sort fields accepted by PatientFilter to a column and
+// to the accessor used to build the next cursor from the last row returned.
+var sortColumns = map[string]struct {
+	column string
+	value  func(p *models.Patient) string
+	isTime bool
+}{
+	"id":                {"id", func(p *models.Patient) string { return strconv.FormatUint(uint64(p.ID), 10) }, false},
+	"first_name":        {"first_name", func(p *models.Patient) string { return p.FirstName }, false},
+	"last_name":         {"last_name", func(p *models.Patient) string { return p.LastName }, false},
+	"date_of_birth":     {"date_of_birth", func(p *models.Patient) string { return p.DateOfBirth.Format(time.RFC3339Nano) }, true},
+	"registration_date": {"registration_date", func(p *models.Patient) string { return p.RegistrationDate.Format(time.RFC3339Nano) }, true},
+	"created_at":        {"created_at", func(p *models.Patient) string { return p.CreatedAt.Format(time.RFC3339Nano) }, true},
+}
+
 type Repository struct {
 	db *gorm.DB
 }
@@ -14,25 +36,368 @@ func NewRepository(db *gorm.DB) *Repository {
 }
 
 func (r *Repository) Create(patient *models.Patient) error {
-	return r.db.Create(patient).Error
+	foldNames(patient)
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(patient).Error; err != nil {
+			return err
+		}
+		return recordRevision(tx, nil, patient, RevisionCreate, patient.CreatedBy)
+	})
 }
 
-func (r *Repository) GetAll() ([]models.Patient, error) {
+// List returns one page of patients matching filter together with the page
+// metadata. Cursor pagination uses keyset comparisons on (sort column, id)
+// so it stays stable and cheap on large tables.
+func (r *Repository) List(filter models.PatientFilter) ([]models.Patient, *utils.Meta, error) {
+	page, pageSize := utils.NormalizePage(filter.Page, filter.PageSize)
+
+	sortField := filter.Sort
+	if sortField == "" {
+		sortField = "id"
+	}
+	sort := sortColumns[sortField]
+
+	direction, comparison := "ASC", ">"
+	if filter.Order == "desc" {
+		direction, comparison = "DESC", "<"
+	}
+
+	query := r.applyFilter(r.db.Model(&models.Patient{}), filter)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	query = query.Preload("CreatedByUser", utils.WithDeleted).
+		Order(fmt.Sprintf("%s %s, id %s", sort.column, direction, direction)).
+		Limit(pageSize + 1)
+
+	meta := &utils.Meta{Total: total, PageSize: pageSize}
+
+	if filter.Cursor != "" {
+		cursor, err := utils.DecodeCursor(filter.Cursor)
+		if err != nil || cursor.Sort != sortField {
+			return nil, nil, utils.ErrInvalidCursor
+		}
+
+		var value interface{} = cursor.Value
+		if sort.isTime {
+			t, err := time.Parse(time.RFC3339Nano, cursor.Value)
+			if err != nil {
+				return nil, nil, utils.ErrInvalidCursor
+			}
+			value = t
+		} else if sortField == "id" {
+			value = cursor.ID
+		}
+
+		query = query.Where(fmt.Sprintf("(%s, id) %s (?, ?)", sort.column, comparison), value, cursor.ID)
+	} else {
+		meta.Page = page
+		query = query.Offset((page - 1) * pageSize)
+	}
+
 	var patients []models.Patient
-	err := r.db.Preload("CreatedByUser").Find(&patients).Error
+	if err := query.Find(&patients).Error; err != nil {
+		return nil, nil, err
+	}
+
+	if len(patients) > pageSize {
+		patients = patients[:pageSize]
+		last := &patients[len(patients)-1]
+		meta.HasMore = true
+		meta.NextCursor = utils.EncodeCursor(utils.Cursor{
+			Sort:  sortField,
+			Value: sort.value(last),
+			ID:    last.ID,
+		})
+	}
+
+	return patients, meta, nil
+}
+
+func (r *Repository) applyFilter(query *gorm.DB, filter models.PatientFilter) *gorm.DB {
+	query = query.Where("merged_into_id IS NULL")
+	if filter.Gender != "" {
+		query = query.Where("gender = ?", filter.Gender)
+	}
+	if filter.BloodGroup != "" {
+		query = query.Where("blood_group = ?", filter.BloodGroup)
+	}
+	if filter.CreatedBy != 0 {
+		query = query.Where("created_by = ?", filter.CreatedBy)
+	}
+	if !filter.RegisteredFrom.IsZero() {
+		query = query.Where("registration_date >= ?", filter.RegisteredFrom)
+	}
+	if !filter.RegisteredTo.IsZero() {
+		query = query.Where("registration_date < ?", filter.RegisteredTo.AddDate(0, 0, 1))
+	}
+	if !filter.BornFrom.IsZero() {
+		query = query.Where("date_of_birth >= ?", filter.BornFrom)
+	}
+	if !filter.BornTo.IsZero() {
+		query = query.Where("date_of_birth < ?", filter.BornTo.AddDate(0, 0, 1))
+	}
+	return query
+}
+
+// searchCandidateLimit caps how many rows the database prefilter returns for
+// scoring in Go.
+const searchCandidateLimit = 500
+
+// blockingKeys collects the conditions of a candidate prefilter. A patient
+// is a candidate when any key matches. Candidates are ranked by the summed
+// weights of the keys they match, including rank-only keys such as a longer
+// name prefix, so that capping the set drops the least promising rows
+// rather than arbitrary ones.
+type blockingKeys struct {
+	conditions    []string
+	conditionVars []interface{}
+	ranks         []string
+	rankVars      []interface{}
+}
+
+// add adds a condition that selects candidates and adds weight to their
+// rank.
+func (k *blockingKeys) add(weight int, condition string, vars ...interface{}) {
+	k.conditions = append(k.conditions, "("+condition+")")
+	k.conditionVars = append(k.conditionVars, vars...)
+	k.rank(weight, condition, vars...)
+}
+
+// rank adds a condition that only orders candidates.
+func (k *blockingKeys) rank(weight int, condition string, vars ...interface{}) {
+	k.ranks = append(k.ranks, fmt.Sprintf("CASE WHEN %s THEN %d ELSE 0 END", condition, weight))
+	k.rankVars = append(k.rankVars, vars...)
+}
+
+// find loads up to searchCandidateLimit live, unmerged candidates, best
+// ranked first.
+func (k *blockingKeys) find(db *gorm.DB) ([]models.Patient, error) {
+	var patients []models.Patient
+	if len(k.conditions) == 0 {
+		return patients, nil
+	}
+
+	err := db.Model(&models.Patient{}).
+		Where(strings.Join(k.conditions, " OR "), k.conditionVars...).
+		Where("merged_into_id IS NULL").
+		Preload("CreatedByUser", utils.WithDeleted).
+		Clauses(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "(" + strings.Join(k.ranks, " + ") + ") DESC, id",
+			Vars:               k.rankVars,
+			WithoutParentheses: true,
+		}}).
+		Limit(searchCandidateLimit).
+		Find(&patients).Error
 	return patients, err
 }
 
+// namePrefix returns the first n letters of a normalised name word as a LIKE
+// prefix pattern.
+func namePrefix(word string, n int) string {
+	runes := []rune(word)
+	if len(runes) > n {
+		runes = runes[:n]
+	}
+	return string(runes) + "%"
+}
+
+// foldNames fills the patient's folded name keys from its names.
+func foldNames(patient *models.Patient) {
+	patient.FirstNameKey = matching.NormalizeName(patient.FirstName)
+	patient.LastNameKey = matching.NormalizeName(patient.LastName)
+}
+
+// SearchCandidates returns a broad set of patients that could match req:
+// any patient whose first or last name starts with the same letter as a query
+// word, whose phone ends in the same digits, or who shares the date of birth.
+// Names are compared without case or accents. Ranking is left to
+// RankMatches.
+func (r *Repository) SearchCandidates(req models.PatientSearchRequest) ([]models.Patient, error) {
+	var keys blockingKeys
+	for _, word := range matching.Tokens(req.Query) {
+		prefix := namePrefix(word, 1)
+		keys.add(1, "first_name_key LIKE ? OR last_name_key LIKE ?", prefix, prefix)
+		if closer := namePrefix(word, 3); closer != prefix {
+			keys.rank(2, "first_name_key LIKE ? OR last_name_key LIKE ?", closer, closer)
+		}
+	}
+	if suffix := matching.PhoneSuffix(req.Phone); len(suffix) >= 7 {
+		keys.add(3, "regexp_replace(phone, '[^0-9]', '', 'g') LIKE ?", "%"+suffix)
+	}
+	if !req.DateOfBirth.IsZero() {
+		day := req.DateOfBirth.UTC().Truncate(24 * time.Hour)
+		keys.add(2, "date_of_birth >= ? AND date_of_birth < ?", day, day.AddDate(0, 0, 1))
+	}
+	return keys.find(r.db)
+}
+
+// DuplicateCandidates returns patients that share at least one blocking key
+// with req: the first letter of the last name, the phone number, the date of
+// birth, the insurance number or the email address. Names are compared
+// without case or accents, and candidates sharing the strongest keys are
+// kept if the set has to be capped.
+func (r *Repository) DuplicateCandidates(req models.CreatePatientRequest) ([]models.Patient, error) {
+	// Weights follow the strength of each key, so that a single matching
+	// identifier outranks any combination of name keys.
+	var keys blockingKeys
+	if req.InsuranceNumber != "" {
+		keys.add(8, "UPPER(insurance_number) = UPPER(?)", req.InsuranceNumber)
+	}
+	if req.Email != "" {
+		keys.add(8, "LOWER(email) = LOWER(?)", req.Email)
+	}
+	if suffix := matching.PhoneSuffix(req.Phone); len(suffix) >= 7 {
+		keys.add(5, "regexp_replace(phone, '[^0-9]', '', 'g') LIKE ?", "%"+suffix)
+	}
+	if !req.DateOfBirth.IsZero() {
+		day := req.DateOfBirth.UTC().Truncate(24 * time.Hour)
+		keys.add(5, "date_of_birth >= ? AND date_of_birth < ?", day, day.AddDate(0, 0, 1))
+	}
+	if name := matching.NormalizeName(req.LastName); name != "" {
+		keys.add(1, "last_name_key LIKE ?", namePrefix(name, 1))
+		keys.rank(2, "last_name_key = ?", name)
+		if first := matching.NormalizeName(req.FirstName); first != "" {
+			keys.rank(1, "first_name_key LIKE ?", namePrefix(first, 1))
+		}
+	}
+	return keys.find(r.db)
+}
+
 func (r *Repository) GetByID(id uint) (*models.Patient, error) {
 	var patient models.Patient
-	err := r.db.Preload("CreatedByUser").First(&patient, id).Error
+	err := r.db.Preload("CreatedByUser", utils.WithDeleted).First(&patient, id).Error
 	return &patient, err
 }
 
-func (r *Repository) Update(patient *models.Patient) error {
-	return r.db.Save(patient).Error
+// Update saves patient and records the change as a new revision.
+// patient.Version must hold the version the change was based on; if the
+// stored row has moved on since, ErrVersionConflict is returned.
+func (r *Repository) Update(patient *models.Patient, changedBy uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		before, err := lockPatient(tx, patient.ID)
+		if err != nil {
+			return err
+		}
+		if before.Version != patient.Version {
+			return utils.ErrVersionConflict
+		}
+
+		patient.Version++
+		foldNames(patient)
+		if err := tx.Omit("CreatedByUser").Save(patient).Error; err != nil {
+			return err
+		}
+		return recordRevision(tx, before, patient, RevisionUpdate, changedBy)
+	})
+}
+
+// Delete soft-deletes a patient if it is still at the given version.
+func (r *Repository) Delete(id, version, deletedBy uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		before, err := lockPatient(tx, id)
+		if err != nil {
+			return err
+		}
+		if before.Version != version {
+			return utils.ErrVersionConflict
+		}
+
+		if err := tx.Model(&models.Patient{}).Where("id = ?", id).
+			Update("version", gorm.Expr("version + 1")).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&models.Patient{}, id).Error; err != nil {
+			return err
+		}
+
+		after, err := lockPatient(tx, id)
+		if err != nil {
+			return err
+		}
+		return recordRevision(tx, before, after, RevisionDelete, deletedBy)
+	})
+}
+
+func (r *Repository) ListDeleted(page, pageSize int) ([]models.Patient, *utils.Meta, error) {
+	page, pageSize = utils.NormalizePage(page, pageSize)
+	query := r.db.Unscoped().Model(&models.Patient{}).Where("deleted_at IS NOT NULL")
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var patients []models.Patient
+	err := query.Preload("CreatedByUser", utils.WithDeleted).
+		Order("deleted_at DESC, id DESC").
+		Limit(pageSize).
+		Offset((page - 1) * pageSize).
+		Find(&patients).Error
+
+	meta := &utils.Meta{
+		Total:    total,
+		PageSize: pageSize,
+		Page:     page,
+		HasMore:  int64(page*pageSize) < total,
+	}
+	return patients, meta, err
 }
 
-func (r *Repository) Delete(id uint) error {
-	return r.db.Delete(&models.Patient{}, id).Error
-}
\ No newline at end of file
+func (r *Repository) Restore(id, restoredBy uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		before, err := lockPatient(tx, id)
+		if err != nil {
+			return err
+		}
+		if !before.DeletedAt.Valid {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Unscoped().Model(&models.Patient{}).
+			Where("id = ?", id).
+			Updates(map[string]interface{}{
+				"deleted_at": nil,
+				"version":    gorm.Expr("version + 1"),
+			}).Error; err != nil {
+			return err
+		}
+
+		after, err := lockPatient(tx, id)
+		if err != nil {
+			return err
+		}
+		return recordRevision(tx, before, after, RevisionRestore, restoredBy)
+	})
+}
+
+// PurgeDeletedBefore permanently removes patients soft-deleted before cutoff
+// together with their related records, returning the purged IDs.
+func (r *Repository) PurgeDeletedBefore(cutoff time.Time) ([]uint, error) {
+	var ids []uint
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&models.Patient{}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		for _, table := range relatedTables {
+			if err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE patient_id IN ?", table), ids).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("patient_id IN ?", ids).Delete(&models.PatientRevision{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Delete(&models.Patient{}, ids).Error
+	})
+	return ids, err
+}
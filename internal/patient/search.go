@@ -0,0 +1,108 @@
+package patient
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"hospital-management/internal/models"
+	"hospital-management/pkg/matching"
+)
+
+// Relative weight of each criterion in a search score. Only the criteria
+// present in the request count towards the total.
+const (
+	searchNameWeight  = 0.5
+	searchPhoneWeight = 0.3
+	searchDOBWeight   = 0.2
+
+	// searchMinScore drops candidates that only share a first letter or a
+	// few digits with the query.
+	searchMinScore = 0.35
+)
+
+// ScoreMatch rates how well patient matches req in [0, 1] and lists the
+// criteria that matched. Each query word is compared with the patient's
+// first and last name and the best score is kept, so "smith john" and
+// "john smith" rank the same.
+func ScoreMatch(patient *models.Patient, req models.PatientSearchRequest) (float64, []string) {
+	var score, weight float64
+	matched := []string{}
+
+	if words := matching.Tokens(req.Query); len(words) > 0 {
+		var nameScore float64
+		for _, word := range words {
+			best := 0.0
+			for _, name := range []string{patient.FirstName, patient.LastName} {
+				for _, part := range matching.Tokens(name) {
+					if s := matching.NameScore(word, part); s > best {
+						best = s
+					}
+				}
+			}
+			nameScore += best
+		}
+		nameScore /= float64(len(words))
+
+		score += nameScore * searchNameWeight
+		weight += searchNameWeight
+		if nameScore >= 0.7 {
+			matched = append(matched, "name")
+		}
+	}
+
+	if req.Phone != "" {
+		weight += searchPhoneWeight
+		if matching.PhoneMatches(req.Phone, patient.Phone) {
+			score += searchPhoneWeight
+			matched = append(matched, "phone")
+		}
+	}
+
+	if !req.DateOfBirth.IsZero() {
+		weight += searchDOBWeight
+		if sameDate(req.DateOfBirth, patient.DateOfBirth) {
+			score += searchDOBWeight
+			matched = append(matched, "date_of_birth")
+		}
+	}
+
+	if weight == 0 {
+		return 0, matched
+	}
+	return score / weight, matched
+}
+
+// RankMatches scores candidates against req and returns those above the
+// minimum score, best first, up to limit.
+func RankMatches(candidates []models.Patient, req models.PatientSearchRequest, limit int) []models.PatientMatch {
+	matches := []models.PatientMatch{}
+	for i := range candidates {
+		score, matched := ScoreMatch(&candidates[i], req)
+		if score < searchMinScore {
+			continue
+		}
+		matches = append(matches, models.PatientMatch{
+			Patient:   candidates[i],
+			Score:     score,
+			MatchedOn: matched,
+		})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return strings.ToLower(matches[i].Patient.LastName) < strings.ToLower(matches[j].Patient.LastName)
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+func sameDate(a, b time.Time) bool {
+	ay, am, ad := a.UTC().Date()
+	by, bm, bd := b.UTC().Date()
+	return ay == by && am == bm && ad == bd
+}
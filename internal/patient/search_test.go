@@ -0,0 +1,109 @@
+package patient
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"hospital-management/internal/models"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestScoreMatch(t *testing.T) {
+	patient := &models.Patient{
+		FirstName:   "Émile",
+		LastName:    "Durand",
+		Phone:       "+1 (555) 123-4567",
+		DateOfBirth: date("1980-04-12"),
+	}
+
+	tests := []struct {
+		name    string
+		req     models.PatientSearchRequest
+		min     float64
+		matched []string
+	}{
+		{"name ignores accents and order", models.PatientSearchRequest{Query: "durand emile"}, 1, []string{"name"}},
+		{"misspelt name", models.PatientSearchRequest{Query: "emil durant"}, 0.7, []string{"name"}},
+		{"phone only", models.PatientSearchRequest{Phone: "555.123.4567"}, 1, []string{"phone"}},
+		{"all criteria", models.PatientSearchRequest{Query: "Durand", Phone: "5551234567", DateOfBirth: date("1980-04-12")}, 1,
+			[]string{"name", "phone", "date_of_birth"}},
+		{"wrong date of birth", models.PatientSearchRequest{Query: "Durand", DateOfBirth: date("1980-04-13")}, 0.5, []string{"name"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, matched := ScoreMatch(patient, tt.req)
+			if score < tt.min || score > 1 {
+				t.Errorf("score = %v, want within [%v, 1]", score, tt.min)
+			}
+			if !reflect.DeepEqual(matched, tt.matched) {
+				t.Errorf("matched on %q, want %q", matched, tt.matched)
+			}
+		})
+	}
+
+	if score, _ := ScoreMatch(patient, models.PatientSearchRequest{}); score != 0 {
+		t.Errorf("empty request scored %v, want 0", score)
+	}
+}
+
+func TestRankMatches(t *testing.T) {
+	candidates := []models.Patient{
+		{ID: 1, FirstName: "Dorothy", LastName: "Garcia"},
+		{ID: 2, FirstName: "John", LastName: "Smyth"},
+		{ID: 3, FirstName: "John", LastName: "Smith"},
+		{ID: 4, FirstName: "Jon", LastName: "Smith"},
+		{ID: 5, FirstName: "Sarah", LastName: "Jones"},
+	}
+
+	matches := RankMatches(candidates, models.PatientSearchRequest{Query: "john smith"}, 10)
+
+	var ids []uint
+	for _, m := range matches {
+		ids = append(ids, m.Patient.ID)
+	}
+	if len(ids) < 3 || ids[0] != 3 {
+		t.Fatalf("ranked %v, want John Smith (3) first and the near misses after", ids)
+	}
+	for _, id := range ids {
+		if id == 1 {
+			t.Errorf("ranked %v, want unrelated Dorothy Garcia (1) left out", ids)
+		}
+	}
+	for i := 1; i < len(matches); i++ {
+		if matches[i].Score > matches[i-1].Score {
+			t.Errorf("matches not sorted by score: %v", ids)
+		}
+	}
+
+	if limited := RankMatches(candidates, models.PatientSearchRequest{Query: "john smith"}, 2); len(limited) != 2 {
+		t.Errorf("got %d matches with limit 2", len(limited))
+	}
+	if none := RankMatches(candidates, models.PatientSearchRequest{Query: "zzyzx"}, 10); len(none) != 0 {
+		t.Errorf("got %d matches for an unrelated query, want 0", len(none))
+	}
+}
+
+func TestNamePrefix(t *testing.T) {
+	tests := []struct {
+		word string
+		n    int
+		want string
+	}{
+		{"emile", 1, "e%"},
+		{"łukasz", 1, "ł%"},
+		{"øyvind", 3, "øyv%"},
+		{"li", 3, "li%"},
+	}
+	for _, tt := range tests {
+		if got := namePrefix(tt.word, tt.n); got != tt.want {
+			t.Errorf("namePrefix(%q, %d) = %q, want %q", tt.word, tt.n, got, tt.want)
+		}
+	}
+}
@@ -1,19 +1,63 @@
 package patient
 
 import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"hospital-management/internal/config"
 	"hospital-management/internal/models"
+	"hospital-management/pkg/utils"
 	"time"
 )
 
+var (
+	ErrEmptySearch         = errors.New("at least one of q, phone or dob is required")
+	ErrPossibleDuplicate   = errors.New("patient is likely already registered")
+	ErrOverrideNeedsReason = errors.New("override_reason is required when overriding duplicates")
+)
+
 type Service struct {
-	repo *Repository
+	repo            *Repository
+	mergeUndoWindow time.Duration
+}
+
+func NewService(repo *Repository, cfg *config.Config) *Service {
+	return &Service{repo: repo, mergeUndoWindow: cfg.MergeUndoWindow}
 }
 
-func NewService(repo *Repository) *Service {
-	return &Service{repo: repo}
+// CheckDuplicates returns existing patients that are likely the same person
+// as req.
+func (s *Service) CheckDuplicates(req models.CreatePatientRequest) ([]models.DuplicateCandidate, error) {
+	candidates, err := s.repo.DuplicateCandidates(req)
+	if err != nil {
+		return nil, err
+	}
+	return FindDuplicates(req, candidates), nil
 }
 
-func (s *Service) CreatePatient(req models.CreatePatientRequest, createdBy uint) (*models.Patient, error) {
+// CreatePatient registers a patient after checking for duplicates. A
+// candidate above the block threshold stops registration with
+// ErrPossibleDuplicate unless the request overrides it with a reason. The
+// duplicates found are returned in every case so the caller can show or
+// record them.
+func (s *Service) CreatePatient(req models.CreatePatientRequest, createdBy uint) (*models.Patient, []models.DuplicateCandidate, error) {
+	if req.OverrideDuplicates && strings.TrimSpace(req.OverrideReason) == "" {
+		return nil, nil, ErrOverrideNeedsReason
+	}
+
+	duplicates, err := s.CheckDuplicates(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !req.OverrideDuplicates {
+		for _, d := range duplicates {
+			if d.Decision == DuplicateBlock {
+				return nil, duplicates, ErrPossibleDuplicate
+			}
+		}
+	}
+
 	patient := &models.Patient{
 		FirstName:        req.FirstName,
 		LastName:         req.LastName,
@@ -24,66 +68,254 @@ func (s *Service) CreatePatient(req models.CreatePatientRequest, createdBy uint)
 		Address:         req.Address,
 		EmergencyContact: req.EmergencyContact,
 		BloodGroup:      req.BloodGroup,
-		Allergies:       req.Allergies,
 		InsuranceNumber: req.InsuranceNumber,
 		RegistrationDate: time.Now(),
 		CreatedBy:       createdBy,
 	}
 
 	if err := s.repo.Create(patient); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return s.repo.GetByID(patient.ID)
+	created, err := s.repo.GetByID(patient.ID)
+	return created, duplicates, err
 }
 
-func (s *Service) GetAllPatients() ([]models.Patient, error) {
-	return s.repo.GetAll()
+func (s *Service) ListPatients(filter models.PatientFilter) ([]models.Patient, *utils.Meta, error) {
+	return s.repo.List(filter)
+}
+
+// SearchPatients finds patients by fuzzy name, phone and date of birth,
+// best match first.
+func (s *Service) SearchPatients(req models.PatientSearchRequest) ([]models.PatientMatch, error) {
+	if strings.TrimSpace(req.Query) == "" && req.Phone == "" && req.DateOfBirth.IsZero() {
+		return nil, ErrEmptySearch
+	}
+
+	candidates, err := s.repo.SearchCandidates(req)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = utils.DefaultPageSize
+	}
+	return RankMatches(candidates, req, limit), nil
 }
 
 func (s *Service) GetPatientByID(id uint) (*models.Patient, error) {
 	return s.repo.GetByID(id)
 }
 
-func (s *Service) UpdatePatient(id uint, req models.UpdatePatientRequest) (*models.Patient, error) {
+// GetActivePatient returns the patient with the given ID for attaching new
+// records. Tombstones left by a merge are refused with ErrPatientMerged,
+// since new records belong on the surviving patient.
+func (s *Service) GetActivePatient(id uint) (*models.Patient, error) {
+	patient, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if patient.MergedIntoID != nil {
+		return nil, ErrPatientMerged
+	}
+	return patient, nil
+}
+
+// ResolvePatient returns the patient with the given ID, following merge
+// redirects so that IDs of merged duplicates still lead to the surviving
+// record. The second result is true when a redirect was followed.
+func (s *Service) ResolvePatient(id uint) (*models.Patient, bool, error) {
 	patient, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, false, err
+	}
+
+	redirected := false
+	for hops := 0; patient.MergedIntoID != nil; hops++ {
+		if hops == maxMergeHops {
+			return nil, false, errors.New("too many merge redirects")
+		}
+		patient, err = s.repo.GetByID(*patient.MergedIntoID)
+		if err != nil {
+			return nil, false, err
+		}
+		redirected = true
+	}
+
+	return patient, redirected, nil
+}
+
+func (s *Service) UpdatePatient(id uint, match utils.Precondition, req models.UpdatePatientRequest, changedBy uint) (*models.Patient, error) {
+	patient, err := s.getMutable(id, match)
 	if err != nil {
 		return nil, err
 	}
 
-	// Update fields
+	// Empty fields are left unchanged; use PatchPatient to clear a field.
 	if req.FirstName != "" {
 		patient.FirstName = req.FirstName
 	}
 	if req.LastName != "" {
 		patient.LastName = req.LastName
 	}
-	// ... update other fields
+	if req.Email != "" {
+		patient.Email = req.Email
+	}
+	if req.Phone != "" {
+		patient.Phone = req.Phone
+	}
+	if !req.DateOfBirth.IsZero() {
+		patient.DateOfBirth = req.DateOfBirth
+	}
+	if req.Gender != "" {
+		patient.Gender = req.Gender
+	}
+	if req.Address != "" {
+		patient.Address = req.Address
+	}
+	if req.EmergencyContact != "" {
+		patient.EmergencyContact = req.EmergencyContact
+	}
+	if req.BloodGroup != "" {
+		patient.BloodGroup = req.BloodGroup
+	}
+	if req.InsuranceNumber != "" {
+		patient.InsuranceNumber = req.InsuranceNumber
+	}
 
-	if err := s.repo.Update(patient); err != nil {
+	if err := s.repo.Update(patient, changedBy); err != nil {
 		return nil, err
 	}
 
 	return s.repo.GetByID(id)
 }
 
-func (s *Service) DeletePatient(id uint) error {
-	return s.repo.Delete(id)
+// PatchPatient applies an RFC 7396 JSON Merge Patch to a patient. Members
+// set to null clear the field; members left out are unchanged. Every member
+// must be a field the caller's permissions allow them to edit.
+func (s *Service) PatchPatient(id uint, match utils.Precondition, patch map[string]json.RawMessage, permissions []string, changedBy uint) (*models.Patient, error) {
+	patient, err := s.getMutable(id, match)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyMergePatch(patient, patch, permissions); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(patient, changedBy); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(id)
 }
 
-func (s *Service) UpdateMedicalInfo(id uint, req models.UpdateMedicalInfoRequest) (*models.Patient, error) {
-	patient, err := s.repo.GetByID(id)
+func (s *Service) DeletePatient(id uint, match utils.Precondition, deletedBy uint) error {
+	patient, err := s.getMutable(id, match)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(id, patient.Version, deletedBy)
+}
+
+func (s *Service) UpdateMedicalInfo(id uint, match utils.Precondition, req models.UpdateMedicalInfoRequest, changedBy uint) (*models.Patient, error) {
+	patient, err := s.getMutable(id, match)
 	if err != nil {
 		return nil, err
 	}
 
 	patient.MedicalHistory = req.MedicalHistory
 	patient.CurrentMedications = req.CurrentMedications
-	patient.Allergies = req.Allergies
 
-	if err := s.repo.Update(patient); err != nil {
+	if err := s.repo.Update(patient, changedBy); err != nil {
 		return nil, err
 	}
 
 	return s.repo.GetByID(id)
-}
\ No newline at end of file
+}
+
+// MergePatients merges the duplicate req.SourceID into targetID.
+func (s *Service) MergePatients(targetID uint, req models.MergePatientRequest, mergedBy uint) (*models.PatientMerge, error) {
+	if req.SourceID == targetID {
+		return nil, ErrSelfMerge
+	}
+	return s.repo.Merge(req.SourceID, targetID, mergedBy, req.Reason)
+}
+
+// UnmergePatients undoes a merge within the configured undo window.
+func (s *Service) UnmergePatients(mergeID, unmergedBy uint) (*models.PatientMerge, error) {
+	return s.repo.Unmerge(mergeID, unmergedBy, s.mergeUndoWindow)
+}
+
+func (s *Service) ListMerges(patientID uint) ([]models.PatientMerge, error) {
+	return s.repo.ListMerges(patientID)
+}
+
+// getMutable loads a patient for modification, refusing merge tombstones
+// and records whose version the client's precondition does not accept.
+func (s *Service) getMutable(id uint, match utils.Precondition) (*models.Patient, error) {
+	patient, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if patient.MergedIntoID != nil {
+		return nil, ErrPatientMerged
+	}
+	if !match.Matches(patient.Version) {
+		return nil, utils.ErrVersionConflict
+	}
+	return patient, nil
+}
+
+func (s *Service) ListDeletedPatients(page, pageSize int) ([]models.Patient, *utils.Meta, error) {
+	return s.repo.ListDeleted(page, pageSize)
+}
+
+func (s *Service) RestorePatient(id, restoredBy uint) (*models.Patient, error) {
+	if err := s.repo.Restore(id, restoredBy); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(id)
+}
+
+// PurgeExpired permanently removes patients deleted more than retention ago.
+func (s *Service) PurgeExpired(retention time.Duration) ([]uint, error) {
+	return s.repo.PurgeDeletedBefore(time.Now().Add(-retention))
+}
+
+func (s *Service) ListRevisions(patientID uint) ([]models.PatientRevision, error) {
+	return s.repo.ListRevisions(patientID)
+}
+
+func (s *Service) GetRevision(patientID uint, version int) (*models.PatientRevision, error) {
+	return s.repo.GetRevision(patientID, version)
+}
+
+// GetPatientAsOf returns the revision of a patient that was current at the
+// given time; its snapshot is the record as it was then.
+func (s *Service) GetPatientAsOf(patientID uint, at time.Time) (*models.PatientRevision, error) {
+	return s.repo.GetRevisionAsOf(patientID, at)
+}
+
+// DiffRevisions compares the snapshots of two versions of a patient.
+func (s *Service) DiffRevisions(patientID uint, from, to int) (map[string]utils.FieldChange, error) {
+	fromRevision, err := s.repo.GetRevision(patientID, from)
+	if err != nil {
+		return nil, err
+	}
+	toRevision, err := s.repo.GetRevision(patientID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var before, after map[string]interface{}
+	if err := json.Unmarshal([]byte(fromRevision.Snapshot), &before); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(toRevision.Snapshot), &after); err != nil {
+		return nil, err
+	}
+
+	return utils.DiffFields(before, after, revisionIgnored...)
+}
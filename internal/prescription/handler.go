@@ -0,0 +1,276 @@
+package prescription
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"hospital-management/internal/audit"
+	"hospital-management/internal/cds"
+	"hospital-management/internal/encounter"
+	"hospital-management/internal/models"
+	"hospital-management/internal/patient"
+	"hospital-management/internal/user"
+	"hospital-management/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type Handler struct {
+	service *Service
+	audit   *audit.Service
+}
+
+func NewHandler(service *Service, auditService *audit.Service) *Handler {
+	return &Handler{service: service, audit: auditService}
+}
+
+// prescriptionDiffIgnored lists fields left out of audit diffs because they
+// change on every write or are loaded from other tables.
+var prescriptionDiffIgnored = []string{"updated_at", "patient", "prescriber", "refill_history", "alerts"}
+
+func (h *Handler) Prescribe(c *gin.Context) {
+	var req models.CreatePrescriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	prescription, alerts, err := h.service.Prescribe(req, utils.CurrentUserID(c))
+	if errors.Is(err, ErrOverrideRequired) {
+		h.audit.Log(c, audit.Entry{
+			Action:       "prescription.blocked",
+			ResourceType: "prescription",
+			PatientID:    &req.PatientID,
+			Details:      gin.H{"drug": req.Drug, "alerts": alerts},
+		})
+		utils.ErrorResponseWithData(c, statusFor(err), "Prescription needs an override", err, gin.H{"alerts": alerts})
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to write prescription", err)
+		return
+	}
+
+	changes, _ := utils.DiffFields(nil, prescription, prescriptionDiffIgnored...)
+	h.audit.Log(c, audit.Entry{
+		Action:       "prescription.create",
+		ResourceType: "prescription",
+		ResourceID:   prescription.ID,
+		PatientID:    &prescription.PatientID,
+		Changes:      changes,
+	})
+	if cds.Blocking(alerts) {
+		h.audit.Log(c, audit.Entry{
+			Action:       "prescription.override",
+			ResourceType: "prescription",
+			ResourceID:   prescription.ID,
+			PatientID:    &prescription.PatientID,
+			Details:      gin.H{"reason": req.OverrideReason, "alerts": alerts},
+		})
+	}
+
+	utils.SuccessResponse(c, "Prescription written successfully", prescription)
+}
+
+// CheckPrescription returns the alerts prescribing a drug to a patient would
+// raise, without prescribing it.
+func (h *Handler) CheckPrescription(c *gin.Context) {
+	var req models.CheckPrescriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	alerts, err := h.service.Check(req.PatientID, req.Drug)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to check prescription", err)
+		return
+	}
+
+	h.audit.Log(c, audit.Entry{
+		Action:       "prescription.check",
+		ResourceType: "prescription",
+		PatientID:    &req.PatientID,
+		Details:      gin.H{"drug": req.Drug, "alert_count": len(alerts)},
+	})
+	utils.SuccessResponse(c, "Prescription checked successfully", gin.H{
+		"alerts":   alerts,
+		"blocking": cds.Blocking(alerts),
+	})
+}
+
+func (h *Handler) GetPrescription(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid prescription ID", err)
+		return
+	}
+
+	prescription, err := h.service.GetPrescription(uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Prescription not found", err)
+		return
+	}
+
+	h.audit.Log(c, audit.Entry{
+		Action:       "prescription.read",
+		ResourceType: "prescription",
+		ResourceID:   prescription.ID,
+		PatientID:    &prescription.PatientID,
+	})
+	utils.SuccessResponse(c, "Prescription retrieved successfully", prescription)
+}
+
+func (h *Handler) DiscontinuePrescription(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid prescription ID", err)
+		return
+	}
+
+	var req models.DiscontinuePrescriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	prescription, err := h.service.Discontinue(uint(id), req.Reason, utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to discontinue prescription", err)
+		return
+	}
+
+	h.audit.Log(c, audit.Entry{
+		Action:       "prescription.discontinue",
+		ResourceType: "prescription",
+		ResourceID:   prescription.ID,
+		PatientID:    &prescription.PatientID,
+		Details:      gin.H{"reason": req.Reason},
+	})
+
+	utils.SuccessResponse(c, "Prescription discontinued successfully", prescription)
+}
+
+func (h *Handler) RefillPrescription(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid prescription ID", err)
+		return
+	}
+
+	var req models.RefillPrescriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	prescription, err := h.service.Refill(uint(id), req, utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to refill prescription", err)
+		return
+	}
+
+	h.audit.Log(c, audit.Entry{
+		Action:       "prescription.refill",
+		ResourceType: "prescription",
+		ResourceID:   prescription.ID,
+		PatientID:    &prescription.PatientID,
+		Details:      gin.H{"refills_used": prescription.RefillsUsed, "end_date": prescription.EndDate.Format("2006-01-02")},
+	})
+
+	utils.SuccessResponse(c, "Prescription refilled successfully", prescription)
+}
+
+// PrintPrescription returns the prescription as an HTML page for printing.
+func (h *Handler) PrintPrescription(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid prescription ID", err)
+		return
+	}
+
+	prescription, page, err := h.service.Print(uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to print prescription", err)
+		return
+	}
+
+	h.audit.Log(c, audit.Entry{
+		Action:       "prescription.print",
+		ResourceType: "prescription",
+		ResourceID:   prescription.ID,
+		PatientID:    &prescription.PatientID,
+	})
+	c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+}
+
+// GetPatientPrescriptions returns the patient's prescription history.
+func (h *Handler) GetPatientPrescriptions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	var filter models.PrescriptionFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query", err)
+		return
+	}
+
+	prescriptions, meta, err := h.service.ListForPatient(uint(id), filter)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to get prescriptions", err)
+		return
+	}
+
+	h.logList(c, "prescription.list", uint(id), prescriptions)
+	utils.PaginatedResponse(c, "Prescriptions retrieved successfully", prescriptions, meta)
+}
+
+// GetActiveMedications returns the patient's active medication list.
+func (h *Handler) GetActiveMedications(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	prescriptions, err := h.service.ActiveMedications(uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to get medications", err)
+		return
+	}
+
+	h.logList(c, "prescription.medications", uint(id), prescriptions)
+	utils.SuccessResponse(c, "Medications retrieved successfully", prescriptions)
+}
+
+// logList audits a listing of one patient's prescriptions.
+func (h *Handler) logList(c *gin.Context, action string, patientID uint, prescriptions []models.Prescription) {
+	ids := make([]uint, 0, len(prescriptions))
+	for _, p := range prescriptions {
+		ids = append(ids, p.ID)
+	}
+	h.audit.Log(c, audit.Entry{
+		Action:       action,
+		ResourceType: "prescription",
+		PatientID:    &patientID,
+		Details:      gin.H{"prescription_ids": ids},
+	})
+}
+
+// statusFor maps service errors to HTTP status codes.
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, user.ErrNotDoctor), errors.Is(err, encounter.ErrNotAuthor):
+		return http.StatusForbidden
+	case errors.Is(err, ErrDiscontinued), errors.Is(err, ErrNoRefillsLeft), errors.Is(err, ErrOverrideRequired):
+		return http.StatusConflict
+	case errors.Is(err, ErrInvalidStartDate), errors.Is(err, ErrEncounterMismatch), errors.Is(err, patient.ErrPatientMerged):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,133 @@
+package prescription
+
+import (
+	"time"
+	"hospital-management/internal/models"
+	"hospital-management/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// withDetails preloads what a prescription view shows.
+func withDetails(db *gorm.DB) *gorm.DB {
+	return db.Preload("Patient", utils.WithDeleted).
+		Preload("Prescriber", utils.WithDeleted).
+		Preload("RefillHistory", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at, id")
+		}).
+		Preload("RefillHistory.AuthorizedBy", utils.WithDeleted).
+		Preload("Alerts", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id")
+		})
+}
+
+// Create saves a prescription together with its alerts.
+func (r *Repository) Create(prescription *models.Prescription) error {
+	return r.db.Omit("Patient", "Prescriber", "RefillHistory").Create(prescription).Error
+}
+
+// Discontinue stops the prescription with the given ID as long as it is
+// still active, returning ErrDiscontinued when it was stopped first.
+func (r *Repository) Discontinue(id, discontinuedBy uint, reason string, at time.Time) error {
+	result := r.db.Model(&models.Prescription{}).
+		Where("id = ? AND status = ?", id, models.PrescriptionActive).
+		Updates(map[string]interface{}{
+			"status":             models.PrescriptionDiscontinued,
+			"discontinued_at":    at,
+			"discontinued_by_id": discontinuedBy,
+			"discontinue_reason": reason,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrDiscontinued
+	}
+	return nil
+}
+
+func (r *Repository) GetByID(id uint) (*models.Prescription, error) {
+	var prescription models.Prescription
+	err := withDetails(r.db).First(&prescription, id).Error
+	return &prescription, err
+}
+
+// Refill records a refill of the prescription with the given ID dispensed
+// on today, locking the prescription so that concurrent refills cannot
+// exceed the number authorized.
+func (r *Repository) Refill(id uint, refill *models.PrescriptionRefill, today time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var prescription models.Prescription
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&prescription, id).Error; err != nil {
+			return err
+		}
+		if prescription.Status != models.PrescriptionActive {
+			return ErrDiscontinued
+		}
+		if prescription.RefillsRemaining() <= 0 {
+			return ErrNoRefillsLeft
+		}
+
+		extendCourse(&prescription, today)
+		prescription.RefillsUsed++
+		if err := tx.Omit("Patient", "Prescriber", "RefillHistory", "Alerts").Save(&prescription).Error; err != nil {
+			return err
+		}
+
+		refill.PrescriptionID = prescription.ID
+		refill.PatientID = prescription.PatientID
+		refill.Quantity = prescription.Quantity
+		return tx.Omit("AuthorizedBy").Create(refill).Error
+	})
+}
+
+// ListForPatient returns one page of a patient's prescriptions, newest
+// first.
+func (r *Repository) ListForPatient(patientID uint, filter models.PrescriptionFilter) ([]models.Prescription, *utils.Meta, error) {
+	page, pageSize := utils.NormalizePage(filter.Page, filter.PageSize)
+
+	query := r.db.Model(&models.Prescription{}).Where("patient_id = ?", patientID)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var prescriptions []models.Prescription
+	err := withDetails(query).
+		Order("start_date DESC, id DESC").
+		Limit(pageSize).
+		Offset((page - 1) * pageSize).
+		Find(&prescriptions).Error
+
+	meta := &utils.Meta{
+		Total:    total,
+		PageSize: pageSize,
+		Page:     page,
+		HasMore:  int64(page*pageSize) < total,
+	}
+	return prescriptions, meta, err
+}
+
+// ListCurrent returns the patient's active prescriptions that have not run
+// out as of today: those still within their course or with refills left.
+func (r *Repository) ListCurrent(patientID uint, today time.Time) ([]models.Prescription, error) {
+	var prescriptions []models.Prescription
+	err := withDetails(r.db).
+		Where("patient_id = ? AND status = ? AND (end_date >= ? OR refills_used < refills)",
+			patientID, models.PrescriptionActive, today.Format("2006-01-02")).
+		Order("drug, start_date").
+		Find(&prescriptions).Error
+	return prescriptions, err
+}
@@ -0,0 +1,238 @@
+package prescription
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"html/template"
+	"strings"
+	"time"
+	"hospital-management/internal/allergy"
+	"hospital-management/internal/cds"
+	"hospital-management/internal/config"
+	"hospital-management/internal/encounter"
+	"hospital-management/internal/models"
+	"hospital-management/internal/patient"
+	"hospital-management/internal/user"
+	"hospital-management/pkg/utils"
+)
+
+var (
+	ErrDiscontinued      = errors.New("prescription has been discontinued")
+	ErrNoRefillsLeft     = errors.New("prescription has no refills left")
+	ErrInvalidStartDate  = errors.New("start_date must be a date formatted as YYYY-MM-DD")
+	ErrEncounterMismatch = errors.New("encounter belongs to another patient")
+	ErrOverrideRequired  = errors.New("prescription raises contraindicated or major alerts; give an override_reason to prescribe anyway")
+)
+
+//go:embed templates/*.html
+var templateFiles embed.FS
+
+var printTemplate = template.Must(template.ParseFS(templateFiles, "templates/prescription.html"))
+
+type Service struct {
+	repo             *Repository
+	patientService   *patient.Service
+	userService      *user.Service
+	encounterService *encounter.Service
+	allergyService   *allergy.Service
+	engine           *cds.Engine
+	location         *time.Location
+	clinicName       string
+}
+
+func NewService(repo *Repository, patientService *patient.Service, userService *user.Service, encounterService *encounter.Service, allergyService *allergy.Service, engine *cds.Engine, cfg *config.Config) *Service {
+	return &Service{
+		repo:             repo,
+		patientService:   patientService,
+		userService:      userService,
+		encounterService: encounterService,
+		allergyService:   allergyService,
+		engine:           engine,
+		location:         cfg.Location,
+		clinicName:       cfg.ClinicName,
+	}
+}
+
+// Check returns the alerts that prescribing drug to the patient would raise.
+func (s *Service) Check(patientID uint, drug string) ([]models.PrescriptionAlert, error) {
+	if _, err := s.patientService.GetActivePatient(patientID); err != nil {
+		return nil, err
+	}
+
+	current, err := s.repo.ListCurrent(patientID, s.today())
+	if err != nil {
+		return nil, err
+	}
+	allergies, err := s.allergyService.List(patientID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts := s.engine.Check(drug, current, allergies)
+	if alerts == nil {
+		alerts = []models.PrescriptionAlert{}
+	}
+	return alerts, nil
+}
+
+// Prescribe writes a prescription signed by prescriberID, who must be an
+// active doctor. The drug is checked against the patient's medications and
+// allergies first; contraindicated and major alerts are returned with
+// ErrOverrideRequired unless the request gives an override reason. The
+// alerts raised are stored with the prescription.
+func (s *Service) Prescribe(req models.CreatePrescriptionRequest, prescriberID uint) (*models.Prescription, []models.PrescriptionAlert, error) {
+	if _, err := s.userService.GetActiveDoctor(prescriberID); err != nil {
+		return nil, nil, err
+	}
+
+	startDate := s.today()
+	if req.StartDate != "" {
+		var err error
+		startDate, err = time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			return nil, nil, ErrInvalidStartDate
+		}
+	}
+
+	alerts, err := s.Check(req.PatientID, req.Drug)
+	if err != nil {
+		return nil, nil, err
+	}
+	reason := strings.TrimSpace(req.OverrideReason)
+	if cds.Blocking(alerts) && reason == "" {
+		return nil, alerts, ErrOverrideRequired
+	}
+	for i := range alerts {
+		if alerts[i].Blocking() {
+			alerts[i].OverrideReason = reason
+			alerts[i].OverriddenByID = &prescriberID
+		}
+	}
+
+	prescription := &models.Prescription{
+		PatientID:    req.PatientID,
+		PrescriberID: prescriberID,
+		Drug:         strings.TrimSpace(req.Drug),
+		DrugCode:     strings.TrimSpace(req.DrugCode),
+		Dose:         strings.TrimSpace(req.Dose),
+		Route:        req.Route,
+		Frequency:    strings.TrimSpace(req.Frequency),
+		DurationDays: req.DurationDays,
+		Quantity:     req.Quantity,
+		QuantityUnit: strings.TrimSpace(req.QuantityUnit),
+		Refills:      req.Refills,
+		Instructions: req.Instructions,
+		Status:       models.PrescriptionActive,
+		StartDate:    startDate,
+		EndDate:      startDate.AddDate(0, 0, req.DurationDays-1),
+		Alerts:       alerts,
+	}
+
+	if req.EncounterID != 0 {
+		e, err := s.encounterService.Get(req.EncounterID, prescriberID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if e.PatientID != req.PatientID {
+			return nil, nil, ErrEncounterMismatch
+		}
+		prescription.EncounterID = &e.ID
+	}
+
+	if err := s.repo.Create(prescription); err != nil {
+		return nil, nil, err
+	}
+
+	prescription, err = s.repo.GetByID(prescription.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return prescription, prescription.Alerts, nil
+}
+
+func (s *Service) GetPrescription(id uint) (*models.Prescription, error) {
+	return s.repo.GetByID(id)
+}
+
+// Discontinue stops an active prescription. It leaves the active
+// medication list but stays in the patient's history.
+func (s *Service) Discontinue(id uint, reason string, discontinuedBy uint) (*models.Prescription, error) {
+	prescription, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if prescription.Status != models.PrescriptionActive {
+		return nil, ErrDiscontinued
+	}
+
+	if err := s.repo.Discontinue(id, discontinuedBy, reason, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(id)
+}
+
+// Refill authorizes one of the prescription's remaining refills, which
+// dispenses the prescribed quantity again and extends the course.
+func (s *Service) Refill(id uint, req models.RefillPrescriptionRequest, authorizedBy uint) (*models.Prescription, error) {
+	refill := &models.PrescriptionRefill{
+		AuthorizedByID: authorizedBy,
+		Note:           req.Note,
+	}
+	if err := s.repo.Refill(id, refill, s.today()); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(id)
+}
+
+// ListForPatient returns the patient's prescription history.
+func (s *Service) ListForPatient(patientID uint, filter models.PrescriptionFilter) ([]models.Prescription, *utils.Meta, error) {
+	if _, err := s.patientService.GetPatientByID(patientID); err != nil {
+		return nil, nil, err
+	}
+	return s.repo.ListForPatient(patientID, filter)
+}
+
+// ActiveMedications returns the medications the patient is currently
+// prescribed.
+func (s *Service) ActiveMedications(patientID uint) ([]models.Prescription, error) {
+	if _, err := s.patientService.GetPatientByID(patientID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListCurrent(patientID, s.today())
+}
+
+// Print renders the prescription as a printable HTML page.
+func (s *Service) Print(id uint) (*models.Prescription, []byte, error) {
+	prescription, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := printTemplate.Execute(&buf, map[string]interface{}{
+		"Clinic":       s.clinicName,
+		"Prescription": prescription,
+		"PrintedAt":    time.Now().In(s.location),
+	}); err != nil {
+		return nil, nil, err
+	}
+	return prescription, buf.Bytes(), nil
+}
+
+// today returns the current calendar day in the clinic's time zone, as a
+// date at UTC midnight to match date columns.
+func (s *Service) today() time.Time {
+	year, month, day := time.Now().In(s.location).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// extendCourse moves the prescription's end date forward by one course,
+// starting the day after the current course ends or today if it already has.
+func extendCourse(prescription *models.Prescription, today time.Time) {
+	start := prescription.EndDate.AddDate(0, 0, 1)
+	if start.Before(today) {
+		start = today
+	}
+	prescription.EndDate = start.AddDate(0, 0, prescription.DurationDays-1)
+}
@@ -0,0 +1,266 @@
+package problem
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"hospital-management/internal/audit"
+	"hospital-management/internal/encounter"
+	"hospital-management/internal/models"
+	"hospital-management/internal/patient"
+	"hospital-management/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type Handler struct {
+	service *Service
+	audit   *audit.Service
+}
+
+func NewHandler(service *Service, auditService *audit.Service) *Handler {
+	return &Handler{service: service, audit: auditService}
+}
+
+// problemDiffIgnored lists fields left out of audit diffs because they
+// change on every write or are loaded from other tables.
+var problemDiffIgnored = []string{"updated_at", "recorded_by"}
+
+// ids parses the :id patient and, when present, :problemId parameters.
+func ids(c *gin.Context) (uint, uint, bool) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid patient ID", err)
+		return 0, 0, false
+	}
+	if c.Param("problemId") == "" {
+		return uint(patientID), 0, true
+	}
+
+	problemID, err := strconv.ParseUint(c.Param("problemId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid problem ID", err)
+		return 0, 0, false
+	}
+	return uint(patientID), uint(problemID), true
+}
+
+// SearchCodes looks up ICD-10 codes by code or description for
+// autocomplete.
+func (h *Handler) SearchCodes(c *gin.Context) {
+	var query models.CodeSearchQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Codes retrieved successfully", h.service.SearchCodes(query))
+}
+
+func (h *Handler) GetCode(c *gin.Context) {
+	code, err := h.service.LookupCode(c.Param("code"))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Code not found", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Code retrieved successfully", code)
+}
+
+// GetProblems lists a patient's problems, optionally only those with a
+// given ?status.
+func (h *Handler) GetProblems(c *gin.Context) {
+	patientID, _, ok := ids(c)
+	if !ok {
+		return
+	}
+
+	var filter models.ProblemFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query", err)
+		return
+	}
+
+	problems, err := h.service.List(patientID, filter)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to get problems", err)
+		return
+	}
+
+	problemIDs := make([]uint, 0, len(problems))
+	for _, p := range problems {
+		problemIDs = append(problemIDs, p.ID)
+	}
+	h.audit.Log(c, audit.Entry{
+		Action:       "problem.list",
+		ResourceType: "problem",
+		PatientID:    &patientID,
+		Details:      gin.H{"problem_ids": problemIDs},
+	})
+
+	utils.SuccessResponse(c, "Problems retrieved successfully", problems)
+}
+
+func (h *Handler) GetProblem(c *gin.Context) {
+	patientID, problemID, ok := ids(c)
+	if !ok {
+		return
+	}
+
+	problem, err := h.service.Get(patientID, problemID)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Problem not found", err)
+		return
+	}
+
+	h.audit.Log(c, audit.Entry{
+		Action:       "problem.read",
+		ResourceType: "problem",
+		ResourceID:   problem.ID,
+		PatientID:    &problem.PatientID,
+	})
+	utils.SuccessResponse(c, "Problem retrieved successfully", problem)
+}
+
+func (h *Handler) CreateProblem(c *gin.Context) {
+	patientID, _, ok := ids(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateProblemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	problem, err := h.service.Create(patientID, req, utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to record problem", err)
+		return
+	}
+
+	changes, _ := utils.DiffFields(nil, problem, problemDiffIgnored...)
+	h.audit.Log(c, audit.Entry{
+		Action:       "problem.create",
+		ResourceType: "problem",
+		ResourceID:   problem.ID,
+		PatientID:    &problem.PatientID,
+		Changes:      changes,
+	})
+
+	utils.SuccessResponse(c, "Problem recorded successfully", problem)
+}
+
+func (h *Handler) UpdateProblem(c *gin.Context) {
+	patientID, problemID, ok := ids(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateProblemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	before, err := h.service.Get(patientID, problemID)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Problem not found", err)
+		return
+	}
+
+	problem, err := h.service.Update(patientID, problemID, req)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to update problem", err)
+		return
+	}
+
+	changes, _ := utils.DiffFields(before, problem, problemDiffIgnored...)
+	h.audit.Log(c, audit.Entry{
+		Action:       "problem.update",
+		ResourceType: "problem",
+		ResourceID:   problem.ID,
+		PatientID:    &problem.PatientID,
+		Changes:      changes,
+	})
+
+	utils.SuccessResponse(c, "Problem updated successfully", problem)
+}
+
+// AddDiagnosis records a coded diagnosis on a draft encounter.
+func (h *Handler) AddDiagnosis(c *gin.Context) {
+	encounterID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid encounter ID", err)
+		return
+	}
+
+	var req models.AddDiagnosisRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	diagnosis, err := h.service.AddDiagnosis(uint(encounterID), req, utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to add diagnosis", err)
+		return
+	}
+
+	h.audit.Log(c, audit.Entry{
+		Action:       "encounter.diagnosis.add",
+		ResourceType: "encounter",
+		ResourceID:   diagnosis.EncounterID,
+		PatientID:    &diagnosis.PatientID,
+		Details:      gin.H{"diagnosis_id": diagnosis.ID, "problem_id": diagnosis.ProblemID, "code": diagnosis.Code, "primary": diagnosis.IsPrimary},
+	})
+
+	utils.SuccessResponse(c, "Diagnosis added successfully", diagnosis)
+}
+
+// RemoveDiagnosis takes a diagnosis off a draft encounter.
+func (h *Handler) RemoveDiagnosis(c *gin.Context) {
+	encounterID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid encounter ID", err)
+		return
+	}
+	diagnosisID, err := strconv.ParseUint(c.Param("diagnosisId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid diagnosis ID", err)
+		return
+	}
+
+	diagnosis, err := h.service.RemoveDiagnosis(uint(encounterID), uint(diagnosisID), utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to remove diagnosis", err)
+		return
+	}
+
+	h.audit.Log(c, audit.Entry{
+		Action:       "encounter.diagnosis.remove",
+		ResourceType: "encounter",
+		ResourceID:   diagnosis.EncounterID,
+		PatientID:    &diagnosis.PatientID,
+		Details:      gin.H{"diagnosis_id": diagnosis.ID, "problem_id": diagnosis.ProblemID, "code": diagnosis.Code},
+	})
+
+	utils.SuccessResponse(c, "Diagnosis removed successfully", diagnosis)
+}
+
+// statusFor maps service errors to HTTP status codes.
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, encounter.ErrNotAuthor):
+		return http.StatusForbidden
+	case errors.Is(err, encounter.ErrAlreadySigned), errors.Is(err, ErrDuplicateProblem), errors.Is(err, ErrAlreadyDiagnosed):
+		return http.StatusConflict
+	case errors.Is(err, ErrUnknownCode), errors.Is(err, ErrInvalidDate), errors.Is(err, ErrResolvedBeforeOnset),
+		errors.Is(err, ErrNotResolved), errors.Is(err, patient.ErrPatientMerged):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,121 @@
+package problem
+
+import (
+	"hospital-management/internal/encounter"
+	"hospital-management/internal/models"
+	"hospital-management/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func withRecorder(db *gorm.DB) *gorm.DB {
+	return db.Preload("RecordedBy", utils.WithDeleted)
+}
+
+func (r *Repository) Create(problem *models.Problem) error {
+	return utils.TranslateConflict(r.db.Omit("RecordedBy").Create(problem).Error, conflictErrors)
+}
+
+func (r *Repository) Update(problem *models.Problem) error {
+	return utils.TranslateConflict(r.db.Omit("RecordedBy").Save(problem).Error, conflictErrors)
+}
+
+// Get returns the patient's problem with the given ID.
+func (r *Repository) Get(patientID, id uint) (*models.Problem, error) {
+	var problem models.Problem
+	err := withRecorder(r.db).Where("patient_id = ?", patientID).First(&problem, id).Error
+	return &problem, err
+}
+
+// FindOpen returns the patient's problem with the given code that has not
+// been resolved, other than the problem with ID except.
+func (r *Repository) FindOpen(patientID uint, code string, except uint) (*models.Problem, error) {
+	var problem models.Problem
+	err := r.db.Where("patient_id = ? AND code = ? AND status <> ? AND id <> ?",
+		patientID, code, models.ProblemResolved, except).
+		First(&problem).Error
+	return &problem, err
+}
+
+// ListForPatient returns a patient's problems, active ones first, then by
+// onset.
+func (r *Repository) ListForPatient(patientID uint, filter models.ProblemFilter) ([]models.Problem, error) {
+	var problems []models.Problem
+	query := withRecorder(r.db).Where("patient_id = ?", patientID)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	err := query.Order("status = 'active' DESC, onset_date DESC NULLS LAST, code, id").Find(&problems).Error
+	return problems, err
+}
+
+// AddDiagnosis saves diagnosis, first adding problem to the problem list if
+// it is new. A primary diagnosis replaces the encounter's previous one.
+func (r *Repository) AddDiagnosis(diagnosis *models.EncounterDiagnosis, problem *models.Problem) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockDraft(tx, diagnosis.EncounterID); err != nil {
+			return err
+		}
+		if problem.ID == 0 {
+			if err := utils.TranslateConflict(tx.Omit("RecordedBy").Create(problem).Error, conflictErrors); err != nil {
+				return err
+			}
+		}
+		if diagnosis.IsPrimary {
+			if err := tx.Model(&models.EncounterDiagnosis{}).
+				Where("encounter_id = ? AND is_primary", diagnosis.EncounterID).
+				Update("is_primary", false).Error; err != nil {
+				return err
+			}
+		}
+
+		diagnosis.ProblemID = problem.ID
+		return utils.TranslateConflict(tx.Omit("Problem").Create(diagnosis).Error, conflictErrors)
+	})
+}
+
+// GetDiagnosis returns the encounter's diagnosis with the given ID.
+func (r *Repository) GetDiagnosis(encounterID, id uint) (*models.EncounterDiagnosis, error) {
+	var diagnosis models.EncounterDiagnosis
+	err := r.db.Preload("Problem").Where("encounter_id = ?", encounterID).First(&diagnosis, id).Error
+	return &diagnosis, err
+}
+
+func (r *Repository) DeleteDiagnosis(encounterID, id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockDraft(tx, encounterID); err != nil {
+			return err
+		}
+		return tx.Where("encounter_id = ?", encounterID).Delete(&models.EncounterDiagnosis{}, id).Error
+	})
+}
+
+// lockDraft locks the encounter with the given ID until the end of tx, so
+// that it cannot be signed while its diagnoses change. It returns
+// encounter.ErrAlreadySigned when the encounter was signed first.
+func lockDraft(tx *gorm.DB, encounterID uint) error {
+	var e models.Encounter
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "status").
+		First(&e, encounterID).Error; err != nil {
+		return err
+	}
+	if e.Status != models.EncounterDraft {
+		return encounter.ErrAlreadySigned
+	}
+	return nil
+}
+
+// conflictErrors maps the problem list's unique indexes to problem errors.
+var conflictErrors = map[string]error{
+	"idx_encounter_diagnoses_problem": ErrAlreadyDiagnosed,
+	"problems_one_open_per_code":      ErrDuplicateProblem,
+}
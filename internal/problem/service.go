@@ -0,0 +1,280 @@
+package problem
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"hospital-management/internal/config"
+	"hospital-management/internal/encounter"
+	"hospital-management/internal/icd10"
+	"hospital-management/internal/models"
+	"hospital-management/internal/patient"
+	"gorm.io/gorm"
+)
+
+// defaultSearchLimit is how many codes a search returns when no limit is
+// given.
+const defaultSearchLimit = 20
+
+var (
+	ErrUnknownCode         = errors.New("code is not in the ICD-10 code table")
+	ErrDuplicateProblem    = errors.New("patient already has an open problem with that code")
+	ErrInvalidDate         = errors.New("dates must be formatted as YYYY-MM-DD and not in the future")
+	ErrResolvedBeforeOnset = errors.New("resolved_date cannot be before onset_date")
+	ErrNotResolved         = errors.New("only resolved problems have a resolved_date")
+	ErrAlreadyDiagnosed    = errors.New("problem is already a diagnosis of this encounter")
+)
+
+type Service struct {
+	repo             *Repository
+	catalog          *icd10.Catalog
+	patientService   *patient.Service
+	encounterService *encounter.Service
+	location         *time.Location
+}
+
+func NewService(repo *Repository, catalog *icd10.Catalog, patientService *patient.Service, encounterService *encounter.Service, cfg *config.Config) *Service {
+	return &Service{
+		repo:             repo,
+		catalog:          catalog,
+		patientService:   patientService,
+		encounterService: encounterService,
+		location:         cfg.Location,
+	}
+}
+
+// SearchCodes returns ICD-10 codes matching query for autocomplete.
+func (s *Service) SearchCodes(query models.CodeSearchQuery) []icd10.Code {
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	return s.catalog.Search(query.Query, limit)
+}
+
+// LookupCode returns the ICD-10 code table entry for code.
+func (s *Service) LookupCode(code string) (icd10.Code, error) {
+	entry, ok := s.catalog.Lookup(code)
+	if !ok {
+		return icd10.Code{}, gorm.ErrRecordNotFound
+	}
+	return entry, nil
+}
+
+// List returns the patient's problem list.
+func (s *Service) List(patientID uint, filter models.ProblemFilter) ([]models.Problem, error) {
+	if _, err := s.patientService.GetPatientByID(patientID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListForPatient(patientID, filter)
+}
+
+func (s *Service) Get(patientID, id uint) (*models.Problem, error) {
+	return s.repo.Get(patientID, id)
+}
+
+// Create adds a problem to the patient's list. A patient has at most one
+// open problem per code.
+func (s *Service) Create(patientID uint, req models.CreateProblemRequest, recordedBy uint) (*models.Problem, error) {
+	if _, err := s.patientService.GetActivePatient(patientID); err != nil {
+		return nil, err
+	}
+	entry, ok := s.catalog.Lookup(req.Code)
+	if !ok {
+		return nil, ErrUnknownCode
+	}
+
+	problem := &models.Problem{
+		PatientID:    patientID,
+		Code:         entry.Code,
+		Description:  entry.Description,
+		Status:       req.Status,
+		Note:         req.Note,
+		RecordedByID: recordedBy,
+	}
+	if problem.Status == "" {
+		problem.Status = models.ProblemActive
+	}
+
+	var err error
+	if problem.OnsetDate, err = parseDate(req.OnsetDate); err != nil {
+		return nil, err
+	}
+	if problem.ResolvedDate, err = parseDate(req.ResolvedDate); err != nil {
+		return nil, err
+	}
+	if err := s.settle(problem); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(problem); err != nil {
+		return nil, err
+	}
+	return s.repo.Get(patientID, problem.ID)
+}
+
+// Update changes the status, dates or note of a problem. Its code is fixed;
+// a wrong code is resolved or made inactive and the right one added.
+func (s *Service) Update(patientID, id uint, req models.UpdateProblemRequest) (*models.Problem, error) {
+	if _, err := s.patientService.GetActivePatient(patientID); err != nil {
+		return nil, err
+	}
+	problem, err := s.repo.Get(patientID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Status != nil {
+		if problem.Status == models.ProblemResolved && *req.Status != models.ProblemResolved {
+			problem.ResolvedDate = nil
+		}
+		problem.Status = *req.Status
+	}
+	if req.OnsetDate != nil {
+		if problem.OnsetDate, err = parseDate(*req.OnsetDate); err != nil {
+			return nil, err
+		}
+	}
+	if req.ResolvedDate != nil {
+		if problem.ResolvedDate, err = parseDate(*req.ResolvedDate); err != nil {
+			return nil, err
+		}
+	}
+	if req.Note != nil {
+		problem.Note = *req.Note
+	}
+	if err := s.settle(problem); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(problem); err != nil {
+		return nil, err
+	}
+	return s.repo.Get(patientID, id)
+}
+
+// AddDiagnosis records a diagnosis on a draft encounter on behalf of its
+// author. A diagnosis given by code links the patient's open problem with
+// that code, or adds one dated from the encounter.
+func (s *Service) AddDiagnosis(encounterID uint, req models.AddDiagnosisRequest, authorID uint) (*models.EncounterDiagnosis, error) {
+	e, err := s.getDraftEncounter(encounterID, authorID)
+	if err != nil {
+		return nil, err
+	}
+
+	var problem *models.Problem
+	if req.ProblemID != 0 {
+		if problem, err = s.repo.Get(e.PatientID, req.ProblemID); err != nil {
+			return nil, err
+		}
+	} else {
+		entry, ok := s.catalog.Lookup(req.Code)
+		if !ok {
+			return nil, ErrUnknownCode
+		}
+		problem, err = s.repo.FindOpen(e.PatientID, entry.Code, 0)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			onset := s.date(e.EncounterAt)
+			problem = &models.Problem{
+				PatientID:    e.PatientID,
+				Code:         entry.Code,
+				Description:  entry.Description,
+				Status:       models.ProblemActive,
+				OnsetDate:    &onset,
+				RecordedByID: authorID,
+			}
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	diagnosis := &models.EncounterDiagnosis{
+		EncounterID: e.ID,
+		PatientID:   e.PatientID,
+		Code:        problem.Code,
+		Description: problem.Description,
+		IsPrimary:   req.Primary,
+		CreatedByID: authorID,
+	}
+	if err := s.repo.AddDiagnosis(diagnosis, problem); err != nil {
+		return nil, err
+	}
+	return s.repo.GetDiagnosis(e.ID, diagnosis.ID)
+}
+
+// RemoveDiagnosis takes a diagnosis off a draft encounter on behalf of its
+// author. The problem stays on the problem list.
+func (s *Service) RemoveDiagnosis(encounterID, id, authorID uint) (*models.EncounterDiagnosis, error) {
+	e, err := s.getDraftEncounter(encounterID, authorID)
+	if err != nil {
+		return nil, err
+	}
+	diagnosis, err := s.repo.GetDiagnosis(e.ID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.DeleteDiagnosis(e.ID, diagnosis.ID); err != nil {
+		return nil, err
+	}
+	return diagnosis, nil
+}
+
+// settle checks a problem's dates against its status and each other, and
+// that it does not duplicate another open problem. Resolved problems
+// without a resolution date are resolved today.
+func (s *Service) settle(problem *models.Problem) error {
+	if problem.Status == models.ProblemResolved {
+		if problem.ResolvedDate == nil {
+			today := s.date(time.Now())
+			problem.ResolvedDate = &today
+		}
+	} else if problem.ResolvedDate != nil {
+		return ErrNotResolved
+	}
+	if problem.OnsetDate != nil && problem.ResolvedDate != nil && problem.ResolvedDate.Before(*problem.OnsetDate) {
+		return ErrResolvedBeforeOnset
+	}
+
+	if problem.Status == models.ProblemResolved {
+		return nil
+	}
+	_, err := s.repo.FindOpen(problem.PatientID, problem.Code, problem.ID)
+	if err == nil {
+		return ErrDuplicateProblem
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+func (s *Service) getDraftEncounter(id, authorID uint) (*models.Encounter, error) {
+	e, err := s.encounterService.Get(id, authorID)
+	if err != nil {
+		return nil, err
+	}
+	if e.DoctorID != authorID {
+		return nil, encounter.ErrNotAuthor
+	}
+	if e.Status != models.EncounterDraft {
+		return nil, encounter.ErrAlreadySigned
+	}
+	return e, nil
+}
+
+// date returns the calendar date of t in the clinic's time zone.
+func (s *Service) date(t time.Time) time.Time {
+	year, month, day := t.In(s.location).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func parseDate(value string) (*time.Time, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	date, err := time.Parse("2006-01-02", strings.TrimSpace(value))
+	if err != nil || date.After(time.Now()) {
+		return nil, ErrInvalidDate
+	}
+	return &date, nil
+}
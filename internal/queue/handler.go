@@ -0,0 +1,252 @@
+package queue
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+	"hospital-management/internal/appointment"
+	"hospital-management/internal/audit"
+	"hospital-management/internal/models"
+	"hospital-management/internal/patient"
+	"hospital-management/internal/user"
+	"hospital-management/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// heartbeatInterval is how often an idle queue stream sends a ping so that
+// proxies keep the connection open.
+const heartbeatInterval = 30 * time.Second
+
+type Handler struct {
+	service *Service
+	audit   *audit.Service
+}
+
+func NewHandler(service *Service, auditService *audit.Service) *Handler {
+	return &Handler{service: service, audit: auditService}
+}
+
+// visitDiffIgnored lists fields left out of audit diffs because they change
+// on every write or are loaded from other tables.
+var visitDiffIgnored = []string{"updated_at", "patient", "doctor", "appointment"}
+
+func (h *Handler) CheckIn(c *gin.Context) {
+	var req models.CheckInRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	visit, err := h.service.CheckIn(req, utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to check in patient", err)
+		return
+	}
+
+	changes, _ := utils.DiffFields(nil, visit, visitDiffIgnored...)
+	h.audit.Log(c, audit.Entry{
+		Action:       "visit.check_in",
+		ResourceType: "visit",
+		ResourceID:   visit.ID,
+		PatientID:    &visit.PatientID,
+		Changes:      changes,
+	})
+
+	utils.SuccessResponse(c, "Patient checked in successfully", visit)
+}
+
+func (h *Handler) UpdateStatus(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid visit ID", err)
+		return
+	}
+
+	var req models.UpdateVisitStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	before, err := h.service.GetVisit(uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Visit not found", err)
+		return
+	}
+
+	visit, err := h.service.SetStatus(uint(id), req)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to update visit status", err)
+		return
+	}
+
+	changes, _ := utils.DiffFields(before, visit, visitDiffIgnored...)
+	h.audit.Log(c, audit.Entry{
+		Action:       "visit.status",
+		ResourceType: "visit",
+		ResourceID:   visit.ID,
+		PatientID:    &visit.PatientID,
+		Changes:      changes,
+	})
+
+	utils.SuccessResponse(c, "Visit status updated successfully", visit)
+}
+
+func (h *Handler) UpdatePriority(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid visit ID", err)
+		return
+	}
+
+	var req models.UpdateVisitPriorityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	before, err := h.service.GetVisit(uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Visit not found", err)
+		return
+	}
+
+	visit, err := h.service.SetPriority(uint(id), req)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to update visit priority", err)
+		return
+	}
+
+	changes, _ := utils.DiffFields(before, visit, visitDiffIgnored...)
+	h.audit.Log(c, audit.Entry{
+		Action:       "visit.priority",
+		ResourceType: "visit",
+		ResourceID:   visit.ID,
+		PatientID:    &visit.PatientID,
+		Changes:      changes,
+	})
+
+	utils.SuccessResponse(c, "Visit priority updated successfully", visit)
+}
+
+// GetQueue returns the queue of the doctor given as ?doctor_id=.
+func (h *Handler) GetQueue(c *gin.Context) {
+	id, ok := queryDoctorID(c)
+	if !ok {
+		return
+	}
+	h.getQueue(c, id)
+}
+
+// GetDoctorQueue returns the authenticated doctor's queue.
+func (h *Handler) GetDoctorQueue(c *gin.Context) {
+	h.getQueue(c, utils.CurrentUserID(c))
+}
+
+// StreamQueue streams the queue of the doctor given as ?doctor_id= as
+// Server-Sent Events.
+func (h *Handler) StreamQueue(c *gin.Context) {
+	id, ok := queryDoctorID(c)
+	if !ok {
+		return
+	}
+	h.stream(c, id)
+}
+
+// StreamDoctorQueue streams the authenticated doctor's queue as Server-Sent
+// Events.
+func (h *Handler) StreamDoctorQueue(c *gin.Context) {
+	h.stream(c, utils.CurrentUserID(c))
+}
+
+func (h *Handler) getQueue(c *gin.Context, doctorID uint) {
+	queue, err := h.service.GetQueue(doctorID)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to get queue", err)
+		return
+	}
+
+	h.logQueue(c, "visit.queue.read", queue)
+	utils.SuccessResponse(c, "Queue retrieved successfully", queue)
+}
+
+// stream sends the current queue and then a fresh "queue" event whenever it
+// changes, with a "ping" event when idle, until the client disconnects.
+func (h *Handler) stream(c *gin.Context, doctorID uint) {
+	updates, unsubscribe := h.service.Subscribe(doctorID)
+	defer unsubscribe()
+
+	queue, err := h.service.GetQueue(doctorID)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to get queue", err)
+		return
+	}
+	h.logQueue(c, "visit.queue.stream", queue)
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.SSEvent(EventName, queue)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-updates:
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.Name, event.Data)
+			return true
+		case now := <-heartbeat.C:
+			c.SSEvent("ping", now.UTC())
+			return true
+		}
+	})
+}
+
+// logQueue audits a view of a queue with the patients it disclosed.
+func (h *Handler) logQueue(c *gin.Context, action string, queue *models.Queue) {
+	ids := make([]uint, 0, len(queue.Entries))
+	patientIDs := make([]uint, 0, len(queue.Entries))
+	for _, entry := range queue.Entries {
+		ids = append(ids, entry.Visit.ID)
+		patientIDs = append(patientIDs, entry.Visit.PatientID)
+	}
+	h.audit.Log(c, audit.Entry{
+		Action:       action,
+		ResourceType: "visit",
+		Details:      gin.H{"doctor_id": queue.DoctorID, "visit_ids": ids, "patient_ids": patientIDs},
+	})
+}
+
+func queryDoctorID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Query("doctor_id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid or missing doctor_id", err)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// statusFor maps service errors to HTTP status codes.
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrAlreadyCheckedIn), errors.Is(err, ErrDoctorBusy), errors.Is(err, ErrInvalidTransition),
+		errors.Is(err, ErrVisitClosed), errors.Is(err, ErrVisitChanged), errors.Is(err, appointment.ErrNotScheduled):
+		return http.StatusConflict
+	case errors.Is(err, user.ErrNotDoctor), errors.Is(err, patient.ErrPatientMerged), errors.Is(err, ErrMissingDoctor),
+		errors.Is(err, ErrMissingPatient), errors.Is(err, ErrAppointmentMismatch):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,116 @@
+package queue
+
+import (
+	"time"
+	"hospital-management/internal/models"
+	"hospital-management/pkg/utils"
+	"gorm.io/gorm"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// withDetails preloads what a queue shows about a visit.
+func withDetails(db *gorm.DB) *gorm.DB {
+	return db.Preload("Patient").
+		Preload("Doctor", utils.WithDeleted).
+		Preload("Appointment")
+}
+
+func (r *Repository) Create(visit *models.Visit) error {
+	return utils.TranslateConflict(r.db.Omit("Patient", "Doctor", "Appointment").Create(visit).Error, conflictErrors)
+}
+
+func (r *Repository) GetByID(id uint) (*models.Visit, error) {
+	var visit models.Visit
+	err := withDetails(r.db).First(&visit, id).Error
+	return &visit, err
+}
+
+// Update writes changes to the visit as long as it still has status from,
+// so two requests racing on the same visit cannot overwrite each other. When
+// appointmentStatus is not empty the linked appointment moves to that status
+// in the same transaction. It returns ErrVisitChanged when another request
+// moved the visit on first.
+func (r *Repository) Update(visit *models.Visit, from string, changes map[string]interface{}, appointmentStatus string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Visit{}).
+			Where("id = ? AND status = ?", visit.ID, from).
+			Updates(changes)
+		if err := utils.TranslateConflict(result.Error, conflictErrors); err != nil {
+			return err
+		}
+		if result.RowsAffected == 0 {
+			return ErrVisitChanged
+		}
+		if appointmentStatus == "" || visit.AppointmentID == nil {
+			return nil
+		}
+		return tx.Model(&models.Appointment{}).
+			Where("id = ? AND status = ?", *visit.AppointmentID, models.AppointmentScheduled).
+			Update("status", appointmentStatus).Error
+	})
+}
+
+// ListActive returns a doctor's active visits in queue order: the patient in
+// consultation first, then by priority and arrival.
+func (r *Repository) ListActive(doctorID uint) ([]models.Visit, error) {
+	var visits []models.Visit
+	err := withDetails(r.db).
+		Where("doctor_id = ? AND status IN ?", doctorID,
+			[]string{models.VisitArrived, models.VisitTriaged, models.VisitInConsultation}).
+		Order("status = 'in_consultation' DESC, priority DESC, arrived_at, id").
+		Find(&visits).Error
+	return visits, err
+}
+
+// FindActiveForPatient returns the patient's active visit.
+func (r *Repository) FindActiveForPatient(patientID uint) (*models.Visit, error) {
+	var visit models.Visit
+	err := withDetails(r.db).
+		Where("patient_id = ? AND status IN ?", patientID,
+			[]string{models.VisitArrived, models.VisitTriaged, models.VisitInConsultation}).
+		First(&visit).Error
+	return &visit, err
+}
+
+// AverageConsultation returns the mean length in minutes of the doctor's
+// consultations completed since the given time, or 0 if there were none.
+func (r *Repository) AverageConsultation(doctorID uint, since time.Time) (float64, error) {
+	var minutes *float64
+	err := r.db.Model(&models.Visit{}).
+		Select("AVG(EXTRACT(EPOCH FROM completed_at - started_at)) / 60").
+		Where("doctor_id = ? AND status = ? AND started_at IS NOT NULL AND completed_at >= ?",
+			doctorID, models.VisitDone, since).
+		Scan(&minutes).Error
+	if err != nil || minutes == nil {
+		return 0, err
+	}
+	return *minutes, nil
+}
+
+// AverageWait returns the mean time in minutes between arrival and the
+// start of consultation for the doctor's patients seen since the given
+// time, or 0 if there were none.
+func (r *Repository) AverageWait(doctorID uint, since time.Time) (float64, error) {
+	var minutes *float64
+	err := r.db.Model(&models.Visit{}).
+		Select("AVG(EXTRACT(EPOCH FROM started_at - arrived_at)) / 60").
+		Where("doctor_id = ? AND started_at >= ?", doctorID, since).
+		Scan(&minutes).Error
+	if err != nil || minutes == nil {
+		return 0, err
+	}
+	return *minutes, nil
+}
+
+// conflictErrors maps the queue's unique indexes to queue errors.
+var conflictErrors = map[string]error{
+	"visits_one_active_per_patient":      ErrAlreadyCheckedIn,
+	"visits_one_consultation_per_doctor": ErrDoctorBusy,
+}
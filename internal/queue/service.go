@@ -0,0 +1,277 @@
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+	"hospital-management/internal/appointment"
+	"hospital-management/internal/config"
+	"hospital-management/internal/models"
+	"hospital-management/internal/patient"
+	"hospital-management/internal/user"
+	"hospital-management/pkg/events"
+)
+
+const (
+	// defaultConsultationMinutes is assumed for doctors without recent
+	// completed consultations.
+	defaultConsultationMinutes = 15
+
+	// consultationHistory is how far back consultations count towards a
+	// doctor's average.
+	consultationHistory = 30 * 24 * time.Hour
+
+	// EventName names queue snapshot events.
+	EventName = "queue"
+)
+
+var (
+	ErrAlreadyCheckedIn    = errors.New("patient is already checked in")
+	ErrDoctorBusy          = errors.New("doctor already has a patient in consultation")
+	ErrInvalidTransition   = errors.New("visit cannot move to that status")
+	ErrVisitClosed         = errors.New("visit has already ended")
+	ErrVisitChanged        = errors.New("visit was updated by someone else; reload it and try again")
+	ErrMissingDoctor       = errors.New("doctor_id is required without an appointment")
+	ErrMissingPatient      = errors.New("patient_id is required without an appointment")
+	ErrAppointmentMismatch = errors.New("appointment belongs to another patient")
+)
+
+// transitions lists the statuses each visit status can move to.
+var transitions = map[string][]string{
+	models.VisitArrived:        {models.VisitTriaged, models.VisitInConsultation, models.VisitNoShow},
+	models.VisitTriaged:        {models.VisitInConsultation, models.VisitNoShow},
+	models.VisitInConsultation: {models.VisitDone},
+}
+
+type Service struct {
+	repo               *Repository
+	patientService     *patient.Service
+	userService        *user.Service
+	appointmentService *appointment.Service
+	broker             *events.Broker
+	location           *time.Location
+}
+
+func NewService(repo *Repository, patientService *patient.Service, userService *user.Service, appointmentService *appointment.Service, broker *events.Broker, cfg *config.Config) *Service {
+	return &Service{
+		repo:               repo,
+		patientService:     patientService,
+		userService:        userService,
+		appointmentService: appointmentService,
+		broker:             broker,
+		location:           cfg.Location,
+	}
+}
+
+// Topic returns the broker topic carrying snapshots of a doctor's queue.
+func Topic(doctorID uint) string {
+	return fmt.Sprintf("queue:%d", doctorID)
+}
+
+// CheckIn adds a patient to a doctor's queue. With an appointment, the
+// patient and doctor come from the appointment, which must still be
+// scheduled.
+func (s *Service) CheckIn(req models.CheckInRequest, checkedInBy uint) (*models.Visit, error) {
+	visit := &models.Visit{
+		PatientID:     req.PatientID,
+		DoctorID:      req.DoctorID,
+		Status:        models.VisitArrived,
+		Priority:      req.Priority,
+		Notes:         req.Notes,
+		CheckedInByID: checkedInBy,
+		ArrivedAt:     time.Now(),
+	}
+
+	if req.AppointmentID != 0 {
+		booked, err := s.appointmentService.GetAppointment(req.AppointmentID)
+		if err != nil {
+			return nil, err
+		}
+		if booked.Status != models.AppointmentScheduled {
+			return nil, appointment.ErrNotScheduled
+		}
+		if req.PatientID != 0 && req.PatientID != booked.PatientID {
+			return nil, ErrAppointmentMismatch
+		}
+		visit.PatientID = booked.PatientID
+		visit.DoctorID = booked.DoctorID
+		visit.AppointmentID = &booked.ID
+	} else {
+		if req.PatientID == 0 {
+			return nil, ErrMissingPatient
+		}
+		if req.DoctorID == 0 {
+			return nil, ErrMissingDoctor
+		}
+	}
+
+	if _, err := s.patientService.GetActivePatient(visit.PatientID); err != nil {
+		return nil, err
+	}
+	if _, err := s.userService.GetActiveDoctor(visit.DoctorID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(visit); err != nil {
+		return nil, err
+	}
+
+	s.publish(visit.DoctorID)
+	return s.repo.GetByID(visit.ID)
+}
+
+func (s *Service) GetVisit(id uint) (*models.Visit, error) {
+	return s.repo.GetByID(id)
+}
+
+// ActiveVisit returns the visit the patient is currently checked in for, or
+// gorm.ErrRecordNotFound when they are not at the clinic.
+func (s *Service) ActiveVisit(patientID uint) (*models.Visit, error) {
+	return s.repo.FindActiveForPatient(patientID)
+}
+
+// SetStatus moves a visit along its workflow. Ending a visit also closes the
+// appointment it was checked in for.
+func (s *Service) SetStatus(id uint, req models.UpdateVisitStatusRequest) (*models.Visit, error) {
+	visit, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed(visit.Status, req.Status) {
+		return nil, ErrInvalidTransition
+	}
+
+	now := time.Now()
+	changes := map[string]interface{}{"status": req.Status}
+	appointmentStatus := ""
+	switch req.Status {
+	case models.VisitTriaged:
+		changes["triaged_at"] = now
+	case models.VisitInConsultation:
+		changes["started_at"] = now
+	case models.VisitDone:
+		changes["completed_at"] = now
+		appointmentStatus = models.AppointmentCompleted
+	case models.VisitNoShow:
+		changes["completed_at"] = now
+		appointmentStatus = models.AppointmentNoShow
+	}
+	if req.Notes != "" {
+		changes["notes"] = req.Notes
+	}
+
+	if err := s.repo.Update(visit, visit.Status, changes, appointmentStatus); err != nil {
+		return nil, err
+	}
+
+	s.publish(visit.DoctorID)
+	return s.repo.GetByID(id)
+}
+
+// SetPriority overrides the priority of a waiting visit, moving it up or
+// down the queue.
+func (s *Service) SetPriority(id uint, req models.UpdateVisitPriorityRequest) (*models.Visit, error) {
+	visit, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !visit.Active() {
+		return nil, ErrVisitClosed
+	}
+
+	changes := map[string]interface{}{
+		"priority":        req.Priority,
+		"priority_reason": req.Reason,
+	}
+	if err := s.repo.Update(visit, visit.Status, changes, ""); err != nil {
+		return nil, err
+	}
+
+	s.publish(visit.DoctorID)
+	return s.repo.GetByID(id)
+}
+
+// GetQueue returns a doctor's current queue with estimated waits. Each
+// waiting patient is expected to wait for the rest of the current
+// consultation plus one average consultation per patient ahead of them.
+func (s *Service) GetQueue(doctorID uint) (*models.Queue, error) {
+	visits, err := s.repo.ListActive(doctorID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	average, err := s.repo.AverageConsultation(doctorID, now.Add(-consultationHistory))
+	if err != nil {
+		return nil, err
+	}
+	if average == 0 {
+		average = defaultConsultationMinutes
+	}
+
+	year, month, day := now.In(s.location).Date()
+	averageWait, err := s.repo.AverageWait(doctorID, time.Date(year, month, day, 0, 0, 0, 0, s.location))
+	if err != nil {
+		return nil, err
+	}
+
+	queue := &models.Queue{
+		DoctorID:                   doctorID,
+		Entries:                    make([]models.QueueEntry, 0, len(visits)),
+		AverageConsultationMinutes: round(average),
+		AverageWaitMinutes:         round(averageWait),
+		GeneratedAt:                now,
+	}
+
+	remaining := 0.0
+	position := 0
+	for _, visit := range visits {
+		if visit.Status == models.VisitInConsultation {
+			elapsed := now.Sub(*visit.StartedAt).Minutes()
+			remaining = math.Max(average-elapsed, 0)
+			queue.Entries = append(queue.Entries, models.QueueEntry{Visit: visit})
+			continue
+		}
+
+		wait := remaining + float64(position)*average
+		position++
+		queue.Entries = append(queue.Entries, models.QueueEntry{
+			Visit:                visit,
+			Position:             position,
+			EstimatedWaitMinutes: int(math.Ceil(wait)),
+		})
+	}
+
+	return queue, nil
+}
+
+// Subscribe returns snapshots of a doctor's queue as it changes.
+func (s *Service) Subscribe(doctorID uint) (<-chan events.Event, func()) {
+	return s.broker.Subscribe(Topic(doctorID))
+}
+
+// publish sends a fresh snapshot of the doctor's queue to subscribers.
+func (s *Service) publish(doctorID uint) {
+	queue, err := s.GetQueue(doctorID)
+	if err != nil {
+		log.Printf("queue: failed to publish queue of doctor %d: %v", doctorID, err)
+		return
+	}
+	s.broker.Publish(Topic(doctorID), events.Event{Name: EventName, Data: queue})
+}
+
+func allowed(from, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// round rounds minutes to one decimal place.
+func round(minutes float64) float64 {
+	return math.Round(minutes*10) / 10
+}
@@ -0,0 +1,68 @@
+package retention
+
+import (
+	"context"
+	"log"
+	"time"
+	"hospital-management/internal/audit"
+	"hospital-management/internal/patient"
+)
+
+// Job permanently purges patient records once they have been soft-deleted
+// for longer than the legal retention period. Staff accounts are never
+// purged because audit entries and clinical records keep referring to them.
+type Job struct {
+	patientService *patient.Service
+	auditService   *audit.Service
+	period         time.Duration
+	interval       time.Duration
+}
+
+func NewJob(patientService *patient.Service, auditService *audit.Service, period, interval time.Duration) *Job {
+	return &Job{
+		patientService: patientService,
+		auditService:   auditService,
+		period:         period,
+		interval:       interval,
+	}
+}
+
+// Start runs the purge immediately and then on every interval until ctx is
+// cancelled.
+func (j *Job) Start(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		j.RunOnce()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (j *Job) RunOnce() {
+	ids, err := j.patientService.PurgeExpired(j.period)
+	if err != nil {
+		log.Printf("retention: purge failed: %v", err)
+		return
+	}
+
+	for _, id := range ids {
+		patientID := id
+		j.auditService.LogSystem(audit.Entry{
+			Action:       "patient.purge",
+			ResourceType: "patient",
+			ResourceID:   patientID,
+			PatientID:    &patientID,
+			Details:      map[string]interface{}{"retention_period": j.period.String()},
+		})
+	}
+
+	if len(ids) > 0 {
+		log.Printf("retention: purged %d patient records", len(ids))
+	}
+}
@@ -0,0 +1,192 @@
+package schedule
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"hospital-management/internal/audit"
+	"hospital-management/internal/models"
+	"hospital-management/internal/user"
+	"hospital-management/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type Handler struct {
+	service *Service
+	audit   *audit.Service
+}
+
+func NewHandler(service *Service, auditService *audit.Service) *Handler {
+	return &Handler{service: service, audit: auditService}
+}
+
+// doctorID returns the doctor a schedule route acts on: the :id parameter
+// on /doctors/:id routes, or the authenticated doctor on /doctor routes.
+func doctorID(c *gin.Context) (uint, bool) {
+	param := c.Param("id")
+	if param == "" {
+		return utils.CurrentUserID(c), true
+	}
+
+	id, err := strconv.ParseUint(param, 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid doctor ID", err)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (h *Handler) GetSchedule(c *gin.Context) {
+	id, ok := doctorID(c)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.service.GetSchedule(id)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to get schedule", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Schedule retrieved successfully", schedule)
+}
+
+func (h *Handler) SetWeeklyHours(c *gin.Context) {
+	id, ok := doctorID(c)
+	if !ok {
+		return
+	}
+
+	var req models.SetWeeklyHoursRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	schedule, err := h.service.SetWeekly(id, req)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to update weekly hours", err)
+		return
+	}
+
+	h.audit.Record(c, "schedule.weekly.update", "user", id, req)
+	utils.SuccessResponse(c, "Weekly hours updated successfully", schedule)
+}
+
+func (h *Handler) AddLeave(c *gin.Context) {
+	id, ok := doctorID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateLeaveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	leave, err := h.service.AddLeave(id, req, utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to add leave", err)
+		return
+	}
+
+	h.audit.Record(c, "schedule.leave.create", "user", id, leave)
+	utils.SuccessResponse(c, "Leave added successfully", leave)
+}
+
+func (h *Handler) DeleteLeave(c *gin.Context) {
+	id, ok := doctorID(c)
+	if !ok {
+		return
+	}
+
+	leaveID, err := strconv.ParseUint(c.Param("leaveId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid leave ID", err)
+		return
+	}
+
+	if err := h.service.DeleteLeave(id, uint(leaveID)); err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to delete leave", err)
+		return
+	}
+
+	h.audit.Record(c, "schedule.leave.delete", "user", id, gin.H{"leave_id": leaveID})
+	utils.SuccessResponse(c, "Leave deleted successfully", nil)
+}
+
+func (h *Handler) AddException(c *gin.Context) {
+	id, ok := doctorID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateScheduleExceptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	exception, err := h.service.AddException(id, req, utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to add schedule exception", err)
+		return
+	}
+
+	h.audit.Record(c, "schedule.exception.create", "user", id, exception)
+	utils.SuccessResponse(c, "Schedule exception added successfully", exception)
+}
+
+func (h *Handler) DeleteException(c *gin.Context) {
+	id, ok := doctorID(c)
+	if !ok {
+		return
+	}
+
+	exceptionID, err := strconv.ParseUint(c.Param("exceptionId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid exception ID", err)
+		return
+	}
+
+	if err := h.service.DeleteException(id, uint(exceptionID)); err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to delete schedule exception", err)
+		return
+	}
+
+	h.audit.Record(c, "schedule.exception.delete", "user", id, gin.H{"exception_id": exceptionID})
+	utils.SuccessResponse(c, "Schedule exception deleted successfully", nil)
+}
+
+// FindSlots returns open appointment slots, e.g.
+// GET /availability/slots?doctor_id=3&doctor_id=7&from=2026-03-27&to=2026-03-30&appointment_type_id=1
+func (h *Handler) FindSlots(c *gin.Context) {
+	var query models.SlotQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query", err)
+		return
+	}
+
+	slots, err := h.service.FindSlots(query)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to find slots", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Slots retrieved successfully", slots)
+}
+
+// statusFor maps service errors to HTTP status codes.
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, user.ErrNotDoctor):
+		return http.StatusNotFound
+	case errors.Is(err, ErrInvalidDate), errors.Is(err, ErrInvalidRange), errors.Is(err, ErrInvalidTimeZone),
+		errors.Is(err, ErrNoDuration), errors.Is(err, ErrExceptionHours), errors.Is(err, ErrInvalidLeavePeriod),
+		errors.Is(err, ErrInvalidTime):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
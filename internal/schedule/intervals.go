@@ -0,0 +1,178 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"hospital-management/internal/models"
+)
+
+var ErrInvalidTime = errors.New("invalid time block")
+
+// interval is the half-open time range [start, end).
+type interval struct {
+	start time.Time
+	end   time.Time
+}
+
+// parseClock parses a wall-clock time "HH:MM". "24:00" is accepted as the
+// end of the day.
+func parseClock(s string) (hour, minute int, err error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 || len(parts[0]) != 2 || len(parts[1]) != 2 {
+		return 0, 0, fmt.Errorf("%w: %q, expected HH:MM", ErrInvalidTime, s)
+	}
+	hour, err = strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %q, expected HH:MM", ErrInvalidTime, s)
+	}
+	minute, err = strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %q, expected HH:MM", ErrInvalidTime, s)
+	}
+	if hour < 0 || hour > 24 || minute < 0 || minute > 59 || (hour == 24 && minute != 0) {
+		return 0, 0, fmt.Errorf("%w: %q is not a time of day", ErrInvalidTime, s)
+	}
+	return hour, minute, nil
+}
+
+// validateBlock checks that start and end are clock times with start before
+// end.
+func validateBlock(start, end string) error {
+	sh, sm, err := parseClock(start)
+	if err != nil {
+		return err
+	}
+	eh, em, err := parseClock(end)
+	if err != nil {
+		return err
+	}
+	if sh*60+sm >= eh*60+em {
+		return fmt.Errorf("%w: start time %s must be before end time %s", ErrInvalidTime, start, end)
+	}
+	return nil
+}
+
+// at returns the instant the wall clock in loc shows clock on the given
+// date. A time skipped by a DST change is moved forward by the length of the
+// gap, which time.Date does only for zones east of UTC.
+func at(year int, month time.Month, day int, clock string, loc *time.Location) time.Time {
+	hour, minute, _ := parseClock(clock)
+	t := time.Date(year, month, day, hour, minute, 0, 0, loc)
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+	if want := time.Date(year, month, day, hour, minute, 0, 0, time.UTC); wall.Before(want) {
+		t = t.Add(want.Sub(wall))
+	}
+	return t
+}
+
+// dayIntervals returns the open intervals of one calendar date in loc from
+// the doctor's weekly hours and the exceptions falling on that date.
+func dayIntervals(year int, month time.Month, day int, loc *time.Location, weekly []models.WeeklyHours, exceptions []models.ScheduleException) []interval {
+	weekday := int(time.Date(year, month, day, 12, 0, 0, 0, loc).Weekday())
+	block := func(start, end string) interval {
+		return interval{at(year, month, day, start, loc), at(year, month, day, end, loc)}
+	}
+
+	var open []interval
+	replaced := false
+	for _, e := range exceptions {
+		if e.Available && e.StartTime != "" {
+			open = append(open, block(e.StartTime, e.EndTime))
+			replaced = true
+		}
+	}
+
+	if !replaced {
+		for _, h := range weekly {
+			if h.Weekday == weekday && h.Kind == models.HoursWork {
+				open = append(open, block(h.StartTime, h.EndTime))
+			}
+		}
+		for _, h := range weekly {
+			if h.Weekday == weekday && h.Kind == models.HoursBreak {
+				open = subtract(open, block(h.StartTime, h.EndTime))
+			}
+		}
+	}
+
+	for _, e := range exceptions {
+		if e.Available {
+			continue
+		}
+		if e.StartTime == "" {
+			return nil
+		}
+		open = subtract(open, block(e.StartTime, e.EndTime))
+	}
+
+	return normalize(open)
+}
+
+// subtract removes cut from every interval in intervals.
+func subtract(intervals []interval, cut interval) []interval {
+	var result []interval
+	for _, iv := range intervals {
+		if !cut.start.Before(iv.end) || !cut.end.After(iv.start) {
+			result = append(result, iv)
+			continue
+		}
+		if iv.start.Before(cut.start) {
+			result = append(result, interval{iv.start, cut.start})
+		}
+		if cut.end.Before(iv.end) {
+			result = append(result, interval{cut.end, iv.end})
+		}
+	}
+	return result
+}
+
+// normalize sorts intervals and merges those that overlap or touch.
+func normalize(intervals []interval) []interval {
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].start.Before(intervals[j].start)
+	})
+
+	var merged []interval
+	for _, iv := range intervals {
+		if !iv.start.Before(iv.end) {
+			continue
+		}
+		if n := len(merged); n > 0 && !iv.start.After(merged[n-1].end) {
+			if iv.end.After(merged[n-1].end) {
+				merged[n-1].end = iv.end
+			}
+			continue
+		}
+		merged = append(merged, iv)
+	}
+	return merged
+}
+
+// covers reports whether [start, end) lies entirely inside one interval.
+func covers(intervals []interval, start, end time.Time) bool {
+	for _, iv := range intervals {
+		if !start.Before(iv.start) && !end.After(iv.end) {
+			return true
+		}
+	}
+	return false
+}
+
+// slots cuts intervals into consecutive slots of the given length starting
+// no earlier than notBefore.
+func slots(intervals []interval, length time.Duration, notBefore time.Time) []models.Slot {
+	result := []models.Slot{}
+	for _, iv := range intervals {
+		for start := iv.start; !start.Add(length).After(iv.end); start = start.Add(length) {
+			if start.Before(notBefore) {
+				continue
+			}
+			result = append(result, models.Slot{StartsAt: start, EndsAt: start.Add(length)})
+		}
+	}
+	return result
+}
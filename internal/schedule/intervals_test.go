@@ -0,0 +1,301 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+	"hospital-management/internal/models"
+)
+
+// instant parses an RFC 3339 time, so that expectations name the offset in
+// effect on either side of a DST change.
+func instant(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func span(start, end string) interval {
+	return interval{instant(start), instant(end)}
+}
+
+func location(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s unavailable: %v", name, err)
+	}
+	return loc
+}
+
+func assertIntervals(t *testing.T, got, want []interval) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("intervals = %v, want %v", got, want)
+	}
+	for i := range got {
+		if !got[i].start.Equal(want[i].start) || !got[i].end.Equal(want[i].end) {
+			t.Fatalf("intervals = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestAt(t *testing.T) {
+	newYork := location(t, "America/New_York")
+	berlin := location(t, "Europe/Berlin")
+
+	tests := []struct {
+		name  string
+		loc   *time.Location
+		month time.Month
+		day   int
+		clock string
+		want  string
+	}{
+		{"ordinary day", newYork, time.March, 7, "09:00", "2026-03-07T09:00:00-05:00"},
+		{"before the skipped hour", newYork, time.March, 8, "01:30", "2026-03-08T01:30:00-05:00"},
+		{"start of the skipped hour", newYork, time.March, 8, "02:00", "2026-03-08T03:00:00-04:00"},
+		{"inside the skipped hour", newYork, time.March, 8, "02:30", "2026-03-08T03:30:00-04:00"},
+		{"after the skipped hour", newYork, time.March, 8, "03:00", "2026-03-08T03:00:00-04:00"},
+		{"end of the short day", newYork, time.March, 8, "24:00", "2026-03-09T00:00:00-04:00"},
+		{"inside the repeated hour", newYork, time.November, 1, "01:30", "2026-11-01T01:30:00-04:00"},
+		{"after the repeated hour", newYork, time.November, 1, "02:00", "2026-11-01T02:00:00-05:00"},
+		{"end of the long day", newYork, time.November, 1, "24:00", "2026-11-02T00:00:00-05:00"},
+		{"skipped hour east of UTC", berlin, time.March, 29, "02:30", "2026-03-29T03:30:00+02:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := at(2026, tt.month, tt.day, tt.clock, tt.loc)
+			if want := instant(tt.want); !got.Equal(want) {
+				t.Errorf("at(%s) = %v, want %v", tt.clock, got, want)
+			}
+		})
+	}
+}
+
+func TestDayIntervals(t *testing.T) {
+	newYork := location(t, "America/New_York")
+	sunday := int(time.Sunday)
+	work := func(weekday int, start, end string) models.WeeklyHours {
+		return models.WeeklyHours{Kind: models.HoursWork, Weekday: weekday, StartTime: start, EndTime: end}
+	}
+	rest := func(weekday int, start, end string) models.WeeklyHours {
+		return models.WeeklyHours{Kind: models.HoursBreak, Weekday: weekday, StartTime: start, EndTime: end}
+	}
+
+	tests := []struct {
+		name       string
+		month      time.Month
+		day        int
+		weekly     []models.WeeklyHours
+		exceptions []models.ScheduleException
+		want       []interval
+	}{
+		{"hours spanning the skipped hour", time.March, 8,
+			[]models.WeeklyHours{work(sunday, "01:00", "04:00")}, nil,
+			[]interval{span("2026-03-08T01:00:00-05:00", "2026-03-08T04:00:00-04:00")}},
+		{"break ending in the skipped hour", time.March, 8,
+			[]models.WeeklyHours{work(sunday, "00:00", "06:00"), rest(sunday, "01:00", "02:30")}, nil,
+			[]interval{
+				span("2026-03-08T00:00:00-05:00", "2026-03-08T01:00:00-05:00"),
+				span("2026-03-08T03:30:00-04:00", "2026-03-08T06:00:00-04:00"),
+			}},
+		{"hours spanning the repeated hour", time.November, 1,
+			[]models.WeeklyHours{work(sunday, "00:00", "03:00")}, nil,
+			[]interval{span("2026-11-01T00:00:00-04:00", "2026-11-01T03:00:00-05:00")}},
+		{"break spanning the repeated hour", time.November, 1,
+			[]models.WeeklyHours{work(sunday, "00:00", "03:00"), rest(sunday, "01:00", "02:00")}, nil,
+			[]interval{
+				span("2026-11-01T00:00:00-04:00", "2026-11-01T01:00:00-04:00"),
+				span("2026-11-01T02:00:00-05:00", "2026-11-01T03:00:00-05:00"),
+			}},
+		{"hours of another weekday", time.November, 1,
+			[]models.WeeklyHours{work(int(time.Monday), "09:00", "17:00")}, nil,
+			nil},
+		{"available exception replaces the weekly hours", time.November, 1,
+			[]models.WeeklyHours{work(sunday, "09:00", "17:00"), rest(sunday, "10:00", "11:00")},
+			[]models.ScheduleException{{Available: true, StartTime: "09:00", EndTime: "12:00"}},
+			[]interval{span("2026-11-01T09:00:00-05:00", "2026-11-01T12:00:00-05:00")}},
+		{"unavailable exception removes time", time.November, 1,
+			[]models.WeeklyHours{work(sunday, "09:00", "17:00")},
+			[]models.ScheduleException{{Available: false, StartTime: "12:00", EndTime: "13:00"}},
+			[]interval{
+				span("2026-11-01T09:00:00-05:00", "2026-11-01T12:00:00-05:00"),
+				span("2026-11-01T13:00:00-05:00", "2026-11-01T17:00:00-05:00"),
+			}},
+		{"day off", time.November, 1,
+			[]models.WeeklyHours{work(sunday, "09:00", "17:00")},
+			[]models.ScheduleException{{Available: true, StartTime: "09:00", EndTime: "12:00"}, {Available: false}},
+			nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dayIntervals(2026, tt.month, tt.day, newYork, tt.weekly, tt.exceptions)
+			assertIntervals(t, got, tt.want)
+		})
+	}
+}
+
+func TestSubtract(t *testing.T) {
+	morning := span("2026-03-08T09:00:00Z", "2026-03-08T12:00:00Z")
+
+	tests := []struct {
+		name      string
+		intervals []interval
+		cut       interval
+		want      []interval
+	}{
+		{"cut before", []interval{morning},
+			span("2026-03-08T07:00:00Z", "2026-03-08T08:00:00Z"),
+			[]interval{morning}},
+		{"cut touching the end", []interval{morning},
+			span("2026-03-08T12:00:00Z", "2026-03-08T13:00:00Z"),
+			[]interval{morning}},
+		{"cut inside", []interval{morning},
+			span("2026-03-08T10:00:00Z", "2026-03-08T10:30:00Z"),
+			[]interval{
+				span("2026-03-08T09:00:00Z", "2026-03-08T10:00:00Z"),
+				span("2026-03-08T10:30:00Z", "2026-03-08T12:00:00Z"),
+			}},
+		{"cut over the start", []interval{morning},
+			span("2026-03-08T08:00:00Z", "2026-03-08T10:00:00Z"),
+			[]interval{span("2026-03-08T10:00:00Z", "2026-03-08T12:00:00Z")}},
+		{"cut over the end", []interval{morning},
+			span("2026-03-08T11:00:00Z", "2026-03-08T13:00:00Z"),
+			[]interval{span("2026-03-08T09:00:00Z", "2026-03-08T11:00:00Z")}},
+		{"cut covering everything", []interval{morning},
+			span("2026-03-08T09:00:00Z", "2026-03-08T12:00:00Z"),
+			nil},
+		{"cut across two intervals", []interval{morning, span("2026-03-08T13:00:00Z", "2026-03-08T17:00:00Z")},
+			span("2026-03-08T11:00:00Z", "2026-03-08T14:00:00Z"),
+			[]interval{
+				span("2026-03-08T09:00:00Z", "2026-03-08T11:00:00Z"),
+				span("2026-03-08T14:00:00Z", "2026-03-08T17:00:00Z"),
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertIntervals(t, subtract(tt.intervals, tt.cut), tt.want)
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name      string
+		intervals []interval
+		want      []interval
+	}{
+		{"empty", nil, nil},
+		{"unsorted", []interval{
+			span("2026-11-01T13:00:00Z", "2026-11-01T14:00:00Z"),
+			span("2026-11-01T09:00:00Z", "2026-11-01T10:00:00Z"),
+		}, []interval{
+			span("2026-11-01T09:00:00Z", "2026-11-01T10:00:00Z"),
+			span("2026-11-01T13:00:00Z", "2026-11-01T14:00:00Z"),
+		}},
+		{"overlapping", []interval{
+			span("2026-11-01T09:00:00Z", "2026-11-01T11:00:00Z"),
+			span("2026-11-01T10:00:00Z", "2026-11-01T12:00:00Z"),
+		}, []interval{span("2026-11-01T09:00:00Z", "2026-11-01T12:00:00Z")}},
+		{"touching", []interval{
+			span("2026-11-01T10:00:00Z", "2026-11-01T12:00:00Z"),
+			span("2026-11-01T09:00:00Z", "2026-11-01T10:00:00Z"),
+		}, []interval{span("2026-11-01T09:00:00Z", "2026-11-01T12:00:00Z")}},
+		{"contained", []interval{
+			span("2026-11-01T09:00:00Z", "2026-11-01T12:00:00Z"),
+			span("2026-11-01T10:00:00Z", "2026-11-01T11:00:00Z"),
+		}, []interval{span("2026-11-01T09:00:00Z", "2026-11-01T12:00:00Z")}},
+		{"empty and inverted dropped", []interval{
+			span("2026-11-01T09:00:00Z", "2026-11-01T09:00:00Z"),
+			span("2026-11-01T12:00:00Z", "2026-11-01T11:00:00Z"),
+		}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertIntervals(t, normalize(tt.intervals), tt.want)
+		})
+	}
+}
+
+func TestCovers(t *testing.T) {
+	intervals := []interval{
+		span("2026-11-01T09:00:00Z", "2026-11-01T12:00:00Z"),
+		span("2026-11-01T13:00:00Z", "2026-11-01T17:00:00Z"),
+	}
+
+	tests := []struct {
+		name       string
+		start, end string
+		want       bool
+	}{
+		{"inside", "2026-11-01T10:00:00Z", "2026-11-01T10:30:00Z", true},
+		{"whole interval", "2026-11-01T09:00:00Z", "2026-11-01T12:00:00Z", true},
+		{"ending at the close", "2026-11-01T11:30:00Z", "2026-11-01T12:00:00Z", true},
+		{"past the close", "2026-11-01T11:30:00Z", "2026-11-01T12:30:00Z", false},
+		{"across the gap", "2026-11-01T11:00:00Z", "2026-11-01T14:00:00Z", false},
+		{"before opening", "2026-11-01T08:30:00Z", "2026-11-01T09:30:00Z", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := covers(intervals, instant(tt.start), instant(tt.end)); got != tt.want {
+				t.Errorf("covers(%s, %s) = %v, want %v", tt.start, tt.end, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlots(t *testing.T) {
+	tests := []struct {
+		name      string
+		intervals []interval
+		length    time.Duration
+		notBefore string
+		want      []string
+	}{
+		{"across the skipped hour",
+			[]interval{span("2026-03-08T01:00:00-05:00", "2026-03-08T04:00:00-04:00")},
+			30 * time.Minute, "2026-03-08T00:00:00-05:00",
+			[]string{
+				"2026-03-08T01:00:00-05:00", "2026-03-08T01:30:00-05:00",
+				"2026-03-08T03:00:00-04:00", "2026-03-08T03:30:00-04:00",
+			}},
+		{"across the repeated hour",
+			[]interval{span("2026-11-01T00:00:00-04:00", "2026-11-01T03:00:00-05:00")},
+			time.Hour, "2026-11-01T00:00:00-04:00",
+			[]string{
+				"2026-11-01T00:00:00-04:00", "2026-11-01T01:00:00-04:00",
+				"2026-11-01T01:00:00-05:00", "2026-11-01T02:00:00-05:00",
+			}},
+		{"partial slot dropped",
+			[]interval{span("2026-11-02T09:00:00-05:00", "2026-11-02T10:45:00-05:00")},
+			30 * time.Minute, "2026-11-02T00:00:00-05:00",
+			[]string{"2026-11-02T09:00:00-05:00", "2026-11-02T09:30:00-05:00", "2026-11-02T10:00:00-05:00"}},
+		{"slots before notBefore skipped",
+			[]interval{
+				span("2026-11-02T09:00:00-05:00", "2026-11-02T10:00:00-05:00"),
+				span("2026-11-02T11:00:00-05:00", "2026-11-02T12:00:00-05:00"),
+			},
+			30 * time.Minute, "2026-11-02T09:10:00-05:00",
+			[]string{"2026-11-02T09:30:00-05:00", "2026-11-02T11:00:00-05:00", "2026-11-02T11:30:00-05:00"}},
+		{"no intervals", nil, 30 * time.Minute, "2026-11-02T00:00:00-05:00", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := slots(tt.intervals, tt.length, instant(tt.notBefore))
+			if got == nil {
+				t.Fatal("slots = nil, want an empty list")
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("slots = %v, want starts %v", got, tt.want)
+			}
+			for i, slot := range got {
+				start := instant(tt.want[i])
+				if !slot.StartsAt.Equal(start) || !slot.EndsAt.Equal(start.Add(tt.length)) {
+					t.Errorf("slot %d = %v–%v, want %v–%v", i, slot.StartsAt, slot.EndsAt, start, start.Add(tt.length))
+				}
+			}
+		})
+	}
+}
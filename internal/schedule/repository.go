@@ -0,0 +1,117 @@
+package schedule
+
+import (
+	"time"
+	"hospital-management/internal/models"
+	"gorm.io/gorm"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) ListWeekly(doctorID uint) ([]models.WeeklyHours, error) {
+	var hours []models.WeeklyHours
+	err := r.db.Where("doctor_id = ?", doctorID).
+		Order("weekday, start_time, kind").
+		Find(&hours).Error
+	return hours, err
+}
+
+// ReplaceWeekly swaps a doctor's weekly hours for hours and, when timeZone
+// is not empty, updates the doctor's time zone.
+func (r *Repository) ReplaceWeekly(doctorID uint, timeZone string, hours []models.WeeklyHours) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if timeZone != "" {
+			if err := tx.Model(&models.User{}).Where("id = ?", doctorID).Updates(map[string]interface{}{
+				"time_zone": timeZone,
+				"version":   gorm.Expr("version + 1"),
+			}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("doctor_id = ?", doctorID).Delete(&models.WeeklyHours{}).Error; err != nil {
+			return err
+		}
+		if len(hours) == 0 {
+			return nil
+		}
+		return tx.Create(&hours).Error
+	})
+}
+
+func (r *Repository) HasWeekly(doctorID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.WeeklyHours{}).Where("doctor_id = ?", doctorID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *Repository) CreateLeave(leave *models.DoctorLeave) error {
+	return r.db.Create(leave).Error
+}
+
+func (r *Repository) DeleteLeave(doctorID, id uint) error {
+	result := r.db.Where("doctor_id = ?", doctorID).Delete(&models.DoctorLeave{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListLeave returns the doctor's leave overlapping [from, to).
+func (r *Repository) ListLeave(doctorID uint, from, to time.Time) ([]models.DoctorLeave, error) {
+	var leave []models.DoctorLeave
+	err := r.db.Where("doctor_id = ? AND starts_at < ? AND ends_at > ?", doctorID, to, from).
+		Order("starts_at").
+		Find(&leave).Error
+	return leave, err
+}
+
+func (r *Repository) CreateException(exception *models.ScheduleException) error {
+	return r.db.Create(exception).Error
+}
+
+func (r *Repository) DeleteException(doctorID, id uint) error {
+	result := r.db.Where("doctor_id = ?", doctorID).Delete(&models.ScheduleException{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListExceptions returns the doctor's exceptions dated from first to last,
+// inclusive.
+func (r *Repository) ListExceptions(doctorID uint, first, last time.Time) ([]models.ScheduleException, error) {
+	var exceptions []models.ScheduleException
+	err := r.db.Where("doctor_id = ? AND date BETWEEN ? AND ?", doctorID, first.Format("2006-01-02"), last.Format("2006-01-02")).
+		Order("date, start_time").
+		Find(&exceptions).Error
+	return exceptions, err
+}
+
+// ListBooked returns the doctor's scheduled appointments overlapping
+// [from, to).
+func (r *Repository) ListBooked(doctorID uint, from, to time.Time) ([]models.Appointment, error) {
+	var appointments []models.Appointment
+	err := r.db.Where("doctor_id = ? AND status = ? AND starts_at < ? AND ends_at > ?",
+		doctorID, models.AppointmentScheduled, to, from).
+		Order("starts_at").
+		Find(&appointments).Error
+	return appointments, err
+}
+
+func (r *Repository) GetAppointmentType(id uint) (*models.AppointmentType, error) {
+	var appointmentType models.AppointmentType
+	err := r.db.First(&appointmentType, id).Error
+	return &appointmentType, err
+}
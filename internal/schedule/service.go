@@ -0,0 +1,346 @@
+package schedule
+
+import (
+	"errors"
+	"time"
+	"hospital-management/internal/config"
+	"hospital-management/internal/models"
+	"hospital-management/internal/user"
+)
+
+// maxSlotRangeDays bounds how many calendar days one slot search may cover.
+const maxSlotRangeDays = 31
+
+var (
+	ErrInvalidDate        = errors.New("invalid date, expected YYYY-MM-DD")
+	ErrInvalidRange       = errors.New("range must not end before it starts and may cover at most 31 days")
+	ErrInvalidTimeZone    = errors.New("unknown time zone")
+	ErrNoDuration         = errors.New("appointment_type_id or duration is required")
+	ErrExceptionHours     = errors.New("an available exception needs start_time and end_time")
+	ErrDoctorUnavailable  = errors.New("doctor is not available at that time")
+	ErrInvalidLeavePeriod = errors.New("leave must end after it starts")
+)
+
+type Service struct {
+	repo        *Repository
+	userService *user.Service
+	location    *time.Location
+}
+
+func NewService(repo *Repository, userService *user.Service, cfg *config.Config) *Service {
+	return &Service{repo: repo, userService: userService, location: cfg.Location}
+}
+
+// GetSchedule returns a doctor's weekly hours together with leave and
+// exceptions that have not yet passed.
+func (s *Service) GetSchedule(doctorID uint) (*models.DoctorSchedule, error) {
+	doctor, err := s.userService.GetActiveDoctor(doctorID)
+	if err != nil {
+		return nil, err
+	}
+	loc := s.doctorLocation(doctor)
+
+	weekly, err := s.repo.ListWeekly(doctorID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	leave, err := s.repo.ListLeave(doctorID, now, now.AddDate(10, 0, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	today := now.In(loc)
+	exceptions, err := s.repo.ListExceptions(doctorID, today, today.AddDate(10, 0, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.DoctorSchedule{
+		DoctorID:   doctorID,
+		TimeZone:   loc.String(),
+		Weekly:     weekly,
+		Leave:      leave,
+		Exceptions: exceptions,
+	}, nil
+}
+
+// SetWeekly replaces a doctor's weekly hours and optionally their time zone.
+func (s *Service) SetWeekly(doctorID uint, req models.SetWeeklyHoursRequest) (*models.DoctorSchedule, error) {
+	if _, err := s.userService.GetActiveDoctor(doctorID); err != nil {
+		return nil, err
+	}
+	if req.TimeZone != "" {
+		if _, err := time.LoadLocation(req.TimeZone); err != nil {
+			return nil, ErrInvalidTimeZone
+		}
+	}
+
+	hours := make([]models.WeeklyHours, 0, len(req.Hours))
+	for _, h := range req.Hours {
+		if err := validateBlock(h.StartTime, h.EndTime); err != nil {
+			return nil, err
+		}
+		hours = append(hours, models.WeeklyHours{
+			DoctorID:  doctorID,
+			Kind:      h.Kind,
+			Weekday:   h.Weekday,
+			StartTime: h.StartTime,
+			EndTime:   h.EndTime,
+		})
+	}
+
+	if err := s.repo.ReplaceWeekly(doctorID, req.TimeZone, hours); err != nil {
+		return nil, err
+	}
+
+	return s.GetSchedule(doctorID)
+}
+
+func (s *Service) AddLeave(doctorID uint, req models.CreateLeaveRequest, createdBy uint) (*models.DoctorLeave, error) {
+	if _, err := s.userService.GetActiveDoctor(doctorID); err != nil {
+		return nil, err
+	}
+	if !req.EndsAt.After(req.StartsAt) {
+		return nil, ErrInvalidLeavePeriod
+	}
+
+	leave := &models.DoctorLeave{
+		DoctorID:    doctorID,
+		StartsAt:    req.StartsAt.UTC(),
+		EndsAt:      req.EndsAt.UTC(),
+		Reason:      req.Reason,
+		CreatedByID: createdBy,
+	}
+	if err := s.repo.CreateLeave(leave); err != nil {
+		return nil, err
+	}
+	return leave, nil
+}
+
+func (s *Service) DeleteLeave(doctorID, id uint) error {
+	return s.repo.DeleteLeave(doctorID, id)
+}
+
+func (s *Service) AddException(doctorID uint, req models.CreateScheduleExceptionRequest, createdBy uint) (*models.ScheduleException, error) {
+	if _, err := s.userService.GetActiveDoctor(doctorID); err != nil {
+		return nil, err
+	}
+
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return nil, ErrInvalidDate
+	}
+	if req.StartTime != "" || req.EndTime != "" {
+		if err := validateBlock(req.StartTime, req.EndTime); err != nil {
+			return nil, err
+		}
+	} else if req.Available {
+		return nil, ErrExceptionHours
+	}
+
+	exception := &models.ScheduleException{
+		DoctorID:    doctorID,
+		Date:        date,
+		Available:   req.Available,
+		StartTime:   req.StartTime,
+		EndTime:     req.EndTime,
+		Reason:      req.Reason,
+		CreatedByID: createdBy,
+	}
+	if err := s.repo.CreateException(exception); err != nil {
+		return nil, err
+	}
+	return exception, nil
+}
+
+func (s *Service) DeleteException(doctorID, id uint) error {
+	return s.repo.DeleteException(doctorID, id)
+}
+
+// FindSlots returns the open slots of each requested doctor. Dates are
+// calendar days in the doctor's own time zone, and wall-clock hours are
+// resolved per day so that slots follow DST changes.
+func (s *Service) FindSlots(q models.SlotQuery) ([]models.DoctorSlots, error) {
+	length := time.Duration(q.DurationMinutes) * time.Minute
+	if q.AppointmentTypeID != 0 {
+		appointmentType, err := s.repo.GetAppointmentType(q.AppointmentTypeID)
+		if err != nil {
+			return nil, err
+		}
+		length = time.Duration(appointmentType.DurationMinutes) * time.Minute
+	}
+	if length <= 0 {
+		return nil, ErrNoDuration
+	}
+
+	var out *time.Location
+	if q.TimeZone != "" {
+		loc, err := time.LoadLocation(q.TimeZone)
+		if err != nil {
+			return nil, ErrInvalidTimeZone
+		}
+		out = loc
+	}
+
+	now := time.Now()
+	results := make([]models.DoctorSlots, 0, len(q.DoctorIDs))
+	for _, doctorID := range q.DoctorIDs {
+		doctor, err := s.userService.GetActiveDoctor(doctorID)
+		if err != nil {
+			return nil, err
+		}
+		loc := s.doctorLocation(doctor)
+
+		first, last, err := dateRange(q.From, q.To, loc)
+		if err != nil {
+			return nil, err
+		}
+
+		open, err := s.openIntervals(doctorID, loc, first, last)
+		if err != nil {
+			return nil, err
+		}
+
+		booked, err := s.repo.ListBooked(doctorID, first, last.AddDate(0, 0, 1))
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range booked {
+			open = subtract(open, interval{a.StartsAt, a.EndsAt})
+		}
+
+		zone := loc
+		if out != nil {
+			zone = out
+		}
+		found := slots(open, length, now)
+		for i := range found {
+			found[i].StartsAt = found[i].StartsAt.In(zone)
+			found[i].EndsAt = found[i].EndsAt.In(zone)
+		}
+
+		results = append(results, models.DoctorSlots{
+			DoctorID: doctorID,
+			TimeZone: zone.String(),
+			Slots:    found,
+		})
+	}
+
+	return results, nil
+}
+
+// CheckAvailable returns ErrDoctorUnavailable when [start, end) falls in the
+// doctor's leave or, for doctors with weekly hours, outside their working
+// time. Doctors without weekly hours can be booked at any time.
+func (s *Service) CheckAvailable(doctorID uint, start, end time.Time) error {
+	leave, err := s.repo.ListLeave(doctorID, start, end)
+	if err != nil {
+		return err
+	}
+	if len(leave) > 0 {
+		return ErrDoctorUnavailable
+	}
+
+	hasWeekly, err := s.repo.HasWeekly(doctorID)
+	if err != nil || !hasWeekly {
+		return err
+	}
+
+	doctor, err := s.userService.GetActiveDoctor(doctorID)
+	if err != nil {
+		return err
+	}
+	loc := s.doctorLocation(doctor)
+
+	first := midnight(start.In(loc), loc)
+	last := midnight(end.In(loc), loc)
+	open, err := s.openIntervals(doctorID, loc, first, last)
+	if err != nil {
+		return err
+	}
+	if !covers(open, start, end) {
+		return ErrDoctorUnavailable
+	}
+	return nil
+}
+
+// openIntervals returns the doctor's working time on the calendar days from
+// first to last, inclusive, minus leave. Booked appointments are not
+// removed.
+func (s *Service) openIntervals(doctorID uint, loc *time.Location, first, last time.Time) ([]interval, error) {
+	weekly, err := s.repo.ListWeekly(doctorID)
+	if err != nil {
+		return nil, err
+	}
+	exceptions, err := s.repo.ListExceptions(doctorID, first, last)
+	if err != nil {
+		return nil, err
+	}
+
+	byDate := make(map[string][]models.ScheduleException)
+	for _, e := range exceptions {
+		key := e.Date.UTC().Format("2006-01-02")
+		byDate[key] = append(byDate[key], e)
+	}
+
+	var open []interval
+	year, month, day := first.Date()
+	for i := 0; ; i++ {
+		date := time.Date(year, month, day+i, 0, 0, 0, 0, loc)
+		if date.After(last) {
+			break
+		}
+		y, m, d := date.Date()
+		open = append(open, dayIntervals(y, m, d, loc, weekly, byDate[date.Format("2006-01-02")])...)
+	}
+
+	end := time.Date(last.Year(), last.Month(), last.Day()+1, 0, 0, 0, 0, loc)
+	leave, err := s.repo.ListLeave(doctorID, first, end)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range leave {
+		open = subtract(open, interval{l.StartsAt, l.EndsAt})
+	}
+
+	return normalize(open), nil
+}
+
+// doctorLocation returns the doctor's time zone, or the clinic's when the
+// doctor has none or it cannot be loaded.
+func (s *Service) doctorLocation(doctor *models.User) *time.Location {
+	if doctor.TimeZone != "" {
+		if loc, err := time.LoadLocation(doctor.TimeZone); err == nil {
+			return loc
+		}
+	}
+	return s.location
+}
+
+// dateRange parses the inclusive calendar range [from, to] in loc. to
+// defaults to a week from from.
+func dateRange(from, to string, loc *time.Location) (time.Time, time.Time, error) {
+	first, err := time.ParseInLocation("2006-01-02", from, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidDate
+	}
+
+	last := first.AddDate(0, 0, 6)
+	if to != "" {
+		if last, err = time.ParseInLocation("2006-01-02", to, loc); err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidDate
+		}
+	}
+
+	if last.Before(first) || last.After(first.AddDate(0, 0, maxSlotRangeDays)) {
+		return time.Time{}, time.Time{}, ErrInvalidRange
+	}
+	return first, last, nil
+}
+
+func midnight(t time.Time, loc *time.Location) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
@@ -1,7 +1,9 @@
 package user
 
 import (
+	"errors"
 	"net/http"
+	"time"
 	"hospital-management/pkg/utils"
 	"github.com/gin-gonic/gin"
 )
@@ -23,9 +25,7 @@ func NewHandler(service *Service) *Handler {
 // @Failure 401 {object} utils.Response
 // @Router /profile [get]
 func (h *Handler) GetProfile(c *gin.Context) {
-	userInterface := c.MustGet("user")
-	userMap := userInterface.(map[string]interface{})
-	userID := uint(userMap["id"].(float64))
+	userID := utils.CurrentUserID(c)
 
 	user, err := h.service.GetByID(userID)
 	if err != nil {
@@ -33,6 +33,9 @@ func (h *Handler) GetProfile(c *gin.Context) {
 		return
 	}
 
+	if utils.NotModified(c, user.Version) {
+		return
+	}
 	utils.SuccessResponse(c, "Profile retrieved successfully", user)
 }
 
@@ -42,13 +45,19 @@ func (h *Handler) GetProfile(c *gin.Context) {
 // @Security Bearer
 // @Accept json
 // @Produce json
+// @Param If-Match header string true "ETag of the profile"
 // @Success 200 {object} utils.Response
 // @Failure 400 {object} utils.Response
+// @Failure 412 {object} utils.Response
+// @Failure 428 {object} utils.Response
 // @Router /profile [put]
 func (h *Handler) UpdateProfile(c *gin.Context) {
-	userInterface := c.MustGet("user")
-	userMap := userInterface.(map[string]interface{})
-	userID := uint(userMap["id"].(float64))
+	userID := utils.CurrentUserID(c)
+
+	match, ok := utils.IfMatch(c)
+	if !ok {
+		return
+	}
 
 	user, err := h.service.GetByID(userID)
 	if err != nil {
@@ -75,11 +84,28 @@ func (h *Handler) UpdateProfile(c *gin.Context) {
 	if email, ok := updateData["email"].(string); ok {
 		user.Email = email
 	}
+	if timeZone, ok := updateData["time_zone"].(string); ok {
+		if _, err := time.LoadLocation(timeZone); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid time zone", err)
+			return
+		}
+		user.TimeZone = timeZone
+	}
 
+	if !match.Matches(user.Version) {
+		utils.ErrorResponse(c, http.StatusPreconditionFailed, "Profile was modified, reload and retry", utils.ErrVersionConflict)
+		return
+	}
 	if err := h.service.Update(user); err != nil {
+		if errors.Is(err, utils.ErrVersionConflict) {
+			utils.ErrorResponse(c, http.StatusPreconditionFailed, "Profile was modified, reload and retry", err)
+			return
+		}
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update profile", err)
 		return
 	}
 
+	utils.SetETag(c, user.Version)
 	utils.SuccessResponse(c, "Profile updated successfully", user)
 }
+
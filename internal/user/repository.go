@@ -2,6 +2,7 @@ package user
 
 import (
 	"hospital-management/internal/models"
+	"hospital-management/pkg/utils"
 	"gorm.io/gorm"
 )
 
@@ -23,6 +24,18 @@ func (r *Repository) GetByID(id uint) (*models.User, error) {
 	return &user, err
 }
 
+func (r *Repository) GetByIDWithRoles(id uint) (*models.User, error) {
+	var user models.User
+	err := r.db.Preload("Roles").First(&user, id).Error
+	return &user, err
+}
+
+func (r *Repository) GetByIDWithPermissions(id uint) (*models.User, error) {
+	var user models.User
+	err := r.db.Preload("Roles.Permissions").First(&user, id).Error
+	return &user, err
+}
+
 func (r *Repository) GetByUsername(username string) (*models.User, error) {
 	var user models.User
 	err := r.db.Where("username = ?", username).First(&user).Error
@@ -35,6 +48,167 @@ func (r *Repository) GetByEmail(email string) (*models.User, error) {
 	return &user, err
 }
 
+func (r *Repository) List(filter models.UserFilter) ([]models.User, error) {
+	var users []models.User
+	query := r.db.Preload("Roles")
+
+	if filter.Role != "" {
+		query = query.Where("id IN (?)", r.db.Table("user_roles").
+			Select("user_roles.user_id").
+			Joins("JOIN roles ON roles.id = user_roles.role_id").
+			Where("roles.name = ?", filter.Role))
+	}
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+	if filter.PendingApproval != nil {
+		query = query.Where("pending_approval = ?", *filter.PendingApproval)
+	}
+	if filter.Search != "" {
+		like := "%" + filter.Search + "%"
+		query = query.Where("username ILIKE ? OR email ILIKE ? OR first_name ILIKE ? OR last_name ILIKE ?", like, like, like, like)
+	}
+
+	err := query.Order("id").Find(&users).Error
+	return users, err
+}
+
+// Update saves user if the stored row is still at user.Version and
+// increments the version. Otherwise it returns utils.ErrVersionConflict.
 func (r *Repository) Update(user *models.User) error {
-	return r.db.Save(user).Error
-}
\ No newline at end of file
+	expected := user.Version
+	user.Version++
+
+	result := r.db.Model(user).
+		Where("version = ?", expected).
+		Select("*").
+		Omit("Roles", "CreatedAt").
+		Updates(user)
+	if result.Error != nil {
+		user.Version = expected
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		user.Version = expected
+		return conflictOrNotFound(r.db, user.ID)
+	}
+	return nil
+}
+
+// Delete soft-deletes a user if it is still at the given version.
+func (r *Repository) Delete(id, version uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, id, version); err != nil {
+			return err
+		}
+		return tx.Delete(&models.User{}, id).Error
+	})
+}
+
+// bumpVersion increments the version of a user still at version.
+func bumpVersion(tx *gorm.DB, id, version uint) error {
+	result := tx.Model(&models.User{}).
+		Where("id = ? AND version = ?", id, version).
+		Update("version", gorm.Expr("version + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return conflictOrNotFound(tx, id)
+	}
+	return nil
+}
+
+// conflictOrNotFound explains why a conditional write matched no row.
+func conflictOrNotFound(db *gorm.DB, id uint) error {
+	var count int64
+	if err := db.Model(&models.User{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return utils.ErrVersionConflict
+}
+
+func (r *Repository) ListDeleted() ([]models.User, error) {
+	var users []models.User
+	err := r.db.Unscoped().Preload("Roles").
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Find(&users).Error
+	return users, err
+}
+
+func (r *Repository) Restore(id uint) error {
+	result := r.db.Unscoped().Model(&models.User{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"version":    gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ReplaceRoles replaces the roles of a user still at user.Version.
+func (r *Repository) ReplaceRoles(user *models.User, roles []models.Role) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, user.ID, user.Version); err != nil {
+			return err
+		}
+		if err := tx.Model(user).Association("Roles").Replace(roles); err != nil {
+			return err
+		}
+		return tx.Model(user).Update("role", user.Role).Error
+	})
+}
+
+func (r *Repository) GetRoleByName(name string) (*models.Role, error) {
+	var role models.Role
+	err := r.db.Where("name = ?", name).First(&role).Error
+	return &role, err
+}
+
+func (r *Repository) GetRoleByID(id uint) (*models.Role, error) {
+	var role models.Role
+	err := r.db.Preload("Permissions").First(&role, id).Error
+	return &role, err
+}
+
+func (r *Repository) GetRolesByNames(names []string) ([]models.Role, error) {
+	var roles []models.Role
+	err := r.db.Where("name IN ?", names).Find(&roles).Error
+	return roles, err
+}
+
+func (r *Repository) ListRoles() ([]models.Role, error) {
+	var roles []models.Role
+	err := r.db.Preload("Permissions").Order("name").Find(&roles).Error
+	return roles, err
+}
+
+func (r *Repository) CreateRole(role *models.Role) error {
+	return r.db.Create(role).Error
+}
+
+func (r *Repository) ReplaceRolePermissions(role *models.Role, permissions []models.Permission) error {
+	return r.db.Model(role).Association("Permissions").Replace(permissions)
+}
+
+func (r *Repository) ListPermissions() ([]models.Permission, error) {
+	var permissions []models.Permission
+	err := r.db.Order("name").Find(&permissions).Error
+	return permissions, err
+}
+
+func (r *Repository) GetPermissionsByNames(names []string) ([]models.Permission, error) {
+	var permissions []models.Permission
+	err := r.db.Where("name IN ?", names).Find(&permissions).Error
+	return permissions, err
+}
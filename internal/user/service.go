@@ -1,6 +1,19 @@
 package user
 
-import "hospital-management/internal/models"
+import (
+	"errors"
+	"hospital-management/internal/models"
+	"hospital-management/pkg/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUnknownRole       = errors.New("unknown role")
+	ErrUnknownPermission = errors.New("unknown permission")
+	ErrInvalidPassword   = errors.New("current password is incorrect")
+	ErrNotPending        = errors.New("user is not pending approval")
+	ErrNotDoctor         = errors.New("user is not an active doctor")
+)
 
 type Service struct {
 	repo *Repository
@@ -17,14 +30,295 @@ func (s *Service) Create(user *models.User) (*models.User, error) {
 	return user, nil
 }
 
+// CreateWithRoles creates an active user holding the named roles. The first
+// role becomes the user's primary role.
+func (s *Service) CreateWithRoles(req models.CreateUserRequest) (*models.User, error) {
+	roles, err := s.rolesByNames(req.Roles)
+	if err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		Username:  req.Username,
+		Email:     req.Email,
+		Password:  hashedPassword,
+		Role:      req.Roles[0],
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Phone:     req.Phone,
+		IsActive:  true,
+		Roles:     roles,
+	}
+
+	return s.Create(user)
+}
+
 func (s *Service) GetByID(id uint) (*models.User, error) {
 	return s.repo.GetByID(id)
 }
 
+func (s *Service) GetByIDWithRoles(id uint) (*models.User, error) {
+	return s.repo.GetByIDWithRoles(id)
+}
+
+// GetActiveDoctor returns the user with the given ID if it is an active
+// account holding the doctor role, and ErrNotDoctor otherwise.
+func (s *Service) GetActiveDoctor(id uint) (*models.User, error) {
+	doctor, err := s.repo.GetByIDWithRoles(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotDoctor
+		}
+		return nil, err
+	}
+	if !doctor.IsActive {
+		return nil, ErrNotDoctor
+	}
+	for _, role := range doctor.RoleNames() {
+		if role == models.RoleDoctor {
+			return doctor, nil
+		}
+	}
+	return nil, ErrNotDoctor
+}
+
+func (s *Service) GetByIDWithPermissions(id uint) (*models.User, error) {
+	return s.repo.GetByIDWithPermissions(id)
+}
+
 func (s *Service) GetByUsername(username string) (*models.User, error) {
 	return s.repo.GetByUsername(username)
 }
 
+func (s *Service) List(filter models.UserFilter) ([]models.User, error) {
+	return s.repo.List(filter)
+}
+
 func (s *Service) Update(user *models.User) error {
 	return s.repo.Update(user)
 }
+
+func (s *Service) SetActive(id uint, match utils.Precondition, active bool) (*models.User, error) {
+	user, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !match.Matches(user.Version) {
+		return nil, utils.ErrVersionConflict
+	}
+
+	user.IsActive = active
+	if err := s.repo.Update(user); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByIDWithRoles(id)
+}
+
+func (s *Service) Delete(id uint, match utils.Precondition) error {
+	user, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if !match.Matches(user.Version) {
+		return utils.ErrVersionConflict
+	}
+	return s.repo.Delete(id, user.Version)
+}
+
+func (s *Service) ListDeleted() ([]models.User, error) {
+	return s.repo.ListDeleted()
+}
+
+func (s *Service) Restore(id uint) (*models.User, error) {
+	if err := s.repo.Restore(id); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByIDWithRoles(id)
+}
+
+// Review settles a pending registration. Approved accounts become active;
+// rejected ones stay inactive and leave the approval queue.
+func (s *Service) Review(id uint, approve bool) (*models.User, error) {
+	user, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.PendingApproval {
+		return nil, ErrNotPending
+	}
+
+	user.PendingApproval = false
+	user.IsActive = approve
+	if err := s.repo.Update(user); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByIDWithRoles(id)
+}
+
+// SetRoles replaces the roles held by a user. The first role becomes the
+// user's primary role.
+func (s *Service) SetRoles(id uint, match utils.Precondition, names []string) (*models.User, error) {
+	user, err := s.repo.GetByIDWithRoles(id)
+	if err != nil {
+		return nil, err
+	}
+	if !match.Matches(user.Version) {
+		return nil, utils.ErrVersionConflict
+	}
+
+	roles, err := s.rolesByNames(names)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Role = names[0]
+	if err := s.repo.ReplaceRoles(user, roles); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByIDWithRoles(id)
+}
+
+// ResetPassword sets a new password and forces the user to change it at the
+// next login. When password is empty a temporary one is generated and
+// returned.
+func (s *Service) ResetPassword(id uint, password string) (string, error) {
+	user, err := s.repo.GetByID(id)
+	if err != nil {
+		return "", err
+	}
+
+	if password == "" {
+		password, err = utils.GenerateRandomToken(12)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return "", err
+	}
+
+	user.Password = hashedPassword
+	user.MustChangePassword = true
+	if err := s.repo.Update(user); err != nil {
+		return "", err
+	}
+
+	return password, nil
+}
+
+func (s *Service) ChangePassword(id uint, req models.ChangePasswordRequest) error {
+	user, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if !utils.CheckPasswordHash(req.CurrentPassword, user.Password) {
+		return ErrInvalidPassword
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	user.Password = hashedPassword
+	user.MustChangePassword = false
+	return s.repo.Update(user)
+}
+
+func (s *Service) GetRoleByName(name string) (*models.Role, error) {
+	return s.repo.GetRoleByName(name)
+}
+
+func (s *Service) ListRoles() ([]models.Role, error) {
+	return s.repo.ListRoles()
+}
+
+func (s *Service) ListPermissions() ([]models.Permission, error) {
+	return s.repo.ListPermissions()
+}
+
+func (s *Service) CreateRole(req models.CreateRoleRequest) (*models.Role, error) {
+	permissions, err := s.permissionsByNames(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &models.Role{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: permissions,
+	}
+	if err := s.repo.CreateRole(role); err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+func (s *Service) SetRolePermissions(id uint, names []string) (*models.Role, error) {
+	role, err := s.repo.GetRoleByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := s.permissionsByNames(names)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ReplaceRolePermissions(role, permissions); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetRoleByID(id)
+}
+
+func (s *Service) rolesByNames(names []string) ([]models.Role, error) {
+	roles, err := s.repo.GetRolesByNames(names)
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) != len(unique(names)) {
+		return nil, ErrUnknownRole
+	}
+	return roles, nil
+}
+
+func (s *Service) permissionsByNames(names []string) ([]models.Permission, error) {
+	if len(names) == 0 {
+		return []models.Permission{}, nil
+	}
+
+	permissions, err := s.repo.GetPermissionsByNames(names)
+	if err != nil {
+		return nil, err
+	}
+	if len(permissions) != len(unique(names)) {
+		return nil, ErrUnknownPermission
+	}
+	return permissions, nil
+}
+
+func unique(values []string) []string {
+	seen := make(map[string]bool)
+	result := []string{}
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
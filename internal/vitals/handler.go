@@ -0,0 +1,288 @@
+package vitals
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+	"hospital-management/internal/audit"
+	"hospital-management/internal/models"
+	"hospital-management/internal/patient"
+	"hospital-management/internal/queue"
+	"hospital-management/internal/user"
+	"hospital-management/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// heartbeatInterval is how often an idle alert stream sends a ping so that
+// proxies keep the connection open.
+const heartbeatInterval = 30 * time.Second
+
+type Handler struct {
+	service *Service
+	audit   *audit.Service
+}
+
+func NewHandler(service *Service, auditService *audit.Service) *Handler {
+	return &Handler{service: service, audit: auditService}
+}
+
+// vitalsDiffIgnored lists fields left out of audit diffs because they are
+// loaded from other tables.
+var vitalsDiffIgnored = []string{"recorded_by"}
+
+func (h *Handler) RecordVitals(c *gin.Context) {
+	var req models.RecordVitalsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	vitals, previous, err := h.service.Record(req, utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to record vitals", err)
+		return
+	}
+
+	changes, _ := utils.DiffFields(nil, vitals, vitalsDiffIgnored...)
+	h.audit.Log(c, audit.Entry{
+		Action:       "vitals.create",
+		ResourceType: "vitals",
+		ResourceID:   vitals.ID,
+		PatientID:    &vitals.PatientID,
+		Changes:      changes,
+	})
+	if vitals.Escalated {
+		h.audit.Log(c, audit.Entry{
+			Action:       "vitals.escalate",
+			ResourceType: "vitals",
+			ResourceID:   vitals.ID,
+			PatientID:    &vitals.PatientID,
+			Details: gin.H{
+				"news2_score":        vitals.News2Score,
+				"news2_risk":         vitals.News2Risk,
+				"previous_score":     previous,
+				"assigned_doctor_id": vitals.AssignedDoctorID,
+			},
+		})
+	}
+
+	utils.SuccessResponse(c, "Vitals recorded successfully", gin.H{
+		"vitals":         vitals,
+		"previous_score": previous,
+	})
+}
+
+func (h *Handler) GetVitals(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid vitals ID", err)
+		return
+	}
+
+	vitals, err := h.service.GetVitals(uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Vitals not found", err)
+		return
+	}
+
+	h.audit.Log(c, audit.Entry{
+		Action:       "vitals.read",
+		ResourceType: "vitals",
+		ResourceID:   vitals.ID,
+		PatientID:    &vitals.PatientID,
+	})
+	utils.SuccessResponse(c, "Vitals retrieved successfully", vitals)
+}
+
+// GetPatientVitals returns the patient's vitals, newest first, optionally
+// limited to ?from= and ?to=.
+func (h *Handler) GetPatientVitals(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	var filter models.VitalsFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query", err)
+		return
+	}
+
+	vitals, err := h.service.List(uint(id), filter)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to get vitals", err)
+		return
+	}
+
+	ids := make([]uint, 0, len(vitals))
+	for _, v := range vitals {
+		ids = append(ids, v.ID)
+	}
+	patientID := uint(id)
+	h.audit.Log(c, audit.Entry{
+		Action:       "vitals.list",
+		ResourceType: "vitals",
+		PatientID:    &patientID,
+		Details:      gin.H{"vitals_ids": ids},
+	})
+	utils.SuccessResponse(c, "Vitals retrieved successfully", vitals)
+}
+
+// GetPatientSeries returns one measure of the patient's vitals over time for
+// charting.
+func (h *Handler) GetPatientSeries(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	var filter models.VitalSeriesFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query", err)
+		return
+	}
+
+	series, err := h.service.Series(uint(id), filter)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to get vitals series", err)
+		return
+	}
+
+	ids := make([]uint, 0, len(series.Points))
+	for _, p := range series.Points {
+		ids = append(ids, p.VitalSignsID)
+	}
+	patientID := uint(id)
+	h.audit.Log(c, audit.Entry{
+		Action:       "vitals.series",
+		ResourceType: "vitals",
+		PatientID:    &patientID,
+		Details:      gin.H{"measure": filter.Measure, "vitals_ids": ids},
+	})
+	utils.SuccessResponse(c, "Vitals series retrieved successfully", series)
+}
+
+// AcknowledgeVitals records that the doctor has responded to escalated
+// vitals.
+func (h *Handler) AcknowledgeVitals(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid vitals ID", err)
+		return
+	}
+
+	vitals, err := h.service.Acknowledge(uint(id), utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to acknowledge vitals", err)
+		return
+	}
+
+	h.audit.Log(c, audit.Entry{
+		Action:       "vitals.acknowledge",
+		ResourceType: "vitals",
+		ResourceID:   vitals.ID,
+		PatientID:    &vitals.PatientID,
+	})
+	utils.SuccessResponse(c, "Vitals acknowledged successfully", vitals)
+}
+
+// GetDoctorAlerts returns the authenticated doctor's unacknowledged
+// early-warning escalations.
+func (h *Handler) GetDoctorAlerts(c *gin.Context) {
+	vitals, err := h.service.Escalations(utils.CurrentUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to get vitals alerts", err)
+		return
+	}
+
+	h.logEscalations(c, "vitals.alerts.read", vitals)
+	utils.SuccessResponse(c, "Vitals alerts retrieved successfully", vitals)
+}
+
+// StreamDoctorAlerts sends the authenticated doctor's unacknowledged
+// escalations as an "escalations" event and then a "news2" event for each
+// new escalation, with a "ping" event when idle, until the client
+// disconnects.
+func (h *Handler) StreamDoctorAlerts(c *gin.Context) {
+	doctorID := utils.CurrentUserID(c)
+	alerts, unsubscribe := h.service.Subscribe(doctorID)
+	defer unsubscribe()
+
+	vitals, err := h.service.Escalations(doctorID)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to get vitals alerts", err)
+		return
+	}
+	h.logEscalations(c, "vitals.alerts.stream", vitals)
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.SSEvent("escalations", vitals)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-alerts:
+			if !ok {
+				return false
+			}
+			if alert, ok := event.Data.(models.VitalsAlert); ok {
+				h.audit.Log(c, audit.Entry{
+					Action:       "vitals.alerts.push",
+					ResourceType: "vitals",
+					ResourceID:   alert.Vitals.ID,
+					PatientID:    &alert.Vitals.PatientID,
+				})
+			}
+			c.SSEvent(event.Name, event.Data)
+			return true
+		case now := <-heartbeat.C:
+			c.SSEvent("ping", now.UTC())
+			return true
+		}
+	})
+}
+
+// logEscalations audits a view of escalated vitals with the patients it
+// disclosed.
+func (h *Handler) logEscalations(c *gin.Context, action string, vitals []models.VitalSigns) {
+	ids := make([]uint, 0, len(vitals))
+	patientIDs := make([]uint, 0, len(vitals))
+	for _, v := range vitals {
+		ids = append(ids, v.ID)
+		patientIDs = append(patientIDs, v.PatientID)
+	}
+	h.audit.Log(c, audit.Entry{
+		Action:       action,
+		ResourceType: "vitals",
+		Details:      gin.H{"vitals_ids": ids, "patient_ids": patientIDs},
+	})
+}
+
+// statusFor maps service errors to HTTP status codes.
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, user.ErrNotDoctor):
+		return http.StatusForbidden
+	case errors.Is(err, ErrNotEscalated), errors.Is(err, queue.ErrInvalidTransition):
+		return http.StatusConflict
+	case errors.Is(err, ErrNoMeasurements), errors.Is(err, ErrTemperatureRange), errors.Is(err, ErrWeightRange),
+		errors.Is(err, ErrHeightRange), errors.Is(err, ErrPressureOrder), errors.Is(err, ErrInFuture),
+		errors.Is(err, ErrUnitMismatch), errors.Is(err, ErrVisitMismatch), errors.Is(err, patient.ErrPatientMerged):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,130 @@
+package vitals
+
+import "hospital-management/internal/models"
+
+// news2 scores a set of observations with the National Early Warning Score 2,
+// using SpO2 scale 1. It returns ok false when a parameter is missing. A
+// single parameter scoring 3 raises an otherwise low score to low_medium.
+func news2(v *models.VitalSigns) (score int, risk string, ok bool) {
+	if v.RespiratoryRate == nil || v.SpO2 == nil || v.SystolicBP == nil || v.HeartRate == nil ||
+		v.TemperatureC == nil || v.Consciousness == "" {
+		return 0, "", false
+	}
+
+	parts := []int{
+		respirationScore(*v.RespiratoryRate),
+		saturationScore(*v.SpO2),
+		oxygenScore(v.SupplementalOxygen),
+		systolicScore(*v.SystolicBP),
+		pulseScore(*v.HeartRate),
+		consciousnessScore(v.Consciousness),
+		temperatureScore(*v.TemperatureC),
+	}
+
+	redScore := false
+	for _, p := range parts {
+		score += p
+		if p == 3 {
+			redScore = true
+		}
+	}
+
+	switch {
+	case score >= 7:
+		risk = models.News2RiskHigh
+	case score >= 5:
+		risk = models.News2RiskMedium
+	case redScore:
+		risk = models.News2RiskLowMedium
+	default:
+		risk = models.News2RiskLow
+	}
+	return score, risk, true
+}
+
+func respirationScore(rate int) int {
+	switch {
+	case rate <= 8:
+		return 3
+	case rate <= 11:
+		return 1
+	case rate <= 20:
+		return 0
+	case rate <= 24:
+		return 2
+	}
+	return 3
+}
+
+func saturationScore(spo2 int) int {
+	switch {
+	case spo2 <= 91:
+		return 3
+	case spo2 <= 93:
+		return 2
+	case spo2 <= 95:
+		return 1
+	}
+	return 0
+}
+
+func oxygenScore(supplemental bool) int {
+	if supplemental {
+		return 2
+	}
+	return 0
+}
+
+func systolicScore(pressure int) int {
+	switch {
+	case pressure <= 90:
+		return 3
+	case pressure <= 100:
+		return 2
+	case pressure <= 110:
+		return 1
+	case pressure <= 219:
+		return 0
+	}
+	return 3
+}
+
+func pulseScore(rate int) int {
+	switch {
+	case rate <= 40:
+		return 3
+	case rate <= 50:
+		return 1
+	case rate <= 90:
+		return 0
+	case rate <= 110:
+		return 1
+	case rate <= 130:
+		return 2
+	}
+	return 3
+}
+
+func consciousnessScore(level string) int {
+	if level == models.ConsciousnessAlert {
+		return 0
+	}
+	return 3
+}
+
+// temperatureScore scores a temperature in degrees Celsius, rounded to one
+// decimal place as charted.
+func temperatureScore(celsius float64) int {
+	t := roundTo(celsius, 1)
+	switch {
+	case t <= 35.0:
+		return 3
+	case t <= 36.0:
+		return 1
+	case t <= 38.0:
+		return 0
+	case t <= 39.0:
+		return 1
+	}
+	return 2
+}
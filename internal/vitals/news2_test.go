@@ -0,0 +1,164 @@
+package vitals
+
+import (
+	"testing"
+	"hospital-management/internal/models"
+)
+
+func TestParameterScores(t *testing.T) {
+	tests := []struct {
+		name  string
+		score func(int) int
+		bands []struct{ value, want int }
+	}{
+		{"respiration", respirationScore, []struct{ value, want int }{
+			{8, 3}, {9, 1}, {11, 1}, {12, 0}, {20, 0}, {21, 2}, {24, 2}, {25, 3},
+		}},
+		{"saturation", saturationScore, []struct{ value, want int }{
+			{91, 3}, {92, 2}, {93, 2}, {94, 1}, {95, 1}, {96, 0}, {100, 0},
+		}},
+		{"systolic", systolicScore, []struct{ value, want int }{
+			{90, 3}, {91, 2}, {100, 2}, {101, 1}, {110, 1}, {111, 0}, {219, 0}, {220, 3},
+		}},
+		{"pulse", pulseScore, []struct{ value, want int }{
+			{40, 3}, {41, 1}, {50, 1}, {51, 0}, {90, 0}, {91, 1}, {110, 1}, {111, 2}, {130, 2}, {131, 3},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, band := range tt.bands {
+				if got := tt.score(band.value); got != band.want {
+					t.Errorf("score(%d) = %d, want %d", band.value, got, band.want)
+				}
+			}
+		})
+	}
+}
+
+func TestTemperatureScore(t *testing.T) {
+	tests := []struct {
+		celsius float64
+		want    int
+	}{
+		{35.0, 3}, {35.1, 1}, {36.0, 1}, {36.04, 1}, {36.06, 0}, {36.1, 0},
+		{38.0, 0}, {toCelsius(100.4, models.UnitFahrenheit), 0}, {38.1, 1}, {39.0, 1}, {39.1, 2},
+	}
+	for _, tt := range tests {
+		if got := temperatureScore(tt.celsius); got != tt.want {
+			t.Errorf("temperatureScore(%v) = %d, want %d", tt.celsius, got, tt.want)
+		}
+	}
+}
+
+func TestOxygenAndConsciousnessScores(t *testing.T) {
+	if got := oxygenScore(false); got != 0 {
+		t.Errorf("oxygenScore(false) = %d, want 0", got)
+	}
+	if got := oxygenScore(true); got != 2 {
+		t.Errorf("oxygenScore(true) = %d, want 2", got)
+	}
+	levels := map[string]int{
+		models.ConsciousnessAlert:        0,
+		models.ConsciousnessConfusion:    3,
+		models.ConsciousnessVoice:        3,
+		models.ConsciousnessPain:         3,
+		models.ConsciousnessUnresponsive: 3,
+	}
+	for level, want := range levels {
+		if got := consciousnessScore(level); got != want {
+			t.Errorf("consciousnessScore(%q) = %d, want %d", level, got, want)
+		}
+	}
+}
+
+// observations returns a complete set of vital signs scoring 0, changed by
+// edit.
+func observations(edit func(v *models.VitalSigns)) *models.VitalSigns {
+	rate, spo2, systolic, pulse, temperature := 16, 97, 120, 70, 37.0
+	v := &models.VitalSigns{
+		RespiratoryRate: &rate,
+		SpO2:            &spo2,
+		SystolicBP:      &systolic,
+		HeartRate:       &pulse,
+		TemperatureC:    &temperature,
+		Consciousness:   models.ConsciousnessAlert,
+	}
+	if edit != nil {
+		edit(v)
+	}
+	return v
+}
+
+func TestNews2(t *testing.T) {
+	tests := []struct {
+		name      string
+		edit      func(v *models.VitalSigns)
+		wantScore int
+		wantRisk  string
+	}{
+		{"all normal", nil, 0, models.News2RiskLow},
+		{"aggregate 4", func(v *models.VitalSigns) {
+			*v.HeartRate = 111
+			v.SupplementalOxygen = true
+		}, 4, models.News2RiskLow},
+		{"single red score", func(v *models.VitalSigns) {
+			v.Consciousness = models.ConsciousnessVoice
+		}, 3, models.News2RiskLowMedium},
+		{"red score with aggregate 4", func(v *models.VitalSigns) {
+			*v.SpO2 = 91
+			*v.TemperatureC = 38.5
+		}, 4, models.News2RiskLowMedium},
+		{"aggregate 5", func(v *models.VitalSigns) {
+			*v.RespiratoryRate = 21
+			*v.SystolicBP = 100
+			*v.TemperatureC = 36.0
+		}, 5, models.News2RiskMedium},
+		{"red score with aggregate 5", func(v *models.VitalSigns) {
+			*v.SystolicBP = 90
+			*v.HeartRate = 111
+		}, 5, models.News2RiskMedium},
+		{"aggregate 6", func(v *models.VitalSigns) {
+			*v.RespiratoryRate = 25
+			*v.HeartRate = 91
+			*v.SystolicBP = 100
+		}, 6, models.News2RiskMedium},
+		{"aggregate 7", func(v *models.VitalSigns) {
+			*v.RespiratoryRate = 25
+			*v.SpO2 = 92
+			*v.SystolicBP = 110
+			*v.TemperatureC = 39.0
+		}, 7, models.News2RiskHigh},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, risk, ok := news2(observations(tt.edit))
+			if !ok {
+				t.Fatal("news2 not calculated")
+			}
+			if score != tt.wantScore || risk != tt.wantRisk {
+				t.Errorf("news2 = %d %q, want %d %q", score, risk, tt.wantScore, tt.wantRisk)
+			}
+		})
+	}
+}
+
+func TestNews2Incomplete(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(v *models.VitalSigns)
+	}{
+		{"no respiratory rate", func(v *models.VitalSigns) { v.RespiratoryRate = nil }},
+		{"no saturation", func(v *models.VitalSigns) { v.SpO2 = nil }},
+		{"no systolic pressure", func(v *models.VitalSigns) { v.SystolicBP = nil }},
+		{"no pulse", func(v *models.VitalSigns) { v.HeartRate = nil }},
+		{"no temperature", func(v *models.VitalSigns) { v.TemperatureC = nil }},
+		{"no consciousness level", func(v *models.VitalSigns) { v.Consciousness = "" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, ok := news2(observations(tt.edit)); ok {
+				t.Error("news2 calculated from incomplete observations")
+			}
+		})
+	}
+}
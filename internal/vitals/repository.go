@@ -0,0 +1,135 @@
+package vitals
+
+import (
+	"fmt"
+	"time"
+	"hospital-management/internal/models"
+	"hospital-management/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// measureColumns maps the measures that can be charted to their columns.
+var measureColumns = map[string]string{
+	"systolic_bp":      "systolic_bp",
+	"diastolic_bp":     "diastolic_bp",
+	"heart_rate":       "heart_rate",
+	"respiratory_rate": "respiratory_rate",
+	"temperature":      "temperature_c",
+	"spo2":             "spo2",
+	"weight":           "weight_kg",
+	"height":           "height_cm",
+	"bmi":              "bmi",
+	"news2_score":      "news2_score",
+}
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Create(vitals *models.VitalSigns) error {
+	return r.db.Omit("RecordedBy").Create(vitals).Error
+}
+
+func (r *Repository) GetByID(id uint) (*models.VitalSigns, error) {
+	var vitals models.VitalSigns
+	err := r.db.Preload("RecordedBy", utils.WithDeleted).First(&vitals, id).Error
+	return &vitals, err
+}
+
+// List returns the patient's vitals recorded within the filter's period,
+// newest first.
+func (r *Repository) List(patientID uint, filter models.VitalsFilter) ([]models.VitalSigns, error) {
+	query := r.db.Preload("RecordedBy", utils.WithDeleted).Where("patient_id = ?", patientID)
+	query = withinPeriod(query, filter.From, filter.To)
+
+	var vitals []models.VitalSigns
+	err := query.Order("recorded_at DESC, id DESC").Find(&vitals).Error
+	return vitals, err
+}
+
+// Series returns the recorded values of one measure for the patient, oldest
+// first.
+func (r *Repository) Series(patientID uint, measure string, from, to time.Time) ([]models.VitalPoint, error) {
+	column, ok := measureColumns[measure]
+	if !ok {
+		return nil, fmt.Errorf("unknown measure %q", measure)
+	}
+
+	query := r.db.Model(&models.VitalSigns{}).
+		Select(fmt.Sprintf("id AS vital_signs_id, recorded_at, %s AS value", column)).
+		Where(fmt.Sprintf("patient_id = ? AND %s IS NOT NULL", column), patientID)
+	query = withinPeriod(query, from, to)
+
+	var points []models.VitalPoint
+	err := query.Order("recorded_at, id").Scan(&points).Error
+	return points, err
+}
+
+// LatestHeight returns the patient's most recently recorded height in
+// centimetres, or nil if none was recorded.
+func (r *Repository) LatestHeight(patientID uint) (*float64, error) {
+	var vitals []models.VitalSigns
+	if err := r.db.Where("patient_id = ? AND height_cm IS NOT NULL", patientID).
+		Order("recorded_at DESC, id DESC").
+		Limit(1).
+		Find(&vitals).Error; err != nil {
+		return nil, err
+	}
+	if len(vitals) == 0 {
+		return nil, nil
+	}
+	return vitals[0].HeightCm, nil
+}
+
+// PreviousScore returns the NEWS2 score of the patient's last scored vitals
+// recorded before the given time, or nil if there are none.
+func (r *Repository) PreviousScore(patientID uint, before time.Time) (*int, error) {
+	var vitals []models.VitalSigns
+	if err := r.db.Where("patient_id = ? AND news2_score IS NOT NULL AND recorded_at < ?", patientID, before).
+		Order("recorded_at DESC, id DESC").
+		Limit(1).
+		Find(&vitals).Error; err != nil {
+		return nil, err
+	}
+	if len(vitals) == 0 {
+		return nil, nil
+	}
+	return vitals[0].News2Score, nil
+}
+
+// ListEscalated returns the escalated vitals assigned to the doctor that
+// have not been acknowledged, highest score first.
+func (r *Repository) ListEscalated(doctorID uint) ([]models.VitalSigns, error) {
+	var vitals []models.VitalSigns
+	err := r.db.Preload("RecordedBy", utils.WithDeleted).
+		Where("assigned_doctor_id = ? AND escalated AND acknowledged_at IS NULL", doctorID).
+		Order("news2_score DESC, recorded_at").
+		Find(&vitals).Error
+	return vitals, err
+}
+
+// Acknowledge marks escalated vitals as seen by doctorID. It reports false
+// when the vitals were not escalated or were already acknowledged.
+func (r *Repository) Acknowledge(id, doctorID uint, at time.Time) (bool, error) {
+	result := r.db.Model(&models.VitalSigns{}).
+		Where("id = ? AND escalated AND acknowledged_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"acknowledged_by_id": doctorID,
+			"acknowledged_at":    at,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func withinPeriod(query *gorm.DB, from, to time.Time) *gorm.DB {
+	if !from.IsZero() {
+		query = query.Where("recorded_at >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("recorded_at < ?", to)
+	}
+	return query
+}
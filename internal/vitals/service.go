@@ -0,0 +1,306 @@
+package vitals
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"hospital-management/internal/admission"
+	"hospital-management/internal/models"
+	"hospital-management/internal/patient"
+	"hospital-management/internal/queue"
+	"hospital-management/internal/user"
+	"hospital-management/pkg/events"
+	"gorm.io/gorm"
+)
+
+// EventName names early-warning alert events.
+const EventName = "news2"
+
+var (
+	ErrNoMeasurements   = errors.New("record at least one measurement")
+	ErrTemperatureRange = errors.New("temperature must be between 25 and 45 °C (77 and 113 °F)")
+	ErrWeightRange      = errors.New("weight must be between 0.2 and 500 kg")
+	ErrHeightRange      = errors.New("height must be between 20 and 280 cm")
+	ErrPressureOrder    = errors.New("diastolic_bp must be lower than systolic_bp")
+	ErrInFuture         = errors.New("recorded_at cannot be in the future")
+	ErrUnitMismatch     = errors.New("unit does not apply to this measure")
+	ErrVisitMismatch    = errors.New("visit belongs to another patient")
+	ErrNotEscalated     = errors.New("vitals were not escalated or are already acknowledged")
+)
+
+type Service struct {
+	repo             *Repository
+	patientService   *patient.Service
+	userService      *user.Service
+	queueService     *queue.Service
+	admissionService *admission.Service
+	broker           *events.Broker
+}
+
+func NewService(repo *Repository, patientService *patient.Service, userService *user.Service, queueService *queue.Service, admissionService *admission.Service, broker *events.Broker) *Service {
+	return &Service{
+		repo:             repo,
+		patientService:   patientService,
+		userService:      userService,
+		queueService:     queueService,
+		admissionService: admissionService,
+		broker:           broker,
+	}
+}
+
+// Topic returns the broker topic carrying early-warning alerts for a doctor.
+func Topic(doctorID uint) string {
+	return fmt.Sprintf("vitals:%d", doctorID)
+}
+
+// Record stores a set of observations taken by recordedBy. Measurements are
+// converted to metric units, the BMI and NEWS2 score are calculated, and the
+// vitals are assigned to the attending doctor of the patient's admission or,
+// for outpatients, to the doctor of their visit. Recording vitals
+// for a patient who has just arrived triages their visit. A NEWS2 risk
+// above low escalates the vitals to the assigned doctor.
+func (s *Service) Record(req models.RecordVitalsRequest, recordedBy uint) (*models.VitalSigns, *int, error) {
+	p, err := s.patientService.GetActivePatient(req.PatientID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	vitals := &models.VitalSigns{
+		PatientID:          req.PatientID,
+		RecordedByID:       recordedBy,
+		RecordedAt:         time.Now().UTC(),
+		SystolicBP:         req.SystolicBP,
+		DiastolicBP:        req.DiastolicBP,
+		HeartRate:          req.HeartRate,
+		RespiratoryRate:    req.RespiratoryRate,
+		SpO2:               req.SpO2,
+		SupplementalOxygen: req.SupplementalOxygen,
+		Consciousness:      req.Consciousness,
+		Note:               req.Note,
+	}
+	if req.RecordedAt != nil {
+		if req.RecordedAt.After(time.Now()) {
+			return nil, nil, ErrInFuture
+		}
+		vitals.RecordedAt = req.RecordedAt.UTC()
+	}
+	if err := convert(vitals, req); err != nil {
+		return nil, nil, err
+	}
+
+	if vitals.WeightKg != nil {
+		height := vitals.HeightCm
+		if height == nil {
+			if height, err = s.repo.LatestHeight(req.PatientID); err != nil {
+				return nil, nil, err
+			}
+		}
+		if height != nil {
+			value := bmi(*vitals.WeightKg, *height)
+			vitals.BMI = &value
+		}
+	}
+
+	if score, risk, ok := news2(vitals); ok {
+		vitals.News2Score = &score
+		vitals.News2Risk = risk
+		vitals.Escalated = risk != models.News2RiskLow
+	}
+
+	visit, err := s.visitFor(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	if visit != nil {
+		vitals.VisitID = &visit.ID
+		vitals.AssignedDoctorID = &visit.DoctorID
+	}
+	stay, err := s.admissionService.ActiveAdmission(req.PatientID)
+	switch {
+	case err == nil:
+		vitals.AdmissionID = &stay.ID
+		vitals.AssignedDoctorID = &stay.AttendingDoctorID
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, nil, err
+	}
+
+	previous, err := s.repo.PreviousScore(req.PatientID, vitals.RecordedAt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.repo.Create(vitals); err != nil {
+		return nil, nil, err
+	}
+
+	if visit != nil && visit.Status == models.VisitArrived {
+		// The visit may have moved on since it was read, for example into
+		// consultation; it is then past triage and is left alone.
+		_, err := s.queueService.SetStatus(visit.ID, models.UpdateVisitStatusRequest{Status: models.VisitTriaged})
+		if err != nil && !errors.Is(err, queue.ErrVisitChanged) && !errors.Is(err, queue.ErrInvalidTransition) {
+			return nil, nil, err
+		}
+	}
+
+	vitals, err = s.repo.GetByID(vitals.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if vitals.Escalated && vitals.AssignedDoctorID != nil {
+		s.broker.Publish(Topic(*vitals.AssignedDoctorID), events.Event{
+			Name: EventName,
+			Data: models.VitalsAlert{Vitals: *vitals, Patient: *p, PreviousScore: previous},
+		})
+	}
+	return vitals, previous, nil
+}
+
+func (s *Service) GetVitals(id uint) (*models.VitalSigns, error) {
+	return s.repo.GetByID(id)
+}
+
+// List returns the patient's vitals, newest first.
+func (s *Service) List(patientID uint, filter models.VitalsFilter) ([]models.VitalSigns, error) {
+	if _, err := s.patientService.GetPatientByID(patientID); err != nil {
+		return nil, err
+	}
+	return s.repo.List(patientID, filter)
+}
+
+// Series returns the patient's values of one measure over time, converted to
+// the requested unit.
+func (s *Service) Series(patientID uint, filter models.VitalSeriesFilter) (*models.VitalSeries, error) {
+	if _, err := s.patientService.GetPatientByID(patientID); err != nil {
+		return nil, err
+	}
+	if _, ok := fromStored(filter.Measure, 0, filter.Unit); !ok {
+		return nil, ErrUnitMismatch
+	}
+	unit := filter.Unit
+	if unit == "" {
+		unit = defaultUnits[filter.Measure]
+	}
+
+	points, err := s.repo.Series(patientID, filter.Measure, filter.From, filter.To)
+	if err != nil {
+		return nil, err
+	}
+
+	series := &models.VitalSeries{PatientID: patientID, Measure: filter.Measure, Unit: unit, Points: points}
+	for i := range series.Points {
+		value, _ := fromStored(filter.Measure, series.Points[i].Value, filter.Unit)
+		value = roundTo(value, 1)
+		series.Points[i].Value = value
+
+		if series.Min == nil || value < *series.Min {
+			series.Min = &series.Points[i].Value
+		}
+		if series.Max == nil || value > *series.Max {
+			series.Max = &series.Points[i].Value
+		}
+		series.Latest = &series.Points[i].Value
+	}
+	return series, nil
+}
+
+// Escalations returns the escalated vitals assigned to the doctor that are
+// waiting for acknowledgement.
+func (s *Service) Escalations(doctorID uint) ([]models.VitalSigns, error) {
+	return s.repo.ListEscalated(doctorID)
+}
+
+// Acknowledge records that doctorID, who must be an active doctor, has
+// responded to escalated vitals.
+func (s *Service) Acknowledge(id, doctorID uint) (*models.VitalSigns, error) {
+	if _, err := s.userService.GetActiveDoctor(doctorID); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetByID(id); err != nil {
+		return nil, err
+	}
+
+	ok, err := s.repo.Acknowledge(id, doctorID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNotEscalated
+	}
+	return s.repo.GetByID(id)
+}
+
+// Subscribe returns a channel of early-warning alerts for the doctor and a
+// function ending the subscription.
+func (s *Service) Subscribe(doctorID uint) (<-chan events.Event, func()) {
+	return s.broker.Subscribe(Topic(doctorID))
+}
+
+// visitFor returns the visit the vitals belong to: the one given in the
+// request, or else the patient's active visit, if any.
+func (s *Service) visitFor(req models.RecordVitalsRequest) (*models.Visit, error) {
+	if req.VisitID != 0 {
+		visit, err := s.queueService.GetVisit(req.VisitID)
+		if err != nil {
+			return nil, err
+		}
+		if visit.PatientID != req.PatientID {
+			return nil, ErrVisitMismatch
+		}
+		return visit, nil
+	}
+
+	visit, err := s.queueService.ActiveVisit(req.PatientID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return visit, err
+}
+
+// defaultUnits gives the units measures are stored and charted in.
+var defaultUnits = map[string]string{
+	"systolic_bp":      "mmHg",
+	"diastolic_bp":     "mmHg",
+	"heart_rate":       "/min",
+	"respiratory_rate": "/min",
+	"temperature":      models.UnitCelsius,
+	"spo2":             "%",
+	"weight":           models.UnitKilogram,
+	"height":           models.UnitCentimetre,
+	"bmi":              "kg/m²",
+	"news2_score":      "",
+}
+
+// convert copies the request's temperature, weight and height onto vitals in
+// metric units and checks that they are plausible.
+func convert(vitals *models.VitalSigns, req models.RecordVitalsRequest) error {
+	if req.SystolicBP == nil && req.DiastolicBP == nil && req.HeartRate == nil && req.RespiratoryRate == nil &&
+		req.Temperature == nil && req.SpO2 == nil && req.Consciousness == "" && req.Weight == nil && req.Height == nil {
+		return ErrNoMeasurements
+	}
+	if req.SystolicBP != nil && req.DiastolicBP != nil && *req.DiastolicBP >= *req.SystolicBP {
+		return ErrPressureOrder
+	}
+
+	if req.Temperature != nil {
+		celsius := roundTo(toCelsius(*req.Temperature, req.TemperatureUnit), 1)
+		if celsius < 25 || celsius > 45 {
+			return ErrTemperatureRange
+		}
+		vitals.TemperatureC = &celsius
+	}
+	if req.Weight != nil {
+		kg := roundTo(toKilograms(*req.Weight, req.WeightUnit), 2)
+		if kg < 0.2 || kg > 500 {
+			return ErrWeightRange
+		}
+		vitals.WeightKg = &kg
+	}
+	if req.Height != nil {
+		cm := roundTo(toCentimetres(*req.Height, req.HeightUnit), 1)
+		if cm < 20 || cm > 280 {
+			return ErrHeightRange
+		}
+		vitals.HeightCm = &cm
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package vitals
+
+import (
+	"math"
+	"hospital-management/internal/models"
+)
+
+const (
+	poundsPerKilogram  = 2.20462262
+	centimetresPerInch = 2.54
+)
+
+// toCelsius converts a temperature given in unit to degrees Celsius.
+func toCelsius(value float64, unit string) float64 {
+	if unit == models.UnitFahrenheit {
+		return (value - 32) * 5 / 9
+	}
+	return value
+}
+
+// toKilograms converts a weight given in unit to kilograms.
+func toKilograms(value float64, unit string) float64 {
+	if unit == models.UnitPound {
+		return value / poundsPerKilogram
+	}
+	return value
+}
+
+// toCentimetres converts a height given in unit to centimetres.
+func toCentimetres(value float64, unit string) float64 {
+	if unit == models.UnitInch {
+		return value * centimetresPerInch
+	}
+	return value
+}
+
+// fromStored converts a stored value of measure to unit for display. It
+// reports false when unit does not apply to the measure.
+func fromStored(measure string, value float64, unit string) (float64, bool) {
+	switch {
+	case unit == "":
+		return value, true
+	case measure == "temperature" && unit == models.UnitCelsius,
+		measure == "weight" && unit == models.UnitKilogram,
+		measure == "height" && unit == models.UnitCentimetre:
+		return value, true
+	case measure == "temperature" && unit == models.UnitFahrenheit:
+		return value*9/5 + 32, true
+	case measure == "weight" && unit == models.UnitPound:
+		return value * poundsPerKilogram, true
+	case measure == "height" && unit == models.UnitInch:
+		return value / centimetresPerInch, true
+	}
+	return 0, false
+}
+
+// bmi returns the body mass index for a weight in kilograms and a height in
+// centimetres, rounded to one decimal place.
+func bmi(weightKg, heightCm float64) float64 {
+	metres := heightCm / 100
+	return roundTo(weightKg/(metres*metres), 1)
+}
+
+func roundTo(value float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(value*scale) / scale
+}
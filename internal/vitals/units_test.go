@@ -0,0 +1,80 @@
+package vitals
+
+import (
+	"math"
+	"testing"
+	"hospital-management/internal/models"
+)
+
+func TestUnitRoundTrips(t *testing.T) {
+	tests := []struct {
+		name    string
+		measure string
+		unit    string
+		value   float64
+		toStore func(float64, string) float64
+		stored  float64
+	}{
+		{"fahrenheit", "temperature", models.UnitFahrenheit, 98.6, toCelsius, 37.0},
+		{"fahrenheit below freezing", "temperature", models.UnitFahrenheit, -40, toCelsius, -40},
+		{"celsius", "temperature", models.UnitCelsius, 36.6, toCelsius, 36.6},
+		{"pounds", "weight", models.UnitPound, 154.32358, toKilograms, 70.0},
+		{"kilograms", "weight", models.UnitKilogram, 70.0, toKilograms, 70.0},
+		{"inches", "height", models.UnitInch, 70, toCentimetres, 177.8},
+		{"centimetres", "height", models.UnitCentimetre, 177.8, toCentimetres, 177.8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := tt.toStore(tt.value, tt.unit)
+			if math.Abs(stored-tt.stored) > 1e-4 {
+				t.Errorf("stored %v %s as %v, want %v", tt.value, tt.unit, stored, tt.stored)
+			}
+			back, ok := fromStored(tt.measure, stored, tt.unit)
+			if !ok {
+				t.Fatalf("fromStored(%s, %s) not accepted", tt.measure, tt.unit)
+			}
+			if math.Abs(back-tt.value) > 1e-9 {
+				t.Errorf("round trip of %v %s = %v", tt.value, tt.unit, back)
+			}
+		})
+	}
+}
+
+func TestFromStoredUnits(t *testing.T) {
+	tests := []struct {
+		measure string
+		unit    string
+		ok      bool
+	}{
+		{"temperature", "", true},
+		{"weight", "", true},
+		{"temperature", models.UnitKilogram, false},
+		{"weight", models.UnitInch, false},
+		{"height", models.UnitFahrenheit, false},
+		{"height", "ft", false},
+	}
+	for _, tt := range tests {
+		got, ok := fromStored(tt.measure, 42, tt.unit)
+		if ok != tt.ok {
+			t.Errorf("fromStored(%s, %q) ok = %v, want %v", tt.measure, tt.unit, ok, tt.ok)
+		}
+		if ok && tt.unit == "" && got != 42 {
+			t.Errorf("fromStored(%s, \"\") = %v, want the stored value", tt.measure, got)
+		}
+	}
+}
+
+func TestBMI(t *testing.T) {
+	tests := []struct {
+		weightKg, heightCm, want float64
+	}{
+		{70, 175, 22.9},
+		{toKilograms(154.32358, models.UnitPound), toCentimetres(70, models.UnitInch), 22.1},
+		{50, 160, 19.5},
+	}
+	for _, tt := range tests {
+		if got := bmi(tt.weightKg, tt.heightCm); got != tt.want {
+			t.Errorf("bmi(%v, %v) = %v, want %v", tt.weightKg, tt.heightCm, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// Package events fans out in-process events to subscribers, for example to
+// Server-Sent Events streams.
+package events
+
+import "sync"
+
+// subscriberBuffer is how many events a subscriber may fall behind before
+// further events to it are dropped.
+const subscriberBuffer = 16
+
+// Event is a named message. Data is encoded as JSON when sent to clients.
+type Event struct {
+	Name string
+	Data interface{}
+}
+
+// Broker delivers published events to every subscriber of a topic. Delivery
+// never blocks the publisher: a subscriber whose buffer is full misses the
+// event, so events should carry complete state rather than deltas.
+type Broker struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[chan Event]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{subscribers: make(map[string]map[chan Event]struct{})}
+}
+
+// Subscribe returns a channel receiving the topic's events and a function
+// that ends the subscription and closes the channel.
+func (b *Broker) Subscribe(topic string) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	b.mu.Lock()
+	if b.subscribers[topic] == nil {
+		b.subscribers[topic] = make(map[chan Event]struct{})
+	}
+	b.subscribers[topic][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers[topic], ch)
+			if len(b.subscribers[topic]) == 0 {
+				delete(b.subscribers, topic)
+			}
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Publish sends e to the current subscribers of topic.
+func (b *Broker) Publish(topic string, e Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.subscribers[topic] {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Package matching provides the string comparison primitives used to find and
+// de-duplicate patients: name normalisation, phonetic codes, trigram
+// similarity and phone number normalisation. It has no database dependency.
+package matching
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeName lowercases s, strips diacritics and drops everything except
+// letters and single spaces.
+func NormalizeName(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range norm.NFD.String(s) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Combining mark left over from decomposing an accented letter.
+		case unicode.IsLetter(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// Tokens splits a name into normalised words.
+func Tokens(s string) []string {
+	return strings.Fields(NormalizeName(s))
+}
+
+// NormalizePhone keeps only the digits of a phone number.
+func NormalizePhone(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// phoneSignificantDigits is the number of trailing digits compared, so that
+// numbers written with and without a country or trunk prefix still match.
+const phoneSignificantDigits = 10
+
+// PhoneMatches reports whether two phone numbers refer to the same line.
+func PhoneMatches(a, b string) bool {
+	a, b = NormalizePhone(a), NormalizePhone(b)
+	if len(a) < 7 || len(b) < 7 {
+		return false
+	}
+	return lastDigits(a) == lastDigits(b)
+}
+
+// PhoneSuffix returns the significant trailing digits of a phone number, for
+// use as a database prefilter.
+func PhoneSuffix(s string) string {
+	return lastDigits(NormalizePhone(s))
+}
+
+func lastDigits(s string) string {
+	if len(s) > phoneSignificantDigits {
+		return s[len(s)-phoneSignificantDigits:]
+	}
+	return s
+}
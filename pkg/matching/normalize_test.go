@@ -0,0 +1,60 @@
+package matching
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Smith", "smith"},
+		{"  ÉMILE ", "emile"},
+		{"Núñez-García", "nunez garcia"},
+		{"O'Brien", "o brien"},
+		{"Zoë  Ångström", "zoe angstrom"},
+		{"Łukasz", "łukasz"},
+		{"123", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeName(tt.in); got != tt.want {
+			t.Errorf("NormalizeName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTokens(t *testing.T) {
+	got := Tokens("Jean-Émile  de la Croix")
+	want := []string{"jean", "emile", "de", "la", "croix"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Tokens = %q, want %q", got, want)
+	}
+}
+
+func TestPhoneMatches(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"(555) 123-4567", "555.123.4567", true},
+		{"+1 555 123 4567", "5551234567", true},
+		{"0044 20 7946 0958", "020 7946 0958", true},
+		{"+44 20 7946 0958", "2079460958", true},
+		{"555-1234", "555-1235", false},
+		{"12345", "12345", false},
+		{"", "5551234567", false},
+	}
+	for _, tt := range tests {
+		if got := PhoneMatches(tt.a, tt.b); got != tt.want {
+			t.Errorf("PhoneMatches(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestPhoneSuffix(t *testing.T) {
+	if got := PhoneSuffix("+1 (555) 123-4567"); got != "5551234567" {
+		t.Errorf("PhoneSuffix = %q, want %q", got, "5551234567")
+	}
+}
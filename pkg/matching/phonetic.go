@@ -0,0 +1,46 @@
+package matching
+
+// soundexCodes maps letters to their American Soundex digit. Vowels and
+// h, w, y map to 0 and are not coded.
+var soundexCodes = [26]byte{
+	'0', '1', '2', '3', '0', '1', '2', '0', '0', '2', '2', '4', '5',
+	'5', '0', '1', '2', '6', '2', '3', '0', '1', '0', '2', '0', '2',
+}
+
+// Soundex returns the four character American Soundex code of a single
+// word, or "" when it contains no letters a-z after normalisation.
+func Soundex(word string) string {
+	word = NormalizeName(word)
+
+	code := make([]byte, 0, 4)
+	var last byte
+	for i := 0; i < len(word) && len(code) < 4; i++ {
+		c := word[i]
+		if c < 'a' || c > 'z' {
+			continue
+		}
+		digit := soundexCodes[c-'a']
+
+		if len(code) == 0 {
+			code = append(code, c-'a'+'A')
+			last = digit
+			continue
+		}
+
+		if digit != '0' && digit != last {
+			code = append(code, digit)
+		}
+		// h and w do not separate letters with the same code; vowels do.
+		if c != 'h' && c != 'w' {
+			last = digit
+		}
+	}
+
+	if len(code) == 0 {
+		return ""
+	}
+	for len(code) < 4 {
+		code = append(code, '0')
+	}
+	return string(code)
+}
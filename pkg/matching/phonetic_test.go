@@ -0,0 +1,25 @@
+package matching
+
+import "testing"
+
+func TestSoundex(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Robert", "R163"},
+		{"Rupert", "R163"},
+		{"Rubin", "R150"},
+		{"Ashcraft", "A261"},
+		{"Tymczak", "T522"},
+		{"Pfister", "P236"},
+		{"Lee", "L000"},
+		{"Müller", "M460"},
+		{"", ""},
+		{"42", ""},
+	}
+	for _, tt := range tests {
+		if got := Soundex(tt.in); got != tt.want {
+			t.Errorf("Soundex(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
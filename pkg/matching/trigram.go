@@ -0,0 +1,56 @@
+package matching
+
+import "strings"
+
+// Trigrams returns the set of trigrams of s following pg_trgm: every word is
+// normalised and padded with two leading spaces and one trailing space.
+func Trigrams(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range Tokens(s) {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+// Similarity returns the trigram similarity of a and b in [0, 1]: the number
+// of shared trigrams divided by the number of distinct trigrams.
+func Similarity(a, b string) float64 {
+	ta, tb := Trigrams(a), Trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for t := range ta {
+		if tb[t] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
+
+// NameScore rates how well a query word matches a name word in [0, 1]:
+// exact and prefix matches score highest, then equal Soundex codes, then
+// trigram similarity.
+func NameScore(query, name string) float64 {
+	query, name = NormalizeName(query), NormalizeName(name)
+	if query == "" || name == "" {
+		return 0
+	}
+
+	switch {
+	case query == name:
+		return 1
+	case len(query) >= 2 && strings.HasPrefix(name, query):
+		return 0.9
+	}
+
+	score := Similarity(query, name) * 0.8
+	if Soundex(query) == Soundex(name) && score < 0.7 {
+		score = 0.7
+	}
+	return score
+}
@@ -0,0 +1,41 @@
+package matching
+
+import "testing"
+
+func TestSimilarity(t *testing.T) {
+	if got := Similarity("Smith", "smith"); got != 1 {
+		t.Errorf("Similarity of equal names = %v, want 1", got)
+	}
+	if got := Similarity("abc", "xyz"); got != 0 {
+		t.Errorf("Similarity of unrelated names = %v, want 0", got)
+	}
+	if got := Similarity("", "smith"); got != 0 {
+		t.Errorf("Similarity with an empty name = %v, want 0", got)
+	}
+
+	close, far := Similarity("Jonathan", "Jonathon"), Similarity("Jonathan", "Johnson")
+	if close <= far {
+		t.Errorf("Similarity(Jonathan, Jonathon) = %v, want more than Similarity(Jonathan, Johnson) = %v", close, far)
+	}
+}
+
+func TestNameScore(t *testing.T) {
+	tests := []struct {
+		query, name string
+		min, max    float64
+	}{
+		{"smith", "Smith", 1, 1},
+		{"emile", "Émile", 1, 1},
+		{"jo", "Johnson", 0.9, 0.9},
+		{"smyth", "Smith", 0.7, 0.8},
+		{"catherine", "Katherine", 0.3, 0.6},
+		{"smith", "Garcia", 0, 0.2},
+		{"", "Smith", 0, 0},
+	}
+	for _, tt := range tests {
+		got := NameScore(tt.query, tt.name)
+		if got < tt.min || got > tt.max {
+			t.Errorf("NameScore(%q, %q) = %v, want within [%v, %v]", tt.query, tt.name, got, tt.min, tt.max)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+// Package pdf writes simple text documents as PDF: headings and wrapped
+// paragraphs on A4 pages, set in the standard Helvetica fonts so that no font
+// needs to be embedded. Text is encoded as WinAnsi; characters outside it are
+// printed as '?'.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Page geometry in points.
+const (
+	pageWidth   = 595.28
+	pageHeight  = 841.89
+	margin      = 56.69
+	footerSpace = 24
+)
+
+// Type sizes in points.
+const (
+	bodySize    = 10.5
+	headingSize = 13
+	titleSize   = 17
+	footerSize  = 8
+	leading     = 1.35
+)
+
+// helveticaWidths holds the advance widths, in thousandths of an em, of the
+// printable ASCII characters in Helvetica, starting at the space.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// boldWidthFactor approximates Helvetica-Bold widths from Helvetica's. It
+// errs on the wide side so that bold lines never overflow.
+const boldWidthFactor = 1.1
+
+// winAnsi maps the characters outside Latin-1 that WinAnsiEncoding places in
+// 0x80-0x9F.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'‰': 0x89, '‹': 0x8B, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94,
+	'•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99, '›': 0x9B,
+}
+
+// Document is a PDF being built page by page. Content flows from the top of
+// the first page and a new page starts whenever the current one is full.
+type Document struct {
+	title string
+	pages []*bytes.Buffer
+	y     float64
+}
+
+// New starts a document with the given title, which is printed at the top of
+// the first page and stored in the document information.
+func New(title string) *Document {
+	d := &Document{title: title}
+	d.newPage()
+	d.lines(title, titleSize, true)
+	d.Space(bodySize)
+	return d
+}
+
+// Heading adds a bold section heading.
+func (d *Document) Heading(text string) {
+	if d.y-headingSize*leading-bodySize*leading*2 < margin+footerSpace {
+		d.newPage()
+	}
+	d.Space(bodySize / 2)
+	d.lines(text, headingSize, true)
+	d.Space(bodySize / 4)
+}
+
+// Paragraph adds text wrapped to the page width. Line breaks in text are
+// kept.
+func (d *Document) Paragraph(text string) {
+	d.lines(text, bodySize, false)
+}
+
+// Space adds vertical space.
+func (d *Document) Space(points float64) {
+	d.y -= points
+}
+
+// Bytes returns the encoded document.
+func (d *Document) Bytes() []byte {
+	var buf bytes.Buffer
+	d.WriteTo(&buf)
+	return buf.Bytes()
+}
+
+// WriteTo writes the encoded document to w.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	var offsets []int
+
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// Objects 1-5 are fixed; each page then takes a page object and a
+	// content stream.
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+2*i)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object(fmt.Sprintf("<< /Title (%s) /Producer (Hospital Management System) >>", escape(d.title)))
+
+	for i, page := range d.pages {
+		content := page.String() + footer(fmt.Sprintf("%s - page %d of %d", d.title, i+1, len(d.pages)))
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 7+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
+func (d *Document) newPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pageHeight - margin
+}
+
+// lines sets text in the given size, wrapping it and starting new pages as
+// needed.
+func (d *Document) lines(text string, size float64, bold bool) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	height := size * leading
+
+	for _, line := range wrap(text, size, bold, pageWidth-2*margin) {
+		if d.y-height < margin+footerSpace {
+			d.newPage()
+		}
+		d.y -= height
+		page := d.pages[len(d.pages)-1]
+		fmt.Fprintf(page, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, margin, d.y, escape(line))
+	}
+}
+
+func footer(text string) string {
+	return fmt.Sprintf("BT /F1 %d Tf %.2f %.2f Td (%s) Tj ET\n", footerSize, margin, margin/2, escape(text))
+}
+
+// wrap breaks text into lines no wider than width points. Words longer than
+// a line are split.
+func wrap(text string, size float64, bold bool, width float64) []string {
+	var out []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if textWidth(candidate, size, bold) <= width {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				out = append(out, line)
+			}
+			for textWidth(word, size, bold) > width {
+				cut := fit(word, size, bold, width)
+				out = append(out, word[:cut])
+				word = word[cut:]
+			}
+			line = word
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+// fit returns how many bytes of the start of word fit in width points,
+// always at least one character.
+func fit(word string, size float64, bold bool, width float64) int {
+	end := 0
+	for i, r := range word {
+		next := i + len(string(r))
+		if end > 0 && textWidth(word[:next], size, bold) > width {
+			break
+		}
+		end = next
+	}
+	return end
+}
+
+func textWidth(s string, size float64, bold bool) float64 {
+	total := 0
+	for _, r := range s {
+		if r >= ' ' && r <= '~' {
+			total += helveticaWidths[r-' ']
+		} else {
+			total += 556
+		}
+	}
+	width := float64(total) * size / 1000
+	if bold {
+		width *= boldWidthFactor
+	}
+	return width
+}
+
+// escape encodes s as the contents of a PDF literal string in WinAnsi.
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		var c byte
+		switch {
+		case r == '\t':
+			c = ' '
+		case r >= ' ' && r <= '~':
+			c = byte(r)
+		case r >= 0xA0 && r <= 0xFF:
+			c = byte(r)
+		default:
+			var ok bool
+			if c, ok = winAnsi[r]; !ok {
+				c = '?'
+			}
+		}
+
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c > '~':
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,137 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var (
+	startxrefPattern = regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`)
+	xrefEntryPattern = regexp.MustCompile(`^(\d{10}) (\d{5}) ([nf]) \n$`)
+	streamPattern    = regexp.MustCompile(`<< /Length (\d+) >>\nstream\n`)
+)
+
+func TestWriteTo(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func() *Document
+		pages int
+	}{
+		{"title only", func() *Document {
+			return New("Discharge summary")
+		}, 1},
+		{"escaped and non-ASCII text", func() *Document {
+			d := New("Summary (draft) \\ Zoë")
+			d.Heading("Medications – “as needed”")
+			d.Paragraph("Paracetamol 1 g (max 4 g/day) €5\nΩ is not in WinAnsi")
+			return d
+		}, 1},
+		{"several pages", func() *Document {
+			d := New("Long summary")
+			for i := 0; i < 40; i++ {
+				d.Heading(fmt.Sprintf("Section %d", i+1))
+				d.Paragraph(strings.Repeat("Hospital course unremarkable. ", 20))
+			}
+			return d
+		}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := tt.build()
+			var buf bytes.Buffer
+			n, err := d.WriteTo(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data := buf.Bytes()
+			if n != int64(len(data)) {
+				t.Errorf("WriteTo returned %d, wrote %d bytes", n, len(data))
+			}
+			if !bytes.Equal(d.Bytes(), data) {
+				t.Error("Bytes differs from WriteTo")
+			}
+			if tt.pages > 0 && len(d.pages) != tt.pages {
+				t.Errorf("pages = %d, want %d", len(d.pages), tt.pages)
+			}
+			if tt.pages == 0 && len(d.pages) < 2 {
+				t.Errorf("pages = %d, want several", len(d.pages))
+			}
+
+			checkXref(t, data, 5+2*len(d.pages))
+			checkStreams(t, data, len(d.pages))
+			if want := fmt.Sprintf("/Count %d >>", len(d.pages)); !bytes.Contains(data, []byte(want)) {
+				t.Errorf("page tree lacks %q", want)
+			}
+		})
+	}
+}
+
+// checkXref checks that the cross-reference table is where startxref points
+// and that every entry holds the offset of its object.
+func checkXref(t *testing.T, data []byte, objects int) {
+	t.Helper()
+	m := startxrefPattern.FindSubmatch(data)
+	if m == nil {
+		t.Fatal("no startxref at the end of the file")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(data[xref:], []byte(fmt.Sprintf("xref\n0 %d\n", objects+1))) {
+		t.Fatalf("startxref %d does not point at an xref table of %d objects", xref, objects+1)
+	}
+
+	lines := strings.SplitAfter(string(data[xref:]), "\n")[2:]
+	if lines[0] != "0000000000 65535 f \n" {
+		t.Errorf("xref entry 0 = %q, want the free list head", lines[0])
+	}
+	for i := 1; i <= objects; i++ {
+		entry := xrefEntryPattern.FindStringSubmatch(lines[i])
+		if entry == nil || entry[3] != "n" {
+			t.Fatalf("xref entry %d = %q, want an in-use entry", i, lines[i])
+		}
+		offset, _ := strconv.Atoi(entry[1])
+		if want := fmt.Sprintf("%d 0 obj\n", i); !bytes.HasPrefix(data[offset:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q, want %q", i, data[offset:offset+len(want)], want)
+		}
+	}
+	if want := fmt.Sprintf("trailer\n<< /Size %d ", objects+1); !strings.HasPrefix(strings.Join(lines[objects+1:], ""), want) {
+		t.Errorf("trailer does not follow the xref table with %q", want)
+	}
+}
+
+// checkStreams checks that every content stream is as long as its /Length.
+func checkStreams(t *testing.T, data []byte, pages int) {
+	t.Helper()
+	streams := streamPattern.FindAllSubmatchIndex(data, -1)
+	if len(streams) != pages {
+		t.Fatalf("content streams = %d, want %d", len(streams), pages)
+	}
+	for i, s := range streams {
+		length, _ := strconv.Atoi(string(data[s[2]:s[3]]))
+		end := s[1] + length
+		if end > len(data) || !bytes.HasPrefix(data[end:], []byte("\nendstream\n")) {
+			t.Errorf("stream %d: /Length %d does not end at endstream", i+1, length)
+		}
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain text", "plain text"},
+		{"(brackets) and \\", `\(brackets\) and \\`},
+		{"tab\there", "tab here"},
+		{"Zoë", `Zo\353`},
+		{"– €", `\226 \200`},
+		{"Ω", "?"},
+	}
+	for _, tt := range tests {
+		if got := escape(tt.in); got != tt.want {
+			t.Errorf("escape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
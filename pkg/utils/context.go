@@ -0,0 +1,15 @@
+package utils
+
+import "github.com/gin-gonic/gin"
+
+// CurrentUserID returns the ID of the authenticated user set by auth.RequireAuth.
+func CurrentUserID(c *gin.Context) uint {
+	user := c.MustGet("user").(map[string]interface{})
+	return user["id"].(uint)
+}
+
+// CurrentPermissions returns the permissions granted to the authenticated user.
+func CurrentPermissions(c *gin.Context) []string {
+	user := c.MustGet("user").(map[string]interface{})
+	return user["permissions"].([]string)
+}
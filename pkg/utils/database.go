@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"errors"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// SQLSTATEs raised when a write conflicts with a unique index or an
+// exclusion constraint.
+const (
+	uniqueViolation    = "23505"
+	exclusionViolation = "23P01"
+)
+
+// Conflict returns the name of the unique index or exclusion constraint that
+// err violated. It returns false when err is not such a violation.
+func Conflict(err error) (string, bool) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && (pgErr.Code == uniqueViolation || pgErr.Code == exclusionViolation) {
+		return pgErr.ConstraintName, true
+	}
+	return "", false
+}
+
+// TranslateConflict returns the error that errs maps the violated constraint
+// to when err is a conflict with one of them, and err otherwise.
+func TranslateConflict(err error, errs map[string]error) error {
+	if constraint, ok := Conflict(err); ok {
+		if mapped, ok := errs[constraint]; ok {
+			return mapped
+		}
+	}
+	return err
+}
+
+// WithDeleted is a preload condition that includes soft-deleted rows, so
+// that records keep showing the patients and staff they refer to after those
+// have been deleted.
+func WithDeleted(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// DiffFields compares the JSON representations of before and after and
+// returns the top-level fields whose values differ, keyed by JSON name.
+// Fields listed in ignore are skipped.
+func DiffFields(before, after interface{}, ignore ...string) (map[string]FieldChange, error) {
+	oldFields, err := toFieldMap(before)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := toFieldMap(after)
+	if err != nil {
+		return nil, err
+	}
+
+	skip := make(map[string]bool, len(ignore))
+	for _, name := range ignore {
+		skip[name] = true
+	}
+
+	changes := make(map[string]FieldChange)
+	for name, newValue := range newFields {
+		if skip[name] {
+			continue
+		}
+		if oldValue, ok := oldFields[name]; !ok || !reflect.DeepEqual(oldValue, newValue) {
+			changes[name] = FieldChange{Old: oldFields[name], New: newValue}
+		}
+	}
+	for name, oldValue := range oldFields {
+		if _, ok := newFields[name]; !ok && !skip[name] {
+			changes[name] = FieldChange{Old: oldValue}
+		}
+	}
+
+	return changes, nil
+}
+
+func toFieldMap(v interface{}) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return fields, nil
+	}
+
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
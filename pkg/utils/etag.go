@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// ErrVersionConflict is returned by repositories when the stored version
+	// no longer matches the version the client based its change on.
+	ErrVersionConflict      = errors.New("resource has been modified since it was retrieved")
+	ErrPreconditionRequired = errors.New("If-Match header with the resource ETag is required")
+)
+
+// ETag formats a row version as an entity tag.
+func ETag(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// SetETag sets the ETag header for a resource at the given version.
+func SetETag(c *gin.Context, version uint) {
+	c.Header("ETag", ETag(version))
+}
+
+// NotModified sets the ETag header and, when If-None-Match matches it,
+// answers 304 Not Modified. Callers stop handling the request when it
+// returns true.
+func NotModified(c *gin.Context, version uint) bool {
+	SetETag(c, version)
+
+	for _, tag := range entityTags(c.GetHeader("If-None-Match")) {
+		if tag == "*" || tag == ETag(version) {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+// Precondition is the set of versions an If-Match header accepts.
+type Precondition struct {
+	any      bool
+	versions []uint
+}
+
+// Matches reports whether a resource at version satisfies the precondition.
+func (p Precondition) Matches(version uint) bool {
+	if p.any {
+		return true
+	}
+	for _, v := range p.versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// IfMatch returns the precondition set by the If-Match header. A header
+// naming no version, or only unknown ones, matches nothing, so the change is
+// refused with ErrVersionConflict. When the header is missing it answers 428
+// Precondition Required and returns false.
+func IfMatch(c *gin.Context) (Precondition, bool) {
+	tags := entityTags(c.GetHeader("If-Match"))
+	if len(tags) == 0 {
+		ErrorResponse(c, http.StatusPreconditionRequired, "Precondition required", ErrPreconditionRequired)
+		return Precondition{}, false
+	}
+
+	var p Precondition
+	for _, tag := range tags {
+		if tag == "*" {
+			p.any = true
+			continue
+		}
+		version, err := strconv.ParseUint(strings.Trim(tag, `"`), 10, 32)
+		if err == nil && version > 0 {
+			p.versions = append(p.versions, uint(version))
+		}
+	}
+	return p, true
+}
+
+// entityTags splits an If-Match or If-None-Match header into its entity
+// tags. Weak tags are compared as strong ones since versions identify the
+// stored row rather than a byte-exact representation.
+func entityTags(header string) []string {
+	var tags []string
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"github.com/gin-gonic/gin"
+)
+
+func testContext(header, value string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	if value != "" {
+		c.Request.Header.Set(header, value)
+	}
+	return c, w
+}
+
+func TestETag(t *testing.T) {
+	if got := ETag(42); got != `"42"` {
+		t.Errorf("ETag(42) = %s, want \"42\"", got)
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        bool
+	}{
+		{"no header", "", false},
+		{"strong tag", `"3"`, true},
+		{"weak tag", `W/"3"`, true},
+		{"other version", `"2"`, false},
+		{"one of several tags", `"1", W/"2" ,"3"`, true},
+		{"none of several tags", `"1", "2"`, false},
+		{"any", "*", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := testContext("If-None-Match", tt.ifNoneMatch)
+
+			got := NotModified(c, 3)
+			c.Writer.WriteHeaderNow()
+
+			if got != tt.want {
+				t.Errorf("NotModified = %v, want %v", got, tt.want)
+			}
+			if etag := w.Header().Get("ETag"); etag != `"3"` {
+				t.Errorf("ETag = %s, want \"3\"", etag)
+			}
+			wantStatus := http.StatusOK
+			if tt.want {
+				wantStatus = http.StatusNotModified
+			}
+			if w.Code != wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, wantStatus)
+			}
+		})
+	}
+}
+
+func TestIfMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		ifMatch string
+		ok      bool
+		matches []uint
+	}{
+		{"no header", "", false, nil},
+		{"empty list", " , ", false, nil},
+		{"strong tag", `"3"`, true, []uint{3}},
+		{"weak tag", `W/"3"`, true, []uint{3}},
+		{"first of several tags", `"3", "4"`, true, []uint{3, 4}},
+		{"later of several tags", `"1",W/"2", "3"`, true, []uint{1, 2, 3}},
+		{"any", "*", true, []uint{1, 2, 3, 4}},
+		{"stale version", `"2"`, true, []uint{2}},
+		{"not a version", `"abc", "0"`, true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := testContext("If-Match", tt.ifMatch)
+
+			match, ok := IfMatch(c)
+
+			if ok != tt.ok {
+				t.Fatalf("IfMatch ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				if w.Code != http.StatusPreconditionRequired {
+					t.Errorf("status = %d, want %d", w.Code, http.StatusPreconditionRequired)
+				}
+				return
+			}
+			for version := uint(1); version <= 4; version++ {
+				want := false
+				for _, v := range tt.matches {
+					want = want || v == version
+				}
+				if got := match.Matches(version); got != want {
+					t.Errorf("Matches(%d) = %v, want %v", version, got, want)
+				}
+			}
+		})
+	}
+}
@@ -6,17 +6,35 @@ import (
 )
 
 type Claims struct {
-	UserID uint   `json:"user_id"`
-	Role   string `json:"role"`
+	UserID    uint   `json:"user_id"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid"`
+	// Scope limits what the token can be used for. Access tokens have none.
+	Scope string `json:"scope,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID uint, role string, secret string) (string, error) {
+func GenerateToken(userID uint, role, sessionID, secret string, ttl time.Duration) (string, time.Time, error) {
+	expiresAt := time.Now().Add(ttl)
+	signed, err := GenerateScopedToken(userID, role, sessionID, "", secret, expiresAt)
+	return signed, expiresAt, err
+}
+
+// GenerateScopedToken signs a token that can only be used for scope and
+// expires at expiresAt.
+func GenerateScopedToken(userID uint, role, sessionID, scope, secret string, expiresAt time.Time) (string, error) {
+	id, err := GenerateRandomToken(16)
+	if err != nil {
+		return "", err
+	}
 	claims := &Claims{
-		UserID: userID,
-		Role:   role,
+		UserID:    userID,
+		Role:      role,
+		SessionID: sessionID,
+		Scope:     scope,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ID:        id,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
@@ -28,6 +46,9 @@ func GenerateToken(userID uint, role string, secret string) (string, error) {
 func ValidateToken(tokenString, secret string) (*Claims, error) {
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
 		return []byte(secret), nil
 	})
 
@@ -39,5 +60,11 @@ func ValidateToken(tokenString, secret string) (*Claims, error) {
 		return nil, jwt.ErrSignatureInvalid
 	}
 
+	// Every token this service issues expires; one without an expiry was
+	// not issued here.
+	if !claims.VerifyExpiresAt(time.Now(), true) {
+		return nil, jwt.ErrTokenExpired
+	}
+
 	return claims, nil
 }
@@ -9,9 +9,20 @@ type Response struct {
 	Success bool        `json:"success"`
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
+	Meta    *Meta       `json:"meta,omitempty"`
 	Error   string      `json:"error,omitempty"`
 }
 
+// Meta describes the page returned by a list endpoint. Page is only set for
+// offset pagination; NextCursor is set whenever more results follow.
+type Meta struct {
+	Total      int64  `json:"total"`
+	PageSize   int    `json:"page_size"`
+	Page       int    `json:"page,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
+
 func SuccessResponse(c *gin.Context, message string, data interface{}) {
 	c.JSON(http.StatusOK, Response{
 		Success: true,
@@ -20,6 +31,15 @@ func SuccessResponse(c *gin.Context, message string, data interface{}) {
 	})
 }
 
+func PaginatedResponse(c *gin.Context, message string, data interface{}, meta *Meta) {
+	c.JSON(http.StatusOK, Response{
+		Success: true,
+		Message: message,
+		Data:    data,
+		Meta:    meta,
+	})
+}
+
 func ErrorResponse(c *gin.Context, statusCode int, message string, err error) {
 	response := Response{
 		Success: false,
@@ -32,3 +52,19 @@ func ErrorResponse(c *gin.Context, statusCode int, message string, err error) {
 	
 	c.JSON(statusCode, response)
 }
+
+// ErrorResponseWithData reports an error together with data the client needs
+// to resolve it.
+func ErrorResponseWithData(c *gin.Context, statusCode int, message string, err error, data interface{}) {
+	response := Response{
+		Success: false,
+		Message: message,
+		Data:    data,
+	}
+
+	if err != nil {
+		response.Error = err.Error()
+	}
+
+	c.JSON(statusCode, response)
+}